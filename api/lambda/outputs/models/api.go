@@ -18,6 +18,8 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "time"
+
 // LambdaInput is the invocation event expected by the Lambda function.
 //
 // Exactly one action must be specified.
@@ -194,27 +196,60 @@ type OutputConfig struct {
 
 	// CustomWebhook contains the configuration for a Custom Webhook alert output
 	CustomWebhook *CustomWebhookConfig `json:"customWebhook,omitempty"`
+
+	// Webhook contains the configuration for a generic, signed Webhook alert output
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+
+	// Email contains the configuration for an Email alert output, sent through SES
+	Email *EmailConfig `json:"email,omitempty"`
 }
 
 // SlackConfig defines options for each Slack output.
 type SlackConfig struct {
 	WebhookURL string `json:"webhookURL" validate:"omitempty,url"` // https://hooks.slack.com/services/...
+	// MessageTemplate is an optional Go text template the alerts are rendered with, instead of the built-in message
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // SnsConfig defines options for each SNS topic output
 type SnsConfig struct {
 	TopicArn string `json:"topicArn" validate:"omitempty,snsArn"`
+	// RoleArn is an optional IAM role assumed to publish to the topic, e.g. for topics in other accounts.
+	// Panther can only assume roles named PantherAlertDeliveryRole-*
+	RoleArn string `json:"roleArn,omitempty" validate:"omitempty,startswith=arn:aws:iam::,contains=:role/PantherAlertDeliveryRole-"`
+	// MessageTemplate is an optional Go text template for the message of email subscribers
+	MessageTemplate string `json:"messageTemplate,omitempty"`
+}
+
+// EmailConfig defines options for each Email output
+type EmailConfig struct {
+	// FromAddress must be verified in SES
+	FromAddress string   `json:"fromAddress" validate:"omitempty,email"`
+	Recipients  []string `json:"recipients" validate:"omitempty,min=1,dive,email"`
+	// Region is the SES region, the region of Panther if not set
+	Region string `json:"region,omitempty"`
+	// RoleArn is an optional IAM role assumed to send the emails, e.g. for SES in other accounts.
+	// Panther can only assume roles named PantherAlertDeliveryRole-*
+	RoleArn string `json:"roleArn,omitempty" validate:"omitempty,startswith=arn:aws:iam::,contains=:role/PantherAlertDeliveryRole-"`
+	// MessageTemplate is an optional Go HTML template for the body of the emails, instead of the built-in layout
+	MessageTemplate string `json:"messageTemplate,omitempty"`
 }
 
 // PagerDutyConfig defines options for each PagerDuty output
 type PagerDutyConfig struct {
 	IntegrationKey string `json:"integrationKey" validate:"omitempty,hexadecimal,len=32"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // GithubConfig defines options for each Github output
 type GithubConfig struct {
 	RepoName string `json:"repoName"`
 	Token    string `json:"token"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // JiraConfig defines options for each Jira output
@@ -225,16 +260,22 @@ type JiraConfig struct {
 	APIKey     string `json:"apiKey"`
 	AssigneeID string `json:"assigneeId"`
 	Type       string `json:"issueType"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // OpsgenieConfig defines options for each Opsgenie output
 type OpsgenieConfig struct {
 	APIKey string `json:"apiKey"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // MsTeamsConfig defines options for each MsTeams output
 type MsTeamsConfig struct {
 	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // SqsConfig defines options for each Sqs topic output
@@ -246,9 +287,35 @@ type SqsConfig struct {
 type AsanaConfig struct {
 	PersonalAccessToken string   `json:"personalAccessToken" validate:"omitempty,min=1"`
 	ProjectGids         []string `json:"projectGids" validate:"omitempty,min=1,dive,required"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
 }
 
 // CustomWebhookConfig defines options for each CustomWebhook output
 type CustomWebhookConfig struct {
 	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
+}
+
+// WebhookConfig defines options for each generic Webhook output
+type WebhookConfig struct {
+	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+	// The shared secret used to sign the request body (X-Panther-Signature header)
+	SigningSecret string `json:"signingSecret"`
+	// Additional headers sent with every request
+	Headers map[string]string `json:"headers,omitempty"`
+	// Applied to every request to the output
+	HTTPTimeoutConfig
+}
+
+// HTTPTimeoutConfig is the delivery timeout shared by the outputs which send alerts over HTTP
+type HTTPTimeoutConfig struct {
+	// Request timeout, the default HTTP client timeout is used if not set
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" validate:"omitempty,min=1,max=60"`
+}
+
+// Timeout returns the configured request timeout, zero if the default should be used
+func (c HTTPTimeoutConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
 }
@@ -83,6 +83,8 @@ type PutIntegrationSettings struct {
 	S3Prefix           string   `json:"s3Prefix" validate:"omitempty,min=1"`
 	KmsKey             string   `json:"kmsKey" validate:"omitempty,kmsKeyArn"`
 	LogTypes           []string `json:"logTypes" validate:"omitempty,min=1"`
+	// Session tags passed when assuming the log processing role of S3 sources
+	LogProcessingRoleSessionTags map[string]string `json:"logProcessingRoleSessionTags" validate:"omitempty,sessionTags"`
 
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 }
@@ -107,6 +109,8 @@ type UpdateIntegrationSettingsInput struct {
 	S3Prefix           string   `json:"s3Prefix" validate:"omitempty,min=1"`
 	KmsKey             string   `json:"kmsKey" validate:"omitempty,kmsKeyArn"`
 	LogTypes           []string `json:"logTypes" validate:"omitempty,min=1"`
+	// Session tags passed when assuming the log processing role of S3 sources
+	LogProcessingRoleSessionTags map[string]string `json:"logProcessingRoleSessionTags" validate:"omitempty,sessionTags"`
 
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 }
@@ -61,6 +61,11 @@ type SourceIntegrationMetadata struct {
 	LogProcessingRole  string     `json:"logProcessingRole,omitempty"`
 	StackName          string     `json:"stackName,omitempty"`
 	SqsConfig          *SqsConfig `json:"sqsConfig,omitempty"`
+	// How the log processor gets credentials to read the S3 objects of the source, S3CredentialsAssumeRole if empty
+	S3CredentialsProvider string `json:"s3CredentialsProvider,omitempty"`
+	// Session tags passed when assuming the log processing role, for IAM policies using ABAC,
+	// e.g. {"panther:integration": "<id>"}
+	LogProcessingRoleSessionTags map[string]string `json:"logProcessingRoleSessionTags,omitempty"`
 }
 
 type SourceIntegrationHealth struct {
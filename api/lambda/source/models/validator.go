@@ -28,6 +28,12 @@ import (
 
 const (
 	integrationLabelMaxLength = 32
+
+	// Limits of STS session tags
+	sessionTagsMaxCount      = 50
+	sessionTagKeyMaxLength   = 128
+	sessionTagValueMaxLength = 256
+	sessionTagReservedPrefix = "aws:"
 )
 
 var (
@@ -43,6 +49,9 @@ func Validator() (*validator.Validate, error) {
 	if err := result.RegisterValidation("kmsKeyArn", validateKmsKeyArn); err != nil {
 		return nil, err
 	}
+	if err := result.RegisterValidation("sessionTags", validateSessionTags); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -66,3 +75,19 @@ func validateKmsKeyArn(fl validator.FieldLevel) bool {
 	}
 	return true
 }
+
+func validateSessionTags(fl validator.FieldLevel) bool {
+	tags, ok := fl.Field().Interface().(map[string]string)
+	if !ok || len(tags) > sessionTagsMaxCount {
+		return false
+	}
+	for key, value := range tags {
+		if key == "" || len(key) > sessionTagKeyMaxLength || len(value) > sessionTagValueMaxLength {
+			return false
+		}
+		if strings.HasPrefix(strings.ToLower(key), sessionTagReservedPrefix) {
+			return false
+		}
+	}
+	return true
+}
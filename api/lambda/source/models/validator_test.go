@@ -19,6 +19,7 @@ package models
  */
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -80,3 +81,27 @@ func TestValidateKmsKey(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestValidateSessionTags(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	input := &UpdateIntegrationSettingsInput{
+		IntegrationID:                "cb7663c7-80ed-420b-a287-ed7dc50a0bf7",
+		IntegrationLabel:             "Test12- ",
+		LogProcessingRoleSessionTags: map[string]string{"panther:integration": "cloudtrail", "team": ""},
+	}
+	require.NoError(t, validator.Struct(input))
+
+	input.LogProcessingRoleSessionTags = map[string]string{"aws:PrincipalTag": "value"}
+	require.EqualError(t, validator.Struct(input), "Key: 'UpdateIntegrationSettingsInput.LogProcessingRoleSessionTags' "+
+		"Error:Field validation for 'LogProcessingRoleSessionTags' failed on the 'sessionTags' tag")
+
+	input.LogProcessingRoleSessionTags = map[string]string{"": "value"}
+	require.Error(t, validator.Struct(input))
+
+	input.LogProcessingRoleSessionTags = make(map[string]string)
+	for i := 0; i <= sessionTagsMaxCount; i++ {
+		input.LogProcessingRoleSessionTags[strconv.Itoa(i)] = "value"
+	}
+	require.Error(t, validator.Struct(input))
+}
@@ -32,4 +32,11 @@ const (
 	StatusOK = "ok"
 	// StatusScanning is the status set while a scan is underway.
 	StatusScanning = "scanning"
+
+	// S3CredentialsAssumeRole reads the S3 objects of a source by assuming its log processing role (the default).
+	S3CredentialsAssumeRole = "assume-role"
+	// S3CredentialsInstance reads the S3 objects of a source with the credentials of the log processor itself.
+	S3CredentialsInstance = "instance"
+	// S3CredentialsStatic reads the S3 objects of a source with the static credentials configured for the log processor.
+	S3CredentialsStatic = "static"
 )
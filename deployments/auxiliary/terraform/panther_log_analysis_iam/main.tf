@@ -30,7 +30,8 @@ resource "aws_iam_role" "log_processing" {
         Principal : {
           AWS : "arn:${var.aws_partition}:iam::${var.master_account_id}:root"
         }
-        Action : "sts:AssumeRole",
+        # The source can have session tags, e.g. for ABAC policies
+        Action : ["sts:AssumeRole", "sts:TagSession"],
         Condition : {
           Bool : { "aws:SecureTransport" : true }
         }
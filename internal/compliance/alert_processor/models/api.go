@@ -37,6 +37,9 @@ type ComplianceNotification struct {
 	// ResourceID is the ID specific to the resource
 	ResourceID *string `json:"resourceId" validate:"required,min=1"`
 
+	// AccountID is the AWS account of the resource, if it has one
+	AccountID *string `json:"accountId,omitempty"`
+
 	// ShouldAlert indicates whether this notification should cause an alert to be send to the customer
 	ShouldAlert *bool `json:"shouldAlert"`
 
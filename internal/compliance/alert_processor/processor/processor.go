@@ -232,6 +232,7 @@ func getAlertConfigPolicy(event *models.ComplianceNotification) (*alertmodel.Ale
 	return &alertmodel.Alert{
 			AnalysisDescription: aws.String(string(policy.Payload.Description)),
 			AnalysisID:          *event.PolicyID,
+			AccountID:           event.AccountID,
 			AnalysisName:        aws.String(string(policy.Payload.DisplayName)),
 			CreatedAt:           *event.Timestamp,
 			OutputIds:           event.OutputIds,
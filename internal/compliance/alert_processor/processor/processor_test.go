@@ -35,6 +35,7 @@ import (
 	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
 	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
 	"github.com/panther-labs/panther/internal/compliance/alert_processor/models"
+	alertmodel "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 	"github.com/panther-labs/panther/pkg/testutils"
 )
 
@@ -94,6 +95,47 @@ func TestHandleEventWithAlert(t *testing.T) {
 	mockRoundTripper.AssertExpectations(t)
 }
 
+// The account of the resource is forwarded in the alert, so the alert can be enriched with the account metadata
+func TestHandleEventWithAlertAccountID(t *testing.T) {
+	mockDdbClient := &testutils.DynamoDBMock{}
+	ddbClient = mockDdbClient
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+
+	input := &models.ComplianceNotification{
+		ResourceID:      aws.String("test-resource"),
+		AccountID:       aws.String("123456789012"),
+		PolicyID:        aws.String("test-policy"),
+		PolicyVersionID: aws.String("test-version"),
+		ShouldAlert:     aws.Bool(true),
+		Timestamp:       aws.Time(time.Now()),
+	}
+
+	complianceResponse := &compliancemodels.ComplianceStatus{
+		PolicyID:   "test-policy",
+		ResourceID: "test-resource",
+		Status:     compliancemodels.StatusFAIL,
+	}
+
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(complianceResponse, http.StatusOK), nil).Once()
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(&analysismodels.Policy{}, http.StatusOK), nil).Once()
+	mockDdbClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	require.NoError(t, Handle(input))
+
+	var alertConfig alertmodel.Alert
+	updateInput := mockDdbClient.Calls[0].Arguments[0].(*dynamodb.UpdateItemInput)
+	for _, value := range updateInput.ExpressionAttributeValues {
+		if value.B != nil {
+			require.NoError(t, jsoniter.Unmarshal(value.B, &alertConfig))
+		}
+	}
+	assert.Equal(t, "test-policy", alertConfig.AnalysisID)
+	assert.Equal(t, aws.String("123456789012"), alertConfig.AccountID)
+	mockDdbClient.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+}
+
 func TestHandleEventWithAlertButNoAutoRemediationID(t *testing.T) {
 	mockDdbClient := &testutils.DynamoDBMock{}
 	ddbClient = mockDdbClient
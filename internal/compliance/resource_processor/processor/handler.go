@@ -242,6 +242,7 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 			complianceNotification := &alertmodels.ComplianceNotification{
 				OutputIds:       policy.OutputIds,
 				ResourceID:      aws.String(string(resource.ID)),
+				AccountID:       resourceAccountID(resource),
 				PolicyID:        aws.String(string(policy.ID)),
 				PolicyVersionID: aws.String(string(policy.VersionID)),
 				Timestamp:       aws.Time(time.Now()),
@@ -271,6 +272,19 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 	return nil
 }
 
+// resourceAccountID returns the AWS account of the resource, which the snapshot pollers add to its attributes
+func resourceAccountID(resource *resourcemodels.Resource) *string {
+	attributes, ok := resource.Attributes.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	accountID, ok := attributes["AccountId"].(string)
+	if !ok || accountID == "" {
+		return nil
+	}
+	return &accountID
+}
+
 // Invoke the policy engine.
 func evaluatePolicies(policies policyMap, resources resourceMap) (*enginemodels.PolicyEngineOutput, error) {
 	input := enginemodels.PolicyEngineInput{
@@ -21,6 +21,7 @@ package processor
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 
@@ -102,3 +103,12 @@ func TestIsSuppressed(t *testing.T) {
 		Suppressions: []string{"not", "this", "one", "but", "here:", "*.us-west-2/*"},
 	}))
 }
+
+func TestResourceAccountID(t *testing.T) {
+	resource := &resourcemodels.Resource{Attributes: map[string]interface{}{"AccountId": "123456789012"}}
+	assert.Equal(t, aws.String("123456789012"), resourceAccountID(resource))
+
+	// Resources which are not in an AWS account
+	assert.Nil(t, resourceAccountID(&resourcemodels.Resource{Attributes: map[string]interface{}{"Name": "bucket"}}))
+	assert.Nil(t, resourceAccountID(&resourcemodels.Resource{Attributes: "{}"}))
+}
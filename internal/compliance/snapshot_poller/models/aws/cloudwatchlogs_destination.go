@@ -0,0 +1,39 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+const (
+	CloudWatchLogsDestinationSchema = "AWS.CloudWatch.LogsDestination"
+)
+
+// CloudWatchLogsDestination contains all the information about a CloudWatch Logs destination,
+// which receives log events from subscription filters in other accounts
+type CloudWatchLogsDestination struct {
+	// Generic resource fields
+	GenericAWSResource
+	GenericResource
+
+	// Fields embedded from cloudwatchlogs.Destination
+	RoleArn   *string
+	TargetArn *string
+
+	// The parsed access policy, which controls who can PutSubscriptionFilter against the destination.
+	// It is nil if the destination has no access policy.
+	AccessPolicy map[string]interface{}
+}
@@ -18,6 +18,8 @@ package aws
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "github.com/go-openapi/strfmt"
+
 const (
 	CloudWatchLogGroupSchema = "AWS.CloudWatch.LogGroup"
 )
@@ -33,4 +35,36 @@ type CloudWatchLogsLogGroup struct {
 	MetricFilterCount *int64
 	RetentionInDays   *int64
 	StoredBytes       *int64
+
+	// Additional fields
+	DataProtectionPolicyEnabled *bool
+	DataProtectionPolicyName    *string
+
+	// RetentionInDays is nil when events in the log group never expire, which is easy to mistake for
+	// a retention of zero days. These fields are always set so rules don't have to interpret the nil:
+	//   - RetentionConfigured is true when a retention period is set (RetentionInDays is not nil)
+	//   - RetentionNeverExpires is true when no retention period is set and events are kept forever
+	RetentionConfigured   *bool
+	RetentionNeverExpires *bool
+
+	// Status of the KMS key in KmsKeyId, only resolved when the scan requests it (ResolveKMSKeys).
+	// Both are nil when the status is unknown, e.g. when access to the key was denied.
+	KmsKeyRotationEnabled *bool
+	KmsKeyState           *string
+
+	// When data was last ingested into any log stream of the log group, only resolved when the scan
+	// requests it (ResolveIngestionTime). NeverIngested is true when the log group has no data at all,
+	// both are nil when the ingestion time is unknown.
+	LastIngestionTime *strfmt.DateTime
+	NeverIngested     *bool
+
+	// The number of subscription filters, resolved along with the ingestion time (nil when unknown).
+	SubscriptionFilterCount *int64
+
+	// Usage flags derived from the fields above, nil when any field they depend on is unknown:
+	//   - RecentlyIngested is true when data was ingested in the last 30 days
+	//   - Orphaned is true when the log group does nothing useful: events never expire, there is no
+	//     metric filter and no subscription filter, and no data was ingested recently
+	RecentlyIngested *bool
+	Orphaned         *bool
 }
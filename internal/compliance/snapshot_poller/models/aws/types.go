@@ -68,6 +68,10 @@ type ResourcePollerInput struct {
 	IntegrationID       *string
 	Regions             []*string
 	Timestamp           *strfmt.DateTime
+	// ResolveKMSKeys enables extra KMS API calls to describe the keys referenced by resources
+	ResolveKMSKeys bool
+	// ResolveIngestionTime enables extra API calls per log group to find when it last received data and its subscriptions
+	ResolveIngestionTime bool
 }
 
 // ResourcePoller represents a function to poll a specific AWS resource.
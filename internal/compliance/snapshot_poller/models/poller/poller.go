@@ -34,4 +34,13 @@ type ScanEntry struct {
 	ResourceID       *string `json:"resourceId"`
 	ResourceType     *string `json:"resourceType"`
 	ScanAllResources *bool   `json:"scanAllResources"`
+
+	// ResolveKMSKeys enables looking up the KMS keys referenced by resources (e.g. the key rotation
+	// status of encrypted log groups). This requires extra API calls, so it is off by default.
+	ResolveKMSKeys *bool `json:"resolveKmsKeys,omitempty"`
+
+	// ResolveIngestionTime enables looking up when each log group last received data and its subscription
+	// filters, so silent and orphaned log groups can be flagged. This requires two extra API calls per log
+	// group, so it is off by default.
+	ResolveIngestionTime *bool `json:"resolveIngestionTime,omitempty"`
 }
@@ -32,6 +32,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.AcmCertificateSchema, PollACMCertificate)
+}
+
 // Set as variables to be overridden in testing
 var (
 	AcmClientFunc = setupAcmClient
@@ -29,5 +29,5 @@ import (
 func AssumeRoleMock(pollerInput *awsmodels.ResourcePollerInput, sess *session.Session,
 	region string) *credentials.Credentials {
 
-	return &credentials.Credentials{}
+	return credentials.NewStaticCredentials("mock-access-key", "mock-secret-key", "")
 }
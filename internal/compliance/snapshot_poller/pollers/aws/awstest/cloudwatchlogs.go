@@ -22,6 +22,7 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
@@ -56,6 +57,69 @@ var (
 		},
 	}
 
+	ExampleDescribeLogStreams = &cloudwatchlogs.DescribeLogStreamsOutput{
+		LogStreams: []*cloudwatchlogs.LogStream{
+			{
+				LogStreamName:       aws.String("LogStream-1"),
+				CreationTime:        aws.Int64(1234567890123),
+				LastEventTimestamp:  aws.Int64(1593561600000),
+				LastIngestionTime:   aws.Int64(1593561605000),
+				FirstEventTimestamp: aws.Int64(1234567890123),
+			},
+		},
+	}
+
+	ExampleDescribeSubscriptionFilters = &cloudwatchlogs.DescribeSubscriptionFiltersOutput{
+		SubscriptionFilters: []*cloudwatchlogs.SubscriptionFilter{
+			{
+				FilterName:     aws.String("SubscriptionFilter-1"),
+				LogGroupName:   aws.String("LogGroup-1"),
+				DestinationArn: aws.String("arn:aws:lambda:us-west-2:123456789012:function:log-processor"),
+				FilterPattern:  aws.String(""),
+			},
+		},
+	}
+
+	ExampleGetDataProtectionPolicy = aws.String(`{
+  "Name": "data-protection-policy",
+  "Description": "",
+  "Version": "2021-06-01",
+  "Statement": []
+}`)
+
+	ExampleDescribeDestinations = &cloudwatchlogs.DescribeDestinationsOutput{
+		Destinations: []*cloudwatchlogs.Destination{
+			{
+				DestinationName: aws.String("Destination-1"),
+				Arn:             aws.String("arn:aws:logs:us-west-2:123456789012:destination:Destination-1"),
+				CreationTime:    aws.Int64(1234567890123),
+				RoleArn:         aws.String("arn:aws:iam::123456789012:role/CWLtoKinesisRole"),
+				TargetArn:       aws.String("arn:aws:kinesis:us-west-2:123456789012:stream/RecipientStream"),
+				AccessPolicy: aws.String(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {"AWS": "111111111111"},
+      "Action": "logs:PutSubscriptionFilter",
+      "Resource": "arn:aws:logs:us-west-2:123456789012:destination:Destination-1"
+    }
+  ]
+}`),
+			},
+			{
+				DestinationName: aws.String("Destination-2"),
+				Arn:             aws.String("arn:aws:logs:us-west-2:123456789012:destination:Destination-2"),
+				CreationTime:    aws.Int64(1234567890123),
+				RoleArn:         aws.String("arn:aws:iam::123456789012:role/CWLtoKinesisRole"),
+				TargetArn:       aws.String("arn:aws:kinesis:us-west-2:123456789012:stream/RecipientStream"),
+			},
+		},
+	}
+
+	ExampleDataProtectionPolicyNotFound = awserr.New(
+		cloudwatchlogs.ErrCodeResourceNotFoundException, "No data protection policy found", nil)
+
 	svcCloudWatchLogsSetupCalls = map[string]func(*MockCloudWatchLogs){
 		"DescribeLogGroupsPages": func(svc *MockCloudWatchLogs) {
 			svc.On("DescribeLogGroupsPages", mock.Anything).
@@ -65,6 +129,22 @@ var (
 			svc.On("ListTagsLogGroup", mock.Anything).
 				Return(ExampleListTagsLogGroup, nil)
 		},
+		"GetDataProtectionPolicy": func(svc *MockCloudWatchLogs) {
+			svc.On("GetDataProtectionPolicy", mock.Anything).
+				Return(ExampleGetDataProtectionPolicy, nil)
+		},
+		"DescribeDestinationsPages": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeDestinationsPages", mock.Anything).
+				Return(nil)
+		},
+		"DescribeLogStreams": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeLogStreams", mock.Anything).
+				Return(ExampleDescribeLogStreams, nil)
+		},
+		"DescribeSubscriptionFilters": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeSubscriptionFilters", mock.Anything).
+				Return(ExampleDescribeSubscriptionFilters, nil)
+		},
 	}
 
 	svcCloudWatchLogsSetupCallsError = map[string]func(*MockCloudWatchLogs){
@@ -77,6 +157,24 @@ var (
 				Return(&cloudwatchlogs.ListTagsLogGroupOutput{},
 					errors.New("CloudWatchLogs.ListTagsLogGroup error"))
 		},
+		"GetDataProtectionPolicy": func(svc *MockCloudWatchLogs) {
+			svc.On("GetDataProtectionPolicy", mock.Anything).
+				Return((*string)(nil), errors.New("CloudWatchLogs.GetDataProtectionPolicy error"))
+		},
+		"DescribeDestinationsPages": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeDestinationsPages", mock.Anything).
+				Return(errors.New("CloudWatchLogs.DescribeDestinationsPages error"))
+		},
+		"DescribeLogStreams": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeLogStreams", mock.Anything).
+				Return(&cloudwatchlogs.DescribeLogStreamsOutput{},
+					errors.New("CloudWatchLogs.DescribeLogStreams error"))
+		},
+		"DescribeSubscriptionFilters": func(svc *MockCloudWatchLogs) {
+			svc.On("DescribeSubscriptionFilters", mock.Anything).
+				Return(&cloudwatchlogs.DescribeSubscriptionFiltersOutput{},
+					errors.New("CloudWatchLogs.DescribeSubscriptionFilters error"))
+		},
 	}
 
 	MockCloudWatchLogsForSetup = &MockCloudWatchLogs{}
@@ -156,3 +254,35 @@ func (m *MockCloudWatchLogs) ListTagsLogGroup(in *cloudwatchlogs.ListTagsLogGrou
 	args := m.Called(in)
 	return args.Get(0).(*cloudwatchlogs.ListTagsLogGroupOutput), args.Error(1)
 }
+
+func (m *MockCloudWatchLogs) GetDataProtectionPolicy(in *string) (*string, error) {
+	args := m.Called(in)
+	return args.Get(0).(*string), args.Error(1)
+}
+
+func (m *MockCloudWatchLogs) DescribeDestinationsPages(
+	in *cloudwatchlogs.DescribeDestinationsInput,
+	paginationFunction func(*cloudwatchlogs.DescribeDestinationsOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleDescribeDestinations, true)
+	return args.Error(0)
+}
+
+func (m *MockCloudWatchLogs) DescribeLogStreams(
+	in *cloudwatchlogs.DescribeLogStreamsInput) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+
+	args := m.Called(in)
+	return args.Get(0).(*cloudwatchlogs.DescribeLogStreamsOutput), args.Error(1)
+}
+
+func (m *MockCloudWatchLogs) DescribeSubscriptionFilters(
+	in *cloudwatchlogs.DescribeSubscriptionFiltersInput) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+
+	args := m.Called(in)
+	return args.Get(0).(*cloudwatchlogs.DescribeSubscriptionFiltersOutput), args.Error(1)
+}
@@ -19,6 +19,7 @@ package aws
  */
 
 import (
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -29,6 +30,7 @@ import (
 	"go.uber.org/zap"
 
 	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 	"github.com/panther-labs/panther/pkg/awsretry"
 )
 
@@ -61,7 +63,15 @@ type cachedClient struct {
 	Credentials *credentials.Credentials
 }
 
-var clientCache = make(map[clientKey]cachedClient)
+var (
+	clientCache = make(map[clientKey]cachedClient)
+	// clientCacheLock guards clientCache, since regions may be polled concurrently
+	clientCacheLock sync.Mutex
+)
+
+// sharedClientCache is keyed by the assumed role rather than the integration, so integrations
+// auditing the same account share their clients and STS sessions.
+var sharedClientCache = utils.NewClientCache()
 
 func Setup() {
 	awsConfig := aws.NewConfig().WithMaxRetries(maxRetries)
@@ -81,10 +91,13 @@ func getClient(pollerInput *awsmodels.ResourcePollerInput,
 	}
 
 	// Return the cached client if the credentials used to build it are not expired
-	if cachedClient, exists := clientCache[cacheKey]; exists {
-		if !cachedClient.Credentials.IsExpired() {
-			if cachedClient.Client != nil {
-				return cachedClient.Client, nil
+	clientCacheLock.Lock()
+	cached, exists := clientCache[cacheKey]
+	clientCacheLock.Unlock()
+	if exists {
+		if !cached.Credentials.IsExpired() {
+			if cached.Client != nil {
+				return cached.Client, nil
 			}
 			zap.L().Debug("expired client was cached", zap.Any("cache key", cacheKey))
 		}
@@ -105,13 +118,58 @@ func getClient(pollerInput *awsmodels.ResourcePollerInput,
 		Credentials: creds,
 		Region:      &region,
 	})
+	clientCacheLock.Lock()
 	clientCache[cacheKey] = cachedClient{
 		Client:      client,
 		Credentials: creds,
 	}
+	clientCacheLock.Unlock()
 	return client, nil
 }
 
+// getSharedClient returns a valid client for a given role, service, and region from the shared client cache.
+//
+// Clients are cached until their credentials are about to expire. Credentials which can't tell
+// when they expire (like the static ones in unit tests) are never cached.
+func getSharedClient(pollerInput *awsmodels.ResourcePollerInput,
+	clientFunc func(session *session.Session, config *aws.Config) interface{},
+	service string, region string) (interface{}, error) {
+
+	key := utils.ClientCacheKey{Service: service, Region: region, RoleARN: aws.StringValue(pollerInput.AuthSource)}
+	return sharedClientCache.Get(key, func() (interface{}, time.Time, error) {
+		creds := assumeRoleFunc(pollerInput, snapshotPollerSession, region)
+		if err := verifyAssumedCredsFunc(creds, region); err != nil {
+			zap.L().Error(clientErrMessage,
+				zap.Error(err),
+				zap.String("service", service),
+				zap.String("region", region),
+				zap.Any("pollerInput", *pollerInput))
+			return nil, time.Time{}, err
+		}
+
+		client := clientFunc(snapshotPollerSession, &aws.Config{
+			Credentials: creds,
+			Region:      &region,
+		})
+		return client, credentialsExpiry(creds), nil
+	})
+}
+
+// credentialsExpiry returns when the credentials expire, zero if they can't tell (e.g. static credentials).
+//
+// The credentials are retrieved first, since the expiry of credentials which were never retrieved is unknown.
+func credentialsExpiry(creds *credentials.Credentials) time.Time {
+	if _, err := creds.Get(); err != nil {
+		return time.Time{}
+	}
+	expiresAt, err := creds.ExpiresAt()
+	if err != nil {
+		// ProviderNotExpirer
+		return time.Time{}
+	}
+	return expiresAt
+}
+
 //  assumes an IAM role associated with an AWS Snapshot Integration.
 func assumeRole(pollerInput *awsmodels.ResourcePollerInput, sess *session.Session, region string) *credentials.Credentials {
 	zap.L().Debug("assuming role", zap.String("roleArn", *pollerInput.AuthSource))
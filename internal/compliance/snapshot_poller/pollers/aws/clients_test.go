@@ -0,0 +1,76 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+)
+
+// The client func is still a test seam: clients built with the mock credentials are never cached
+func TestGetSharedClientNotCachedWithoutExpiry(t *testing.T) {
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+	pollerInput := &awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+	}
+
+	first := &awstest.MockCloudWatchLogs{}
+	awstest.MockCloudWatchLogsForSetup = first
+	client, err := getCloudWatchLogsClient(pollerInput, "us-west-2")
+	require.NoError(t, err)
+	assert.Same(t, first, client)
+
+	second := &awstest.MockCloudWatchLogs{}
+	awstest.MockCloudWatchLogsForSetup = second
+	client, err = getCloudWatchLogsClient(pollerInput, "us-west-2")
+	require.NoError(t, err)
+	assert.Same(t, second, client)
+}
+
+func TestCredentialsExpiry(t *testing.T) {
+	// Static credentials can't tell when they expire
+	assert.True(t, credentialsExpiry(credentials.NewStaticCredentials("key", "secret", "")).IsZero())
+
+	expiresAt := time.Now().Add(time.Hour).Round(time.Second)
+	provider := &expiringProvider{expiresAt: expiresAt}
+	assert.Equal(t, expiresAt, credentialsExpiry(credentials.NewCredentials(provider)))
+	assert.Equal(t, 1, provider.retrieved)
+}
+
+// expiringProvider returns credentials which expire at a fixed time
+type expiringProvider struct {
+	credentials.Expiry
+	expiresAt time.Time
+	retrieved int
+}
+
+func (p *expiringProvider) Retrieve() (credentials.Value, error) {
+	p.retrieved++
+	p.SetExpiration(p.expiresAt, 0)
+	return credentials.Value{AccessKeyID: "key", SecretAccessKey: "secret"}, nil
+}
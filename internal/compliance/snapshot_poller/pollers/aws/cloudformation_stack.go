@@ -38,6 +38,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.CloudFormationStackSchema, PollCloudFormationStack)
+}
+
 const (
 	// Time to delay the requeue of a scan of a CloudFormation stack whose drift detection was in
 	// progress when this scan started.
@@ -33,6 +33,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.CloudTrailSchema, PollCloudTrailTrail)
+}
+
 var (
 	// CloudTrailClientFunc is the function it setup the CloudTrail client.
 	CloudTrailClientFunc = setupCloudTrailClient
@@ -0,0 +1,215 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+)
+
+func init() {
+	RegisterSingleResourcePoller(awsmodels.CloudWatchLogsDestinationSchema, PollCloudWatchLogsDestination)
+}
+
+// PollCloudWatchLogsDestination polls a single CloudWatch Logs destination resource
+func PollCloudWatchLogsDestination(
+	pollerInput *awsmodels.ResourcePollerInput,
+	resourceARN arn.ARN,
+	scanRequest *pollermodels.ScanEntry,
+) (interface{}, error) {
+
+	if err := utils.ValidateRegion(resourceARN.Region); err != nil {
+		return nil, errors.Wrapf(err, "PollCloudWatchLogsDestination(%s)", resourceARN)
+	}
+	logger := utils.PollerLogger(pollerInput).With(zap.String("region", resourceARN.Region))
+	cloudwatchLogsSvc, err := getCloudWatchLogsClient(pollerInput, resourceARN.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resource portion of the ARN is "destination:<name>"
+	destinationName := strings.TrimPrefix(resourceARN.Resource, "destination:")
+	destination, err := getDestination(cloudwatchLogsSvc, destinationName)
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil {
+		// The destination may have been deleted
+		return nil, nil
+	}
+
+	snapshot := buildCloudWatchLogsDestinationSnapshot(logger, destination)
+	snapshot.Region = aws.String(resourceARN.Region)
+	snapshot.AccountID = aws.String(resourceARN.AccountID)
+	scanRequest.ResourceID = snapshot.ARN
+	return snapshot, nil
+}
+
+// getDestination returns the CloudWatch Logs destination with the given name, nil if it does not exist
+func getDestination(
+	cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI,
+	destinationName string,
+) (destination *cloudwatchlogs.Destination, err error) {
+
+	err = cloudwatchLogsSvc.DescribeDestinationsPages(
+		&cloudwatchlogs.DescribeDestinationsInput{DestinationNamePrefix: aws.String(destinationName)},
+		func(page *cloudwatchlogs.DescribeDestinationsOutput, lastPage bool) bool {
+			for _, candidate := range page.Destinations {
+				if aws.StringValue(candidate.DestinationName) == destinationName {
+					destination = candidate
+					return false
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "CloudWatchLogs.DescribeDestinations")
+	}
+	return destination, nil
+}
+
+// describeDestinations returns all CloudWatch Logs destinations in the account
+func describeDestinations(
+	cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI,
+) (destinations []*cloudwatchlogs.Destination, err error) {
+
+	err = cloudwatchLogsSvc.DescribeDestinationsPages(&cloudwatchlogs.DescribeDestinationsInput{},
+		func(page *cloudwatchlogs.DescribeDestinationsOutput, lastPage bool) bool {
+			destinations = append(destinations, page.Destinations...)
+			return true
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "CloudWatchLogs.DescribeDestinations")
+	}
+	return
+}
+
+// parseDestinationAccessPolicy parses the JSON access policy of a destination
+//
+// It returns nil if the destination has no access policy or the policy could not be parsed.
+func parseDestinationAccessPolicy(logger *zap.Logger, destination *cloudwatchlogs.Destination) map[string]interface{} {
+	if aws.StringValue(destination.AccessPolicy) == "" {
+		return nil
+	}
+
+	var policy map[string]interface{}
+	if err := jsoniter.UnmarshalFromString(*destination.AccessPolicy, &policy); err != nil {
+		logger.Warn("failed to parse destination access policy",
+			zap.String("destination", aws.StringValue(destination.DestinationName)),
+			zap.Error(err))
+		return nil
+	}
+	return policy
+}
+
+// buildCloudWatchLogsDestinationSnapshot returns a complete snapshot of a CloudWatch Logs destination
+func buildCloudWatchLogsDestinationSnapshot(
+	logger *zap.Logger,
+	destination *cloudwatchlogs.Destination,
+) *awsmodels.CloudWatchLogsDestination {
+
+	destinationSnapshot := &awsmodels.CloudWatchLogsDestination{
+		GenericResource: awsmodels.GenericResource{
+			ResourceID:   destination.Arn,
+			ResourceType: aws.String(awsmodels.CloudWatchLogsDestinationSchema),
+		},
+		GenericAWSResource: awsmodels.GenericAWSResource{
+			Name: destination.DestinationName,
+			ARN:  destination.Arn,
+		},
+		RoleArn:      destination.RoleArn,
+		TargetArn:    destination.TargetArn,
+		AccessPolicy: parseDestinationAccessPolicy(logger, destination),
+	}
+	if destination.CreationTime != nil {
+		// Convert milliseconds to seconds before converting to datetime
+		// loses nanosecond precision
+		destinationSnapshot.TimeCreated = utils.UnixTimeToDateTime(*destination.CreationTime / 1000)
+	}
+
+	return destinationSnapshot
+}
+
+// PollCloudWatchLogsDestinations gathers information on each CloudWatch Logs destination for an AWS account
+//
+// Like the log groups, regions are polled concurrently and an error is returned only if every region failed.
+func PollCloudWatchLogsDestinations(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
+	pollerLogger := utils.PollerLogger(pollerInput)
+	pollerLogger.Debug("starting CloudWatch Logs Destination resource poller")
+
+	regions := utils.GetServiceRegions(pollerInput.Regions, "logs")
+	resources, regionErrors := utils.PollRegions(regions, maxParallelLogGroupRegions,
+		func(region string) ([]*apimodels.AddResourceEntry, error) {
+			return pollCloudWatchLogsDestinationsRegion(pollerLogger.With(zap.String("region", region)), pollerInput, region)
+		})
+	if len(regionErrors) > 0 {
+		if len(regionErrors) == len(regions) {
+			return nil, errors.Wrapf(regionErrors, "PollCloudWatchLogsDestinations(%#v)", *pollerInput)
+		}
+		pollerLogger.Error("failed to poll CloudWatch Logs Destinations in some regions", zap.Error(regionErrors))
+	}
+	return resources, nil
+}
+
+// pollCloudWatchLogsDestinationsRegion gathers information on each CloudWatch Logs destination in a single region
+func pollCloudWatchLogsDestinationsRegion(
+	logger *zap.Logger, pollerInput *awsmodels.ResourcePollerInput, region string) ([]*apimodels.AddResourceEntry, error) {
+
+	cloudwatchLogsSvc, err := getCloudWatchLogsClient(pollerInput, region)
+	if err != nil {
+		return nil, err // error is logged in getSharedClient()
+	}
+
+	destinations, err := describeDestinations(cloudwatchLogsSvc)
+	if err != nil {
+		return nil, err
+	}
+	if len(destinations) == 0 {
+		logger.Debug("no CloudWatch Logs Destinations found")
+		return nil, nil
+	}
+
+	resources := make([]*apimodels.AddResourceEntry, 0, len(destinations))
+	for _, destination := range destinations {
+		destinationSnapshot := buildCloudWatchLogsDestinationSnapshot(logger, destination)
+		destinationSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+		destinationSnapshot.Region = aws.String(region)
+
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      destinationSnapshot,
+			ID:              apimodels.ResourceID(*destinationSnapshot.ARN),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.CloudWatchLogsDestinationSchema,
+		})
+	}
+	return resources, nil
+}
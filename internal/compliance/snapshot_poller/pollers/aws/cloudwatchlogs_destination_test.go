@@ -0,0 +1,175 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+)
+
+func TestCloudWatchLogsDestinationsDescribe(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"DescribeDestinationsPages"})
+
+	out, err := describeDestinations(mockSvc)
+	require.NoError(t, err)
+	assert.Len(t, out, 2)
+}
+
+func TestCloudWatchLogsDestinationsDescribeError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeDestinationsPages"})
+
+	out, err := describeDestinations(mockSvc)
+	require.Error(t, err)
+	assert.Nil(t, out)
+}
+
+func TestBuildCloudWatchLogsDestinationSnapshot(t *testing.T) {
+	destination := awstest.ExampleDescribeDestinations.Destinations[0]
+
+	snapshot := buildCloudWatchLogsDestinationSnapshot(zap.L(), destination)
+	assert.Equal(t, destination.Arn, snapshot.ResourceID)
+	assert.Equal(t, destination.Arn, snapshot.ARN)
+	assert.Equal(t, destination.DestinationName, snapshot.Name)
+	assert.Equal(t, destination.RoleArn, snapshot.RoleArn)
+	assert.Equal(t, destination.TargetArn, snapshot.TargetArn)
+	assert.NotNil(t, snapshot.TimeCreated)
+	require.NotNil(t, snapshot.AccessPolicy)
+	assert.Equal(t, "2012-10-17", snapshot.AccessPolicy["Version"])
+	assert.Len(t, snapshot.AccessPolicy["Statement"], 1)
+}
+
+func TestBuildCloudWatchLogsDestinationSnapshotNoAccessPolicy(t *testing.T) {
+	snapshot := buildCloudWatchLogsDestinationSnapshot(zap.L(), awstest.ExampleDescribeDestinations.Destinations[1])
+	assert.Nil(t, snapshot.AccessPolicy)
+}
+
+func TestBuildCloudWatchLogsDestinationSnapshotInvalidAccessPolicy(t *testing.T) {
+	snapshot := buildCloudWatchLogsDestinationSnapshot(zap.L(), &cloudwatchlogs.Destination{
+		DestinationName: aws.String("Destination-1"),
+		Arn:             aws.String("arn:aws:logs:us-west-2:123456789012:destination:Destination-1"),
+		AccessPolicy:    aws.String("not json"),
+	})
+	assert.Nil(t, snapshot.AccessPolicy)
+	assert.Nil(t, snapshot.TimeCreated)
+}
+
+func TestCloudWatchLogsDestinationPoller(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAll()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsDestinations(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, resources)
+	assert.Equal(t, apimodels.ResourceType(awsmodels.CloudWatchLogsDestinationSchema), resources[0].Type)
+}
+
+func TestCloudWatchLogsDestinationPollerError(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAllError()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsDestinations(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.Error(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestCloudWatchLogsDestinationPollerSingle(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAll()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resourceARN, err := arn.Parse("arn:aws:logs:us-west-2:123456789012:destination:Destination-2")
+	require.NoError(t, err)
+	scanRequest := &pollermodels.ScanEntry{}
+	resource, err := PollCloudWatchLogsDestination(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Timestamp:           &awstest.ExampleTime,
+	}, resourceARN, scanRequest)
+
+	require.NoError(t, err)
+	snapshot := resource.(*awsmodels.CloudWatchLogsDestination)
+	assert.Equal(t, "Destination-2", *snapshot.Name)
+	assert.Equal(t, "us-west-2", *snapshot.Region)
+	assert.Equal(t, "123456789012", *snapshot.AccountID)
+	assert.Equal(t, snapshot.ARN, scanRequest.ResourceID)
+}
+
+func TestCloudWatchLogsDestinationPollerSingleNotFound(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAll()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resourceARN, err := arn.Parse("arn:aws:logs:us-west-2:123456789012:destination:Deleted")
+	require.NoError(t, err)
+	resource, err := PollCloudWatchLogsDestination(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Timestamp:           &awstest.ExampleTime,
+	}, resourceARN, &pollermodels.ScanEntry{})
+
+	require.NoError(t, err)
+	assert.Nil(t, resource)
+}
+
+func TestCloudWatchLogsDestinationPollerSingleError(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAllError()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resourceARN, err := arn.Parse("arn:aws:logs:us-west-2:123456789012:destination:Destination-1")
+	require.NoError(t, err)
+	resource, err := PollCloudWatchLogsDestination(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Timestamp:           &awstest.ExampleTime,
+	}, resourceARN, &pollermodels.ScanEntry{})
+
+	require.Error(t, err)
+	assert.Nil(t, resource)
+}
@@ -19,13 +19,24 @@ package aws
  */
 
 import (
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-openapi/strfmt"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -35,21 +46,108 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.CloudWatchLogGroupSchema, PollCloudWatchLogsLogGroup)
+}
+
+const (
+	// The number of log groups requested per page when looking up a single log group
+	getLogGroupPageSize = 50
+
+	// The default (and maximum) number of log groups per DescribeLogGroups page
+	maxDescribeLogGroupsPageSize = 50
+
+	// A page size reduced by throttling is doubled again after this many pages in a row succeed
+	logGroupPageSizeRecoveryPages = 3
+
+	// The default number of log groups enumerated per region in a single scan
+	defaultMaxLogGroups = 10000
+
+	// Log groups which ingested data within this window are in use
+	recentIngestionWindow = 30 * 24 * time.Hour
+)
+
 // Set as variables to be overridden in testing
 var (
 	CloudWatchLogsClientFunc = setupCloudWatchLogsClient
+
+	// How long a throttled GetDataProtectionPolicy is retried before the policy is skipped for the rest of the poll
+	maxDataProtectionPolicyBackoff = 30 * time.Second
+
+	// Safety cap on the log groups enumerated per region, set with MAX_LOG_GROUPS.
+	// The error of an invalid value is logged by each scan, with the fields of its poller logger.
+	maxLogGroups, maxLogGroupsErr = getMaxLogGroups()
 )
 
+// getMaxLogGroups reads the log group cap from the environment, falling back to the default if unset or invalid
+func getMaxLogGroups() (int, error) {
+	env := os.Getenv("MAX_LOG_GROUPS")
+	if env == "" {
+		return defaultMaxLogGroups, nil
+	}
+	limit, err := strconv.Atoi(env)
+	if err != nil || limit <= 0 {
+		return defaultMaxLogGroups, errors.Errorf("invalid MAX_LOG_GROUPS %q, using the default of %d", env, defaultMaxLogGroups)
+	}
+	return limit, nil
+}
+
+// warnInvalidMaxLogGroups logs the MAX_LOG_GROUPS configuration error, if any
+func warnInvalidMaxLogGroups(logger *zap.Logger) {
+	if maxLogGroupsErr != nil {
+		logger.Warn("invalid log group cap", zap.Error(maxLogGroupsErr))
+	}
+}
+
 func setupCloudWatchLogsClient(sess *session.Session, cfg *aws.Config) interface{} {
-	return cloudwatchlogs.New(sess, cfg)
+	return &cloudWatchLogsClient{CloudWatchLogs: cloudwatchlogs.New(sess, cfg)}
+}
+
+// cloudWatchLogsDataProtectionAPI looks up the data protection policy attached to a log group
+type cloudWatchLogsDataProtectionAPI interface {
+	GetDataProtectionPolicy(logGroupIdentifier *string) (policyDocument *string, err error)
+}
+
+// cloudWatchLogsClient adds the CloudWatch Logs API calls which our version of the SDK does not model yet
+type cloudWatchLogsClient struct {
+	*cloudwatchlogs.CloudWatchLogs
+}
+
+type getDataProtectionPolicyInput struct {
+	_ struct{} `type:"structure"`
+
+	LogGroupIdentifier *string `locationName:"logGroupIdentifier" min:"1" type:"string" required:"true"`
+}
+
+type getDataProtectionPolicyOutput struct {
+	_ struct{} `type:"structure"`
+
+	LastUpdatedTime    *int64  `locationName:"lastUpdatedTime" type:"long"`
+	LogGroupIdentifier *string `locationName:"logGroupIdentifier" min:"1" type:"string"`
+	PolicyDocument     *string `locationName:"policyDocument" type:"string"`
+}
+
+// GetDataProtectionPolicy returns the data protection policy document attached to a log group
+func (c *cloudWatchLogsClient) GetDataProtectionPolicy(logGroupIdentifier *string) (*string, error) {
+	op := &request.Operation{
+		Name:       "GetDataProtectionPolicy",
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+	}
+	output := &getDataProtectionPolicyOutput{}
+	req := c.NewRequest(op, &getDataProtectionPolicyInput{LogGroupIdentifier: logGroupIdentifier}, output)
+	if err := req.Send(); err != nil {
+		return nil, err
+	}
+	return output.PolicyDocument, nil
 }
 
 func getCloudWatchLogsClient(pollerResourceInput *awsmodels.ResourcePollerInput,
 	region string) (cloudwatchlogsiface.CloudWatchLogsAPI, error) {
 
-	client, err := getClient(pollerResourceInput, CloudWatchLogsClientFunc, "cloudwatchlogs", region)
+	client, err := getSharedClient(pollerResourceInput, CloudWatchLogsClientFunc, "cloudwatchlogs", region)
 	if err != nil {
-		return nil, err // error is logged in getClient()
+		return nil, err // error is logged in getSharedClient()
 	}
 
 	return client.(cloudwatchlogsiface.CloudWatchLogsAPI), nil
@@ -61,6 +159,10 @@ func PollCloudWatchLogsLogGroup(
 	resourceARN arn.ARN,
 	scanRequest *pollermodels.ScanEntry) (resource interface{}, err error) {
 
+	if err := utils.ValidateRegion(resourceARN.Region); err != nil {
+		return nil, errors.Wrapf(err, "PollCloudWatchLogsLogGroup(%s)", resourceARN)
+	}
+	logger := utils.PollerLogger(pollerResourceInput).With(zap.String("region", resourceARN.Region))
 	cwClient, err := getCloudWatchLogsClient(pollerResourceInput, resourceARN.Region)
 	if err != nil {
 		return nil, err
@@ -72,13 +174,15 @@ func PollCloudWatchLogsLogGroup(
 
 	// Split out the log group name from any additional modifiers
 	lgName := strings.Split(lgResource, ":")[0]
-	logGroup := getLogGroup(cwClient, lgName)
+	logGroup := getLogGroup(logger, cwClient, lgName)
 	if logGroup == nil {
 		// this can happen in case we didn't find the requested log group - it might have been deleted
 		// or we might have encountered some issue with querying for it
 		return nil, nil
 	}
-	snapshot := buildCloudWatchLogsLogGroupSnapshot(cwClient, logGroup)
+	kmsClient := getLogGroupKMSClient(logger, pollerResourceInput, resourceARN.Region)
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		logger, cwClient, kmsClient, &logGroupPermissionWarnings{}, logGroup, pollerResourceInput.ResolveIngestionTime)
 	if snapshot == nil {
 		return nil, nil
 	}
@@ -89,56 +193,469 @@ func PollCloudWatchLogsLogGroup(
 }
 
 // getLogGroup returns a specific cloudwatch logs log group
-func getLogGroup(svc cloudwatchlogsiface.CloudWatchLogsAPI, logGroupName string) *cloudwatchlogs.LogGroup {
-	logGroups, err := svc.DescribeLogGroups(&cloudwatchlogs.DescribeLogGroupsInput{
+//
+// Log groups are listed in order of their name, so the exact match is the first log group with the name as its
+// prefix. Listing stops as soon as it is found (or can no longer be found), even if many log groups share the prefix.
+func getLogGroup(logger *zap.Logger, svc cloudwatchlogsiface.CloudWatchLogsAPI, logGroupName string) *cloudwatchlogs.LogGroup {
+	var logGroup *cloudwatchlogs.LogGroup
+	err := svc.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{
 		LogGroupNamePrefix: &logGroupName,
+		Limit:              aws.Int64(getLogGroupPageSize),
+	}, func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		for _, group := range page.LogGroups {
+			name := aws.StringValue(group.LogGroupName)
+			if name == logGroupName {
+				logGroup = group
+				return false
+			}
+			if name > logGroupName {
+				// Every following log group sorts after this one, so there is no exact match
+				return false
+			}
+		}
+		return true
 	})
 	if err != nil {
-		utils.LogAWSError("CloudWatchLogs.DescribeLogGroups", err)
+		utils.LogAWSErrorTo(logger, "CloudWatchLogs.DescribeLogGroups", err)
 		return nil
 	}
-
-	for _, logGroup := range logGroups.LogGroups {
-		if *logGroup.LogGroupName == logGroupName {
-			return logGroup
-		}
+	if logGroup != nil {
+		return logGroup
 	}
 
-	zap.L().Warn("tried to scan non-existent resource",
+	logger.Warn("tried to scan non-existent resource",
 		zap.String("resource", logGroupName),
 		zap.String("resourceType", awsmodels.CloudWatchLogGroupSchema))
 	return nil
 }
 
 // describeLogGroups returns all Log Groups in the account
-func describeLogGroups(cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI) (logGroups []*cloudwatchlogs.LogGroup, err error) {
-	err = cloudwatchLogsSvc.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{},
-		func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
-			logGroups = append(logGroups, page.LogGroups...)
-			return true
-		})
-	if err != nil {
-		return nil, errors.Wrap(err, "CloudWatchLogs.DescribeLogGroups")
+func describeLogGroups(
+	logger *zap.Logger,
+	cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI,
+) (logGroups []*cloudwatchlogs.LogGroup, err error) {
+
+	return describeLogGroupsWithInput(logger, cloudwatchLogsSvc, &cloudwatchlogs.DescribeLogGroupsInput{})
+}
+
+// describeLogGroupsByPrefix returns all Log Groups in the account whose name starts with prefix
+func describeLogGroupsByPrefix(
+	logger *zap.Logger,
+	cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI,
+	prefix string,
+) (logGroups []*cloudwatchlogs.LogGroup, err error) {
+
+	return describeLogGroupsWithInput(logger, cloudwatchLogsSvc, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(prefix),
+	})
+}
+
+// describeLogGroupsWithInput pages through all Log Groups matching the given input
+//
+// Paging stops early once maxLogGroups have been listed, or if the API returns the same
+// NextToken twice in a row, which would otherwise loop forever.
+// If a page is throttled (after the retries of the SDK), paging resumes from the last page with smaller pages,
+// see logGroupPageSizer.
+func describeLogGroupsWithInput(
+	logger *zap.Logger,
+	cloudwatchLogsSvc cloudwatchlogsiface.CloudWatchLogsAPI,
+	input *cloudwatchlogs.DescribeLogGroupsInput,
+) (logGroups []*cloudwatchlogs.LogGroup, err error) {
+
+	var previousToken *string
+	pageSizer := &logGroupPageSizer{size: maxDescribeLogGroupsPageSize}
+	for {
+		pageInput := *input
+		pageInput.Limit = pageSizer.limit()
+		pageInput.NextToken = previousToken
+		resized := false
+		err = cloudwatchLogsSvc.DescribeLogGroupsPages(&pageInput,
+			func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+				logGroups = append(logGroups, page.LogGroups...)
+				if len(logGroups) >= maxLogGroups {
+					if len(logGroups) > maxLogGroups || !lastPage {
+						logger.Warn("too many log groups, skipping the rest",
+							zap.Int("maxLogGroups", maxLogGroups),
+							zap.String("prefix", aws.StringValue(input.LogGroupNamePrefix)))
+					}
+					logGroups = logGroups[:maxLogGroups]
+					return false
+				}
+				if page.NextToken != nil && aws.StringValue(page.NextToken) == aws.StringValue(previousToken) {
+					logger.Warn("DescribeLogGroups returned the same NextToken twice, stopping",
+						zap.String("nextToken", aws.StringValue(page.NextToken)))
+					return false
+				}
+				previousToken = page.NextToken
+				// Paging continues with the larger page size from the next page
+				resized = !lastPage && pageSizer.succeeded()
+				return !resized
+			})
+		if err != nil && request.IsErrorThrottle(err) && pageSizer.throttled() {
+			logger.Warn("DescribeLogGroups throttled, reducing the page size",
+				zap.Int64("pageSize", pageSizer.size), zap.Int("logGroups", len(logGroups)))
+			continue
+		}
+		if err != nil {
+			return nil, utils.WrapAWSError("CloudWatchLogs.DescribeLogGroups", err)
+		}
+		if !resized {
+			return logGroups, nil
+		}
 	}
-	return
+}
+
+// logGroupPageSizer adapts the DescribeLogGroups page size to throttling during a single listing.
+//
+// The page size is halved each time a page is throttled, so retrying is cheaper while the account is
+// under pressure, and doubled back after logGroupPageSizeRecoveryPages pages in a row succeed.
+type logGroupPageSizer struct {
+	size      int64
+	successes int
+}
+
+// limit returns the Limit of the next page, nil for the default page size
+func (s *logGroupPageSizer) limit() *int64 {
+	if s.size >= maxDescribeLogGroupsPageSize {
+		return nil
+	}
+	return aws.Int64(s.size)
+}
+
+// throttled halves the page size, returns false if it is already down to a single log group
+func (s *logGroupPageSizer) throttled() bool {
+	s.successes = 0
+	if s.size <= 1 {
+		return false
+	}
+	s.size /= 2
+	return true
+}
+
+// succeeded records a page which was not throttled, returns true if the page size grew
+func (s *logGroupPageSizer) succeeded() bool {
+	if s.size >= maxDescribeLogGroupsPageSize {
+		return false
+	}
+	s.successes++
+	if s.successes < logGroupPageSizeRecoveryPages {
+		return false
+	}
+	s.successes = 0
+	s.size *= 2
+	if s.size > maxDescribeLogGroupsPageSize {
+		s.size = maxDescribeLogGroupsPageSize
+	}
+	return true
+}
+
+// logGroupPermissionWarnings reports the permissions missing to fully scan the log groups once per poll,
+// rather than once for each log group. A nil value reports them every time.
+//
+// It also remembers when GetDataProtectionPolicy stayed throttled through its retries, so the rest of the
+// log groups of the poll skip the policy instead of each waiting for their own retries.
+type logGroupPermissionWarnings struct {
+	listTags sync.Once
+
+	dataProtectionThrottled sync.Once
+	skipDataProtection      bool // the log groups of a poll are scanned one at a time
+}
+
+// dataProtectionPolicyThrottled stops the data protection policy lookups for the rest of the poll
+func (w *logGroupPermissionWarnings) dataProtectionPolicyThrottled(logger *zap.Logger, err error) {
+	warn := func() {
+		logger.Warn("ThrottlingException, the rest of the log groups are scanned without their data protection policy",
+			zap.String("API", "CloudWatchLogs.GetDataProtectionPolicy"), zap.Error(err))
+	}
+	if w == nil {
+		warn()
+		return
+	}
+	w.skipDataProtection = true
+	w.dataProtectionThrottled.Do(warn)
+}
+
+// dataProtectionPolicySkipped returns true if the data protection policy lookups were stopped by throttling
+func (w *logGroupPermissionWarnings) dataProtectionPolicySkipped() bool {
+	return w != nil && w.skipDataProtection
+}
+
+func (w *logGroupPermissionWarnings) listTagsDenied(logger *zap.Logger, err error) {
+	warn := func() {
+		logger.Warn("AccessDeniedException, log groups are scanned without their tags",
+			zap.String("API", "CloudWatchLogs.ListTagsLogGroup"), zap.Error(err))
+	}
+	if w == nil {
+		warn()
+		return
+	}
+	w.listTags.Do(warn)
 }
 
 // listTagsLogGroup returns the tags for a given log group
-func listTagsLogGroup(svc cloudwatchlogsiface.CloudWatchLogsAPI, groupName *string) map[string]*string {
-	tags, err := svc.ListTagsLogGroup(&cloudwatchlogs.ListTagsLogGroupInput{
+//
+// vanished is true if the log group no longer exists, e.g. because it was deleted after it was listed.
+// If the tags can't be listed, e.g. because logs:ListTagsLogGroup is not allowed, the tags are nil.
+func listTagsLogGroup(
+	logger *zap.Logger,
+	svc cloudwatchlogsiface.CloudWatchLogsAPI,
+	warnings *logGroupPermissionWarnings,
+	groupName *string,
+) (tags map[string]*string, vanished bool) {
+
+	out, err := svc.ListTagsLogGroup(&cloudwatchlogs.ListTagsLogGroupInput{
 		LogGroupName: groupName,
 	})
 	if err != nil {
-		utils.LogAWSError("CloudWatchLogs ListTagsLogGroup", err)
+		if awsErr, ok := err.(awserr.Error); ok {
+			switch awsErr.Code() {
+			case cloudwatchlogs.ErrCodeResourceNotFoundException:
+				return nil, true
+			case "AccessDeniedException":
+				warnings.listTagsDenied(logger, err)
+				return nil, false
+			}
+		}
+		utils.LogAWSErrorTo(logger, "CloudWatchLogs ListTagsLogGroup", err)
+		return nil, false
+	}
+	return out.Tags, false
+}
+
+// getDataProtectionPolicy returns whether a log group has a data protection policy, and the name of that policy
+//
+// Both values are nil if the policy could not be retrieved. Throttled requests are retried for up to
+// maxDataProtectionPolicyBackoff, after which the policy is nil for the rest of the log groups sharing warnings.
+func getDataProtectionPolicy(
+	logger *zap.Logger,
+	svc cloudWatchLogsDataProtectionAPI,
+	warnings *logGroupPermissionWarnings,
+	groupName *string,
+) (enabled *bool, name *string) {
+
+	if warnings.dataProtectionPolicySkipped() {
+		return nil, nil
+	}
+
+	var policyDocument *string
+	getPolicy := func() (err error) {
+		if policyDocument, err = svc.GetDataProtectionPolicy(groupName); err != nil {
+			if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "ThrottlingException" {
+				return err
+			}
+			return backoff.Permanent(err)
+		}
 		return nil
 	}
-	return tags.Tags
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = maxDataProtectionPolicyBackoff
+	if err := backoff.Retry(getPolicy, expBackoff); err != nil {
+		// Log groups without a data protection policy return a ResourceNotFoundException
+		if awsErr, ok := err.(awserr.Error); ok {
+			switch awsErr.Code() {
+			case cloudwatchlogs.ErrCodeResourceNotFoundException:
+				return aws.Bool(false), nil
+			case "ThrottlingException":
+				warnings.dataProtectionPolicyThrottled(logger, err)
+				return nil, nil
+			}
+		}
+		utils.LogAWSErrorTo(logger, "CloudWatchLogs.GetDataProtectionPolicy", err)
+		return nil, nil
+	}
+
+	if aws.StringValue(policyDocument) == "" {
+		return aws.Bool(false), nil
+	}
+
+	// The policy is identified by the Name field of the policy document
+	var policy struct {
+		Name *string
+	}
+	if err := jsoniter.UnmarshalFromString(*policyDocument, &policy); err != nil {
+		logger.Warn("failed to parse data protection policy",
+			zap.String("logGroup", aws.StringValue(groupName)),
+			zap.Error(err))
+	}
+	return aws.Bool(true), policy.Name
+}
+
+// getLogGroupKMSClient returns the KMS client used to resolve log group keys.
+//
+// The client describes each key once (see cachedKMSClient), so it should be used for a single poll of a region.
+// It returns nil if the scan did not request KMS keys to be resolved, or if the client could not be built.
+func getLogGroupKMSClient(
+	logger *zap.Logger,
+	pollerInput *awsmodels.ResourcePollerInput,
+	region string,
+) kmsiface.KMSAPI {
+
+	if !pollerInput.ResolveKMSKeys {
+		return nil
+	}
+	kmsClient, err := getKMSClient(pollerInput, region)
+	if err != nil {
+		logger.Warn("unable to resolve log group KMS keys", zap.Error(err))
+		return nil
+	}
+	return newCachedKMSClient(kmsClient)
+}
+
+// cachedKMSClient remembers the key descriptions and rotation statuses it has fetched, including failures.
+//
+// Many log groups are often encrypted with the same key, which would otherwise be described once per log group.
+// It is not safe for concurrent use, each region is polled with its own client.
+type cachedKMSClient struct {
+	kmsiface.KMSAPI
+	keys      map[string]cachedKMSKey
+	rotations map[string]cachedKMSRotation
+}
+
+type cachedKMSKey struct {
+	out *kms.DescribeKeyOutput
+	err error
+}
+
+type cachedKMSRotation struct {
+	out *kms.GetKeyRotationStatusOutput
+	err error
+}
+
+func newCachedKMSClient(kmsSvc kmsiface.KMSAPI) *cachedKMSClient {
+	return &cachedKMSClient{
+		KMSAPI:    kmsSvc,
+		keys:      make(map[string]cachedKMSKey),
+		rotations: make(map[string]cachedKMSRotation),
+	}
+}
+
+func (c *cachedKMSClient) DescribeKey(input *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	keyID := aws.StringValue(input.KeyId)
+	if cached, ok := c.keys[keyID]; ok {
+		return cached.out, cached.err
+	}
+	out, err := c.KMSAPI.DescribeKey(input)
+	c.keys[keyID] = cachedKMSKey{out: out, err: err}
+	return out, err
+}
+
+func (c *cachedKMSClient) GetKeyRotationStatus(
+	input *kms.GetKeyRotationStatusInput) (*kms.GetKeyRotationStatusOutput, error) {
+
+	keyID := aws.StringValue(input.KeyId)
+	if cached, ok := c.rotations[keyID]; ok {
+		return cached.out, cached.err
+	}
+	out, err := c.KMSAPI.GetKeyRotationStatus(input)
+	c.rotations[keyID] = cachedKMSRotation{out: out, err: err}
+	return out, err
+}
+
+// getLogGroupKMSKeyStatus returns the rotation status and state of the KMS key which encrypts a log group
+//
+// Both are nil if the key can't be resolved, e.g. when access to the key is denied or it is in another account.
+// This never fails the scan of the log group.
+func getLogGroupKMSKeyStatus(
+	logger *zap.Logger,
+	kmsSvc kmsiface.KMSAPI,
+	keyID *string,
+) (rotationEnabled *bool, keyState *string) {
+
+	metadata, err := describeKey(kmsSvc, keyID)
+	if err != nil {
+		return nil, nil // error is logged in describeKey()
+	}
+
+	rotationEnabled, err = getKeyRotationStatus(kmsSvc, metadata.KeyId)
+	if err != nil {
+		utils.LogAWSErrorTo(logger, "KMS.GetKeyRotationStatus", err)
+		rotationEnabled = nil
+	}
+	return rotationEnabled, metadata.KeyState
+}
+
+// getLastIngestionTime returns when data was last ingested into any log stream of a log group
+//
+// Only the log stream with the most recent event is described. Log groups without any log streams,
+// or whose latest log stream never received data, were never ingested into.
+// Both values are nil if the log streams could not be described.
+func getLastIngestionTime(
+	logger *zap.Logger,
+	svc cloudwatchlogsiface.CloudWatchLogsAPI,
+	groupName *string,
+) (lastIngestion *strfmt.DateTime, neverIngested *bool) {
+
+	out, err := svc.DescribeLogStreams(&cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName: groupName,
+		OrderBy:      aws.String(cloudwatchlogs.OrderByLastEventTime),
+		Descending:   aws.Bool(true),
+		Limit:        aws.Int64(1),
+	})
+	if err != nil {
+		utils.LogAWSErrorTo(logger, "CloudWatchLogs.DescribeLogStreams", err)
+		return nil, nil
+	}
+	if len(out.LogStreams) == 0 || out.LogStreams[0].LastIngestionTime == nil {
+		return nil, aws.Bool(true)
+	}
+	// Convert milliseconds to seconds before converting to datetime
+	return utils.UnixTimeToDateTime(*out.LogStreams[0].LastIngestionTime / 1000), aws.Bool(false)
+}
+
+// getSubscriptionFilterCount returns the number of subscription filters of a log group, nil if it can't be listed
+//
+// A log group has at most two subscription filters, so they all fit in one page.
+func getSubscriptionFilterCount(
+	logger *zap.Logger,
+	svc cloudwatchlogsiface.CloudWatchLogsAPI,
+	groupName *string,
+) *int64 {
+
+	out, err := svc.DescribeSubscriptionFilters(&cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName: groupName,
+	})
+	if err != nil {
+		utils.LogAWSErrorTo(logger, "CloudWatchLogs.DescribeSubscriptionFilters", err)
+		return nil
+	}
+	return aws.Int64(int64(len(out.SubscriptionFilters)))
+}
+
+// setLogGroupUsage derives the usage flags of a log group snapshot, see CloudWatchLogsLogGroup.Orphaned
+func setLogGroupUsage(snapshot *awsmodels.CloudWatchLogsLogGroup, now time.Time) {
+	switch {
+	case snapshot.NeverIngested == nil:
+		return // the ingestion time is unknown
+	case *snapshot.NeverIngested:
+		snapshot.RecentlyIngested = aws.Bool(false)
+	default:
+		lastIngestion := time.Time(*snapshot.LastIngestionTime)
+		snapshot.RecentlyIngested = aws.Bool(now.Sub(lastIngestion) < recentIngestionWindow)
+	}
+
+	if snapshot.SubscriptionFilterCount == nil || snapshot.MetricFilterCount == nil {
+		return
+	}
+	snapshot.Orphaned = aws.Bool(aws.BoolValue(snapshot.RetentionNeverExpires) &&
+		*snapshot.MetricFilterCount == 0 &&
+		*snapshot.SubscriptionFilterCount == 0 &&
+		!*snapshot.RecentlyIngested)
 }
 
 // buildCloudWatchLogsLogGroupSnapshot returns a complete snapshot of a LogGroup
+//
+// The KMS key status is only resolved if kmsSvc is not nil. The last ingestion time, the subscription filters
+// and the usage flags derived from them are only resolved if resolveIngestion is set.
+// Missing permissions are reported through warnings, which should be shared by the log groups of a poll.
+// Returns nil if the log group was deleted while it was being scanned.
 func buildCloudWatchLogsLogGroupSnapshot(
+	logger *zap.Logger,
 	svc cloudwatchlogsiface.CloudWatchLogsAPI,
+	kmsSvc kmsiface.KMSAPI,
+	warnings *logGroupPermissionWarnings,
 	logGroup *cloudwatchlogs.LogGroup,
+	resolveIngestion bool,
 ) *awsmodels.CloudWatchLogsLogGroup {
 
 	logGroupSnapshot := &awsmodels.CloudWatchLogsLogGroup{
@@ -157,51 +674,167 @@ func buildCloudWatchLogsLogGroupSnapshot(
 		MetricFilterCount: logGroup.MetricFilterCount,
 		RetentionInDays:   logGroup.RetentionInDays,
 		StoredBytes:       logGroup.StoredBytes,
+
+		RetentionConfigured:   aws.Bool(logGroup.RetentionInDays != nil),
+		RetentionNeverExpires: aws.Bool(logGroup.RetentionInDays == nil),
+	}
+	var vanished bool
+	if logGroupSnapshot.Tags, vanished = listTagsLogGroup(logger, svc, warnings, logGroupSnapshot.Name); vanished {
+		logger.Debug("log group was deleted while it was being scanned, skipping",
+			zap.String("logGroup", aws.StringValue(logGroupSnapshot.Name)))
+		return nil
+	}
+	if dataProtectionSvc, ok := svc.(cloudWatchLogsDataProtectionAPI); ok {
+		logGroupSnapshot.DataProtectionPolicyEnabled, logGroupSnapshot.DataProtectionPolicyName =
+			getDataProtectionPolicy(logger, dataProtectionSvc, warnings, logGroupSnapshot.Name)
+	}
+	if kmsSvc != nil && logGroup.KmsKeyId != nil {
+		logGroupSnapshot.KmsKeyRotationEnabled, logGroupSnapshot.KmsKeyState =
+			getLogGroupKMSKeyStatus(logger, kmsSvc, logGroup.KmsKeyId)
+	}
+	if resolveIngestion {
+		logGroupSnapshot.LastIngestionTime, logGroupSnapshot.NeverIngested =
+			getLastIngestionTime(logger, svc, logGroupSnapshot.Name)
+		logGroupSnapshot.SubscriptionFilterCount = getSubscriptionFilterCount(logger, svc, logGroupSnapshot.Name)
+		setLogGroupUsage(logGroupSnapshot, time.Now())
 	}
-	logGroupSnapshot.Tags = listTagsLogGroup(svc, logGroupSnapshot.Name)
 
 	return logGroupSnapshot
 }
 
+// PollCloudWatchLogsLogGroupsByPrefix polls every CloudWatchLogs LogGroup in a region whose name starts with prefix
+//
+// Unlike PollCloudWatchLogsLogGroup, which only returns an exact name match, this allows re-scanning a family
+// of related log groups (e.g. /aws/lambda/) without sweeping the entire account.
+func PollCloudWatchLogsLogGroupsByPrefix(
+	pollerInput *awsmodels.ResourcePollerInput,
+	region string,
+	prefix string,
+) ([]*apimodels.AddResourceEntry, error) {
+
+	if err := utils.ValidateRegion(region); err != nil {
+		return nil, errors.Wrapf(err, "PollCloudWatchLogsLogGroupsByPrefix(%q)", prefix)
+	}
+	logger := utils.PollerLogger(pollerInput).With(zap.String("region", region), zap.String("prefix", prefix))
+	warnInvalidMaxLogGroups(logger)
+	cwClient, err := getCloudWatchLogsClient(pollerInput, region)
+	if err != nil {
+		return nil, err // error is logged in getSharedClient()
+	}
+
+	logGroups, err := describeLogGroupsByPrefix(logger, cwClient, prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "PollCloudWatchLogsLogGroupsByPrefix(%q) in region %s", prefix, region)
+	}
+
+	kmsClient := getLogGroupKMSClient(logger, pollerInput, region)
+	warnings := &logGroupPermissionWarnings{}
+	resources := make([]*apimodels.AddResourceEntry, 0, len(logGroups))
+	for _, logGroup := range logGroups {
+		snapshot := buildCloudWatchLogsLogGroupSnapshot(
+			logger, cwClient, kmsClient, warnings, logGroup, pollerInput.ResolveIngestionTime)
+		if snapshot == nil {
+			continue
+		}
+		snapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+		snapshot.Region = aws.String(region)
+
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      snapshot,
+			ID:              apimodels.ResourceID(*snapshot.ARN),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.CloudWatchLogGroupSchema,
+		})
+	}
+
+	return resources, nil
+}
+
+// The maximum number of regions in which log groups are polled at the same time
+const maxParallelLogGroupRegions = 5
+
 // PollCloudWatchLogsLogGroups gathers information on each CloudWatchLogs LogGroup for an AWS account
+//
+// Regions are polled concurrently. If only some of them fail, the log groups of the other regions
+// are returned and the failures are logged; an error is returned only if every region failed.
+//
+// Unsupported regions are rejected before polling: if every region is unsupported an error is returned,
+// otherwise the log groups of the supported regions are returned along with the utils.RegionErrors
+// of the unsupported ones.
 func PollCloudWatchLogsLogGroups(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
-	zap.L().Debug("starting CloudWatch LogGroup resource poller")
-	logGroupSnapshots := make(map[string]*awsmodels.CloudWatchLogsLogGroup)
+	pollerLogger := utils.PollerLogger(pollerInput)
+	pollerLogger.Debug("starting CloudWatch LogGroup resource poller")
+	warnInvalidMaxLogGroups(pollerLogger)
 
-	for _, regionID := range utils.GetServiceRegions(pollerInput.Regions, "logs") {
-		cloudwatchLogGroupSvc, err := getCloudWatchLogsClient(pollerInput, *regionID)
-		if err != nil {
-			return nil, err // error is logged in getClient()
+	var invalidRegions utils.RegionErrors
+	for _, region := range pollerInput.Regions {
+		if err := utils.ValidateRegion(aws.StringValue(region)); err != nil {
+			if invalidRegions == nil {
+				invalidRegions = make(utils.RegionErrors)
+			}
+			invalidRegions[aws.StringValue(region)] = err
 		}
+	}
+	if len(invalidRegions) > 0 && len(invalidRegions) == len(pollerInput.Regions) {
+		return nil, errors.Wrap(invalidRegions, "PollCloudWatchLogsLogGroups: no supported region")
+	}
 
-		// Start with generating a list of all log groups
-		logGroups, err := describeLogGroups(cloudwatchLogGroupSvc)
-		if err != nil {
-			return nil, errors.Wrapf(err, "PollCloudWatchLogsLogGroups(%#v) in region %s", *pollerInput, *regionID)
+	regions := utils.GetServiceRegions(pollerInput.Regions, "logs")
+	resources, regionErrors := utils.PollRegions(regions, maxParallelLogGroupRegions,
+		func(region string) ([]*apimodels.AddResourceEntry, error) {
+			return pollCloudWatchLogsLogGroupsRegion(pollerLogger.With(zap.String("region", region)), pollerInput, region)
+		})
+	if len(regionErrors) > 0 {
+		if len(regionErrors) == len(regions) {
+			return nil, errors.Wrapf(regionErrors, "PollCloudWatchLogsLogGroups(%#v)", *pollerInput)
 		}
-		if len(logGroups) == 0 {
-			zap.L().Debug("no CloudWatchLogs LogGroups found", zap.String("region", *regionID))
+		pollerLogger.Error("failed to poll CloudWatchLogs LogGroups in some regions", zap.Error(regionErrors))
+	}
+	if len(invalidRegions) > 0 {
+		return resources, invalidRegions
+	}
+	return resources, nil
+}
+
+// pollCloudWatchLogsLogGroupsRegion gathers information on each CloudWatchLogs LogGroup in a single region
+func pollCloudWatchLogsLogGroupsRegion(
+	logger *zap.Logger, pollerInput *awsmodels.ResourcePollerInput, region string) ([]*apimodels.AddResourceEntry, error) {
+
+	cloudwatchLogGroupSvc, err := getCloudWatchLogsClient(pollerInput, region)
+	if err != nil {
+		return nil, err // error is logged in getSharedClient()
+	}
+
+	// Start with generating a list of all log groups
+	logGroups, err := describeLogGroups(logger, cloudwatchLogGroupSvc)
+	if err != nil {
+		return nil, err
+	}
+	if len(logGroups) == 0 {
+		logger.Debug("no CloudWatchLogs LogGroups found")
+		return nil, nil
+	}
+
+	logGroupSnapshots := make(map[string]*awsmodels.CloudWatchLogsLogGroup)
+	kmsClient := getLogGroupKMSClient(logger, pollerInput, region)
+	warnings := &logGroupPermissionWarnings{}
+	for _, logGroup := range logGroups {
+		logGroupSnapshot := buildCloudWatchLogsLogGroupSnapshot(
+			logger, cloudwatchLogGroupSvc, kmsClient, warnings, logGroup, pollerInput.ResolveIngestionTime)
+		if logGroupSnapshot == nil {
 			continue
 		}
+		logGroupSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+		logGroupSnapshot.Region = aws.String(region)
 
-		for _, logGroup := range logGroups {
-			logGroupSnapshot := buildCloudWatchLogsLogGroupSnapshot(cloudwatchLogGroupSvc, logGroup)
-			if logGroupSnapshot == nil {
-				continue
-			}
-			logGroupSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
-			logGroupSnapshot.Region = regionID
-
-			if _, ok := logGroupSnapshots[*logGroupSnapshot.ARN]; !ok {
-				logGroupSnapshots[*logGroup.Arn] = logGroupSnapshot
-			} else {
-				zap.L().Info(
-					"overwriting existing CloudWatchLogs LogGroup snapshot",
-					zap.String("resourceId", *logGroupSnapshot.ARN),
-				)
-				logGroupSnapshots[*logGroupSnapshot.ARN] = logGroupSnapshot
-			}
+		if _, ok := logGroupSnapshots[*logGroupSnapshot.ARN]; ok {
+			logger.Info(
+				"overwriting existing CloudWatchLogs LogGroup snapshot",
+				zap.String("resourceId", *logGroupSnapshot.ARN),
+			)
 		}
+		logGroupSnapshots[*logGroupSnapshot.ARN] = logGroupSnapshot
 	}
 
 	resources := make([]*apimodels.AddResourceEntry, 0, len(logGroupSnapshots))
@@ -214,6 +847,5 @@ func PollCloudWatchLogsLogGroups(pollerInput *awsmodels.ResourcePollerInput) ([]
 			Type:            awsmodels.CloudWatchLogGroupSchema,
 		})
 	}
-
 	return resources, nil
 }
@@ -19,19 +19,36 @@ package aws
  */
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
 func TestCloudWatchLogsLogGroupsDescribe(t *testing.T) {
 	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"DescribeLogGroupsPages"})
 
-	out, err := describeLogGroups(mockSvc)
+	out, err := describeLogGroups(zap.L(), mockSvc)
 	require.NoError(t, err)
 	assert.NotEmpty(t, out)
 }
@@ -39,7 +56,27 @@ func TestCloudWatchLogsLogGroupsDescribe(t *testing.T) {
 func TestCloudWatchLogsLogGroupsDescribeError(t *testing.T) {
 	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeLogGroupsPages"})
 
-	out, err := describeLogGroups(mockSvc)
+	out, err := describeLogGroups(zap.L(), mockSvc)
+	require.Error(t, err)
+	assert.Nil(t, out)
+}
+
+func TestCloudWatchLogsLogGroupsDescribeByPrefix(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("DescribeLogGroupsPages", &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String("LogGroup-"),
+	}).Return(nil)
+
+	out, err := describeLogGroupsByPrefix(zap.L(), mockSvc, "LogGroup-")
+	mockSvc.AssertExpectations(t)
+	require.NoError(t, err)
+	assert.Len(t, out, 2)
+}
+
+func TestCloudWatchLogsLogGroupsDescribeByPrefixError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeLogGroupsPages"})
+
+	out, err := describeLogGroupsByPrefix(zap.L(), mockSvc, "LogGroup-")
 	require.Error(t, err)
 	assert.Nil(t, out)
 }
@@ -47,28 +84,218 @@ func TestCloudWatchLogsLogGroupsDescribeError(t *testing.T) {
 func TestCloudWatchLogsLogGroupsListTags(t *testing.T) {
 	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"ListTagsLogGroup"})
 
-	out := listTagsLogGroup(mockSvc, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	out, vanished := listTagsLogGroup(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
 	assert.NotEmpty(t, out)
+	assert.False(t, vanished)
 }
 
 func TestCloudWatchLogsLogGroupsListTagsError(t *testing.T) {
 	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"ListTagsLogGroup"})
 
-	out := listTagsLogGroup(mockSvc, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	out, vanished := listTagsLogGroup(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	assert.Nil(t, out)
+	assert.False(t, vanished)
+}
+
+func TestCloudWatchLogsLogGroupsListTagsVanished(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("ListTagsLogGroup", mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{},
+		awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "The specified log group does not exist.", nil))
+
+	out, vanished := listTagsLogGroup(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
 	assert.Nil(t, out)
+	assert.True(t, vanished)
+}
+
+func TestCloudWatchLogsLogGroupsPollSkipsVanished(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"DescribeLogGroupsPages", "GetDataProtectionPolicy"})
+	// LogGroup-1 is deleted between listing the log groups and getting its tags
+	mockSvc.On("ListTagsLogGroup", &cloudwatchlogs.ListTagsLogGroupInput{LogGroupName: aws.String("LogGroup-1")}).
+		Return(&cloudwatchlogs.ListTagsLogGroupOutput{},
+			awserr.New(cloudwatchlogs.ErrCodeResourceNotFoundException, "The specified log group does not exist.", nil))
+	mockSvc.On("ListTagsLogGroup", &cloudwatchlogs.ListTagsLogGroupInput{LogGroupName: aws.String("LogGroup-2")}).
+		Return(awstest.ExampleListTagsLogGroup, nil)
+	awstest.MockCloudWatchLogsForSetup = mockSvc
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := pollCloudWatchLogsLogGroupsRegion(zap.L(), &awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	}, "us-west-2")
+
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "LogGroup-2", *resources[0].Attributes.(*awsmodels.CloudWatchLogsLogGroup).Name)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCloudWatchLogsLogGroupsPollListTagsAccessDenied(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"DescribeLogGroupsPages", "GetDataProtectionPolicy"})
+	mockSvc.On("ListTagsLogGroup", mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{},
+		awserr.New("AccessDeniedException", "not authorized to perform: logs:ListTagsLogGroup", nil))
+	awstest.MockCloudWatchLogsForSetup = mockSvc
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	core, logs := observer.New(zap.WarnLevel)
+	resources, err := pollCloudWatchLogsLogGroupsRegion(zap.New(core), &awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	}, "us-west-2")
+
+	// Every log group is scanned, without its tags, and the missing permission is reported once
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	for _, resource := range resources {
+		assert.Nil(t, resource.Attributes.(*awsmodels.CloudWatchLogsLogGroup).Tags)
+	}
+	mockSvc.AssertNumberOfCalls(t, "ListTagsLogGroup", 2)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "AccessDeniedException, log groups are scanned without their tags", logs.All()[0].Message)
+}
+
+func TestCloudWatchLogsLogGroupsGetDataProtectionPolicy(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvc([]string{"GetDataProtectionPolicy"})
+
+	enabled, name := getDataProtectionPolicy(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	require.NotNil(t, enabled)
+	assert.True(t, *enabled)
+	require.NotNil(t, name)
+	assert.Equal(t, "data-protection-policy", *name)
+}
+
+func TestCloudWatchLogsLogGroupsGetDataProtectionPolicyNotFound(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("GetDataProtectionPolicy", mock.Anything).
+		Return((*string)(nil), awstest.ExampleDataProtectionPolicyNotFound)
+
+	enabled, name := getDataProtectionPolicy(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	require.NotNil(t, enabled)
+	assert.False(t, *enabled)
+	assert.Nil(t, name)
+}
+
+func TestCloudWatchLogsLogGroupsGetDataProtectionPolicyError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"GetDataProtectionPolicy"})
+
+	enabled, name := getDataProtectionPolicy(zap.L(), mockSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0].LogGroupName)
+	assert.Nil(t, enabled)
+	assert.Nil(t, name)
+}
+
+func TestCloudWatchLogsLogGroupsGetDataProtectionPolicyThrottled(t *testing.T) {
+	previous := maxDataProtectionPolicyBackoff
+	maxDataProtectionPolicyBackoff = time.Millisecond
+	defer func() { maxDataProtectionPolicyBackoff = previous }()
+
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("GetDataProtectionPolicy", mock.Anything).
+		Return((*string)(nil), awserr.New("ThrottlingException", "Rate exceeded", nil))
+	core, logs := observer.New(zap.WarnLevel)
+	warnings := &logGroupPermissionWarnings{}
+
+	// Once the lookup stays throttled, the policy is unknown for the rest of the poll and not requested again
+	for _, logGroup := range awstest.ExampleDescribeLogGroups.LogGroups {
+		enabled, name := getDataProtectionPolicy(zap.New(core), mockSvc, warnings, logGroup.LogGroupName)
+		assert.Nil(t, enabled)
+		assert.Nil(t, name)
+	}
+	calls := len(mockSvc.Calls)
+	enabled, _ := getDataProtectionPolicy(zap.New(core), mockSvc, warnings, aws.String("LogGroup-3"))
+	assert.Nil(t, enabled)
+	assert.Len(t, mockSvc.Calls, calls)
+	assert.Equal(t, 1, logs.Len())
 }
 
 func TestBuildCloudWatchLogsLogGroupSnapshot(t *testing.T) {
 	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
 
 	certSnapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(),
 		mockSvc,
+		nil,
+		nil,
 		awstest.ExampleDescribeLogGroups.LogGroups[0],
+		false,
 	)
 
 	assert.NotNil(t, certSnapshot.ARN)
 	assert.NotNil(t, certSnapshot.StoredBytes)
 	assert.Equal(t, "LogGroup-1", *certSnapshot.Name)
+	assert.True(t, *certSnapshot.DataProtectionPolicyEnabled)
+	assert.Equal(t, "data-protection-policy", *certSnapshot.DataProtectionPolicyName)
+	assert.Equal(t, int64(30), *certSnapshot.RetentionInDays)
+	assert.True(t, *certSnapshot.RetentionConfigured)
+	assert.False(t, *certSnapshot.RetentionNeverExpires)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotNeverExpires(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(),
+		mockSvc,
+		nil,
+		nil,
+		awstest.ExampleDescribeLogGroups.LogGroups[1],
+		false,
+	)
+
+	assert.Equal(t, "LogGroup-2", *snapshot.Name)
+	assert.Nil(t, snapshot.RetentionInDays)
+	assert.False(t, *snapshot.RetentionConfigured)
+	assert.True(t, *snapshot.RetentionNeverExpires)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotIngestionTime(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(), mockSvc, nil, nil, awstest.ExampleDescribeLogGroups.LogGroups[0], true)
+
+	require.NotNil(t, snapshot)
+	mockSvc.AssertCalled(t, "DescribeLogStreams", &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName: aws.String("LogGroup-1"),
+		OrderBy:      aws.String(cloudwatchlogs.OrderByLastEventTime),
+		Descending:   aws.Bool(true),
+		Limit:        aws.Int64(1),
+	})
+	assert.Equal(t, utils.UnixTimeToDateTime(1593561605), snapshot.LastIngestionTime)
+	assert.False(t, *snapshot.NeverIngested)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotIngestionTimeDisabled(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(), mockSvc, nil, nil, awstest.ExampleDescribeLogGroups.LogGroups[0], false)
+
+	require.NotNil(t, snapshot)
+	mockSvc.AssertNotCalled(t, "DescribeLogStreams", mock.Anything)
+	assert.Nil(t, snapshot.LastIngestionTime)
+	assert.Nil(t, snapshot.NeverIngested)
+}
+
+func TestGetLastIngestionTimeNoStreams(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("DescribeLogStreams", mock.Anything).Return(&cloudwatchlogs.DescribeLogStreamsOutput{}, nil)
+
+	lastIngestion, neverIngested := getLastIngestionTime(zap.L(), mockSvc, aws.String("LogGroup-1"))
+	assert.Nil(t, lastIngestion)
+	assert.True(t, *neverIngested)
+}
+
+func TestGetLastIngestionTimeError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeLogStreams"})
+
+	lastIngestion, neverIngested := getLastIngestionTime(zap.L(), mockSvc, aws.String("LogGroup-1"))
+	assert.Nil(t, lastIngestion)
+	assert.Nil(t, neverIngested)
 }
 
 func TestCloudWatchLogsLogGroupPoller(t *testing.T) {
@@ -106,3 +333,564 @@ func TestCloudWatchLogsLogGroupPollerError(t *testing.T) {
 		assert.Nil(t, event.Attributes)
 	}
 }
+
+func TestCloudWatchLogsLogGroupPollerByPrefix(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAll()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsLogGroupsByPrefix(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	}, "us-west-2", "LogGroup-")
+
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	for _, resource := range resources {
+		snapshot := resource.Attributes.(*awsmodels.CloudWatchLogsLogGroup)
+		assert.Equal(t, "us-west-2", *snapshot.Region)
+		assert.Equal(t, string(resource.ID), *snapshot.ARN)
+	}
+}
+
+func TestCloudWatchLogsLogGroupPollerByPrefixError(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAllError()
+
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsLogGroupsByPrefix(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	}, "us-west-2", "LogGroup-")
+
+	require.Error(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestCloudWatchLogsLogGroupPollerInvalidRegion(t *testing.T) {
+	// No API calls are expected, the region is rejected before creating a client
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	awstest.MockCloudWatchLogsForSetup = mockSvc
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+	pollerInput := &awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	}
+
+	resourceARN := arn.ARN{
+		Partition: "aws",
+		Service:   "logs",
+		Region:    "uswest2",
+		AccountID: "123456789012",
+		Resource:  "log-group:LogGroup-1",
+	}
+	resource, err := PollCloudWatchLogsLogGroup(pollerInput, resourceARN, &pollermodels.ScanEntry{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported region "uswest2"`)
+	assert.Nil(t, resource)
+
+	resources, err := PollCloudWatchLogsLogGroupsByPrefix(pollerInput, "not a region", "LogGroup-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported region "not a region"`)
+	assert.Empty(t, resources)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCloudWatchLogsLogGroupPollerUnsupportedRegions(t *testing.T) {
+	// No API calls are expected, every region is rejected before polling
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	awstest.MockCloudWatchLogsForSetup = mockSvc
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsLogGroups(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             []*string{aws.String("uswest2"), aws.String("not a region")},
+		Timestamp:           &awstest.ExampleTime,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no supported region")
+	assert.Contains(t, err.Error(), `unsupported region "uswest2"`)
+	assert.Contains(t, err.Error(), `unsupported region "not a region"`)
+	assert.Empty(t, resources)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestCloudWatchLogsLogGroupPollerSomeUnsupportedRegions(t *testing.T) {
+	awstest.MockCloudWatchLogsForSetup = awstest.BuildMockCloudWatchLogsSvcAll()
+	CloudWatchLogsClientFunc = awstest.SetupMockCloudWatchLogs
+
+	resources, err := PollCloudWatchLogsLogGroups(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             []*string{aws.String("us-west-2"), aws.String("uswest2")},
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	// The log groups of the supported region are returned along with the unsupported region
+	require.Error(t, err)
+	regionErrors, ok := err.(utils.RegionErrors)
+	require.True(t, ok)
+	require.Len(t, regionErrors, 1)
+	assert.EqualError(t, regionErrors["uswest2"], `unsupported region "uswest2"`)
+	require.NotEmpty(t, resources)
+	for _, resource := range resources {
+		assert.Equal(t, "us-west-2", *resource.Attributes.(*awsmodels.CloudWatchLogsLogGroup).Region)
+	}
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotKMSKey(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+	mockKmsSvc := awstest.BuildMockKmsSvc([]string{"DescribeKey", "GetKeyRotationStatus"})
+	logGroup := *awstest.ExampleDescribeLogGroups.LogGroups[0]
+	logGroup.KmsKeyId = awstest.ExampleKeyId
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(zap.L(), mockSvc, mockKmsSvc, nil, &logGroup, false)
+
+	mockKmsSvc.AssertExpectations(t)
+	assert.True(t, *snapshot.KmsKeyRotationEnabled)
+	assert.Equal(t, "Enabled", *snapshot.KmsKeyState)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotKMSKeyError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+	mockKmsSvc := awstest.BuildMockKmsSvcError([]string{"DescribeKey"})
+	logGroup := *awstest.ExampleDescribeLogGroups.LogGroups[0]
+	logGroup.KmsKeyId = awstest.ExampleKeyId
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(zap.L(), mockSvc, mockKmsSvc, nil, &logGroup, false)
+
+	require.NotNil(t, snapshot)
+	assert.Nil(t, snapshot.KmsKeyRotationEnabled)
+	assert.Nil(t, snapshot.KmsKeyState)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotNoKMSKey(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+	mockKmsSvc := &awstest.MockKms{}
+
+	// log groups without a KMS key are never resolved
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(), mockSvc, mockKmsSvc, nil, awstest.ExampleDescribeLogGroups.LogGroups[0], false)
+
+	mockKmsSvc.AssertExpectations(t)
+	assert.Nil(t, snapshot.KmsKeyRotationEnabled)
+	assert.Nil(t, snapshot.KmsKeyState)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotSharedKMSKey(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+	mockKmsSvc := awstest.BuildMockKmsSvc([]string{"DescribeKey", "GetKeyRotationStatus"})
+	kmsClient := newCachedKMSClient(mockKmsSvc)
+
+	for i := 0; i < 10; i++ {
+		logGroup := *awstest.ExampleDescribeLogGroups.LogGroups[0]
+		logGroup.LogGroupName = aws.String(fmt.Sprintf("LogGroup-%d", i))
+		logGroup.KmsKeyId = awstest.ExampleKeyId
+
+		snapshot := buildCloudWatchLogsLogGroupSnapshot(zap.L(), mockSvc, kmsClient, nil, &logGroup, false)
+		assert.True(t, *snapshot.KmsKeyRotationEnabled)
+		assert.Equal(t, "Enabled", *snapshot.KmsKeyState)
+	}
+
+	// The key is described once for all of the log groups
+	mockKmsSvc.AssertNumberOfCalls(t, "DescribeKey", 1)
+	mockKmsSvc.AssertNumberOfCalls(t, "GetKeyRotationStatus", 1)
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotSharedKMSKeyError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+	mockKmsSvc := awstest.BuildMockKmsSvcError([]string{"DescribeKey"})
+	kmsClient := newCachedKMSClient(mockKmsSvc)
+
+	for i := 0; i < 10; i++ {
+		logGroup := *awstest.ExampleDescribeLogGroups.LogGroups[0]
+		logGroup.KmsKeyId = awstest.ExampleKeyId
+
+		snapshot := buildCloudWatchLogsLogGroupSnapshot(zap.L(), mockSvc, kmsClient, nil, &logGroup, false)
+		assert.Nil(t, snapshot.KmsKeyState)
+	}
+
+	// Keys which can't be described are not retried for every log group
+	mockKmsSvc.AssertNumberOfCalls(t, "DescribeKey", 1)
+}
+
+func TestGetLogGroupKMSClientDisabled(t *testing.T) {
+	assert.Nil(t, getLogGroupKMSClient(zap.L(), &awsmodels.ResourcePollerInput{}, "us-west-2"))
+}
+
+// pagedLogGroupsSvc serves log groups sorted by name, in pages, counting the pages which were requested
+type pagedLogGroupsSvc struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	names        []string
+	pagesFetched int
+}
+
+func (svc *pagedLogGroupsSvc) DescribeLogGroupsPages(
+	input *cloudwatchlogs.DescribeLogGroupsInput,
+	fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool,
+) error {
+
+	var matching []*cloudwatchlogs.LogGroup
+	for _, name := range svc.names {
+		if strings.HasPrefix(name, aws.StringValue(input.LogGroupNamePrefix)) {
+			matching = append(matching, &cloudwatchlogs.LogGroup{LogGroupName: aws.String(name)})
+		}
+	}
+	pageSize := int(aws.Int64Value(input.Limit))
+	if pageSize == 0 {
+		pageSize = 50 // the API default
+	}
+	for start := 0; start < len(matching); start += pageSize {
+		end := start + pageSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		svc.pagesFetched++
+		if !fn(&cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: matching[start:end]}, end == len(matching)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Log group names sharing the /aws/lambda/panther prefix, sorted like the API returns them
+func sharedPrefixLogGroupNames() []string {
+	names := []string{"/aws/lambda/panther"}
+	for i := 0; i < 500; i++ {
+		names = append(names, fmt.Sprintf("/aws/lambda/panther-%03d", i))
+	}
+	return names
+}
+
+func TestCloudWatchLogsGetLogGroupSharedPrefix(t *testing.T) {
+	svc := &pagedLogGroupsSvc{names: sharedPrefixLogGroupNames()}
+
+	logGroup := getLogGroup(zap.L(), svc, "/aws/lambda/panther")
+	require.NotNil(t, logGroup)
+	assert.Equal(t, "/aws/lambda/panther", *logGroup.LogGroupName)
+	assert.Equal(t, 1, svc.pagesFetched)
+
+	logGroup = getLogGroup(zap.L(), svc, "/aws/lambda/panther-250")
+	require.NotNil(t, logGroup)
+	assert.Equal(t, "/aws/lambda/panther-250", *logGroup.LogGroupName)
+	assert.Equal(t, 2, svc.pagesFetched)
+}
+
+func TestCloudWatchLogsGetLogGroupMissing(t *testing.T) {
+	svc := &pagedLogGroupsSvc{names: sharedPrefixLogGroupNames()[1:]}
+
+	// Many log groups start with the name, but none of them is an exact match
+	assert.Nil(t, getLogGroup(zap.L(), svc, "/aws/lambda/panther"))
+	assert.Equal(t, 1, svc.pagesFetched)
+}
+
+func TestCloudWatchLogsGetLogGroupError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeLogGroupsPages"})
+
+	assert.Nil(t, getLogGroup(zap.L(), mockSvc, "LogGroup-1"))
+}
+
+func TestCloudWatchLogsLogGroupsDescribeCapped(t *testing.T) {
+	previous := maxLogGroups
+	maxLogGroups = 120
+	defer func() { maxLogGroups = previous }()
+
+	svc := &pagedLogGroupsSvc{names: sharedPrefixLogGroupNames()}
+	core, logs := observer.New(zap.WarnLevel)
+	out, err := describeLogGroups(zap.New(core).With(zap.String("region", "us-west-2")), svc)
+	require.NoError(t, err)
+	require.Len(t, out, 120)
+	// The warning is logged with the fields of the poller logger
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "us-west-2", logs.All()[0].ContextMap()["region"])
+	assert.Equal(t, "/aws/lambda/panther", *out[0].LogGroupName)
+	// Paging stops at the page which reached the cap
+	assert.Equal(t, 3, svc.pagesFetched)
+}
+
+func TestCloudWatchLogsLogGroupsDescribeUnderCap(t *testing.T) {
+	svc := &pagedLogGroupsSvc{names: sharedPrefixLogGroupNames()}
+	out, err := describeLogGroups(zap.L(), svc)
+	require.NoError(t, err)
+	assert.Len(t, out, 501)
+	assert.Equal(t, 11, svc.pagesFetched)
+}
+
+// stuckTokenLogGroupsSvc returns the same NextToken on every page, like a misbehaving API
+type stuckTokenLogGroupsSvc struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	pagesFetched int
+}
+
+func (svc *stuckTokenLogGroupsSvc) DescribeLogGroupsPages(
+	_ *cloudwatchlogs.DescribeLogGroupsInput,
+	fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool,
+) error {
+
+	// Bounded so the test fails instead of hanging if the loop is not detected
+	for svc.pagesFetched < 100 {
+		svc.pagesFetched++
+		page := &cloudwatchlogs.DescribeLogGroupsOutput{
+			LogGroups: []*cloudwatchlogs.LogGroup{{LogGroupName: aws.String(fmt.Sprintf("group-%d", svc.pagesFetched))}},
+			NextToken: aws.String("stuck"),
+		}
+		if !fn(page, false) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestCloudWatchLogsLogGroupsDescribeStuckToken(t *testing.T) {
+	svc := &stuckTokenLogGroupsSvc{}
+	core, logs := observer.New(zap.WarnLevel)
+	out, err := describeLogGroups(zap.New(core), svc)
+	require.NoError(t, err)
+	assert.Equal(t, 1, logs.FilterMessage("DescribeLogGroups returned the same NextToken twice, stopping").Len())
+	assert.Equal(t, 2, svc.pagesFetched)
+	assert.Len(t, out, 2)
+}
+
+// throttledLogGroupsSvc serves log groups in pages which resume from the NextToken,
+// throttling the first throttledCalls requests for the default page size
+type throttledLogGroupsSvc struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	names          []string
+	throttledCalls int
+	limits         []int64
+}
+
+func (svc *throttledLogGroupsSvc) DescribeLogGroupsPages(
+	input *cloudwatchlogs.DescribeLogGroupsInput,
+	fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool,
+) error {
+
+	pageSize := aws.Int64Value(input.Limit)
+	if pageSize == 0 {
+		pageSize = 50 // the API default
+	}
+	svc.limits = append(svc.limits, pageSize)
+	if pageSize == 50 && svc.throttledCalls > 0 {
+		svc.throttledCalls--
+		return awserr.New("ThrottlingException", "Rate exceeded", nil)
+	}
+
+	start := 0
+	if input.NextToken != nil {
+		start, _ = strconv.Atoi(*input.NextToken)
+	}
+	for ; start < len(svc.names); start += int(pageSize) {
+		end := start + int(pageSize)
+		if end > len(svc.names) {
+			end = len(svc.names)
+		}
+		page := &cloudwatchlogs.DescribeLogGroupsOutput{}
+		for _, name := range svc.names[start:end] {
+			page.LogGroups = append(page.LogGroups, &cloudwatchlogs.LogGroup{LogGroupName: aws.String(name)})
+		}
+		if end < len(svc.names) {
+			page.NextToken = aws.String(strconv.Itoa(end))
+		}
+		if !fn(page, end == len(svc.names)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestCloudWatchLogsLogGroupsDescribeThrottled(t *testing.T) {
+	names := sharedPrefixLogGroupNames()[:150]
+	svc := &throttledLogGroupsSvc{names: names, throttledCalls: 1}
+
+	core, logs := observer.New(zap.WarnLevel)
+	out, err := describeLogGroups(zap.New(core).With(zap.String("region", "us-west-2")), svc)
+	require.NoError(t, err)
+	throttledLogs := logs.FilterMessage("DescribeLogGroups throttled, reducing the page size").All()
+	require.Len(t, throttledLogs, 1)
+	assert.Equal(t, "us-west-2", throttledLogs[0].ContextMap()["region"])
+	require.Len(t, out, 150)
+	for i, logGroup := range out {
+		assert.Equal(t, names[i], *logGroup.LogGroupName)
+	}
+	// The first page is throttled, three pages of 25 succeed and the page size is back to 50 for the rest
+	assert.Equal(t, []int64{50, 25, 50}, svc.limits)
+}
+
+func TestCloudWatchLogsLogGroupsDescribeAlwaysThrottled(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("DescribeLogGroupsPages", mock.Anything).Return(awserr.New("ThrottlingException", "Rate exceeded", nil))
+
+	out, err := describeLogGroups(zap.L(), mockSvc)
+	require.Error(t, err)
+	assert.Nil(t, out)
+	// One request for each page size: 50, 25, 12, 6, 3 and 1
+	mockSvc.AssertNumberOfCalls(t, "DescribeLogGroupsPages", 6)
+}
+
+func TestLogGroupPageSizer(t *testing.T) {
+	pageSizer := &logGroupPageSizer{size: maxDescribeLogGroupsPageSize}
+	assert.Nil(t, pageSizer.limit())
+	assert.False(t, pageSizer.succeeded())
+
+	require.True(t, pageSizer.throttled())
+	require.True(t, pageSizer.throttled())
+	assert.Equal(t, int64(12), aws.Int64Value(pageSizer.limit()))
+
+	// Recovers one step at a time, a throttled page starts over
+	assert.False(t, pageSizer.succeeded())
+	assert.False(t, pageSizer.succeeded())
+	require.True(t, pageSizer.throttled())
+	assert.Equal(t, int64(6), pageSizer.size)
+	for _, expected := range []int64{12, 24, 48, 50} {
+		assert.False(t, pageSizer.succeeded())
+		assert.False(t, pageSizer.succeeded())
+		assert.True(t, pageSizer.succeeded())
+		assert.Equal(t, expected, pageSizer.size)
+	}
+	assert.Nil(t, pageSizer.limit())
+}
+
+func TestGetMaxLogGroups(t *testing.T) {
+	defer os.Unsetenv("MAX_LOG_GROUPS")
+
+	require.NoError(t, os.Unsetenv("MAX_LOG_GROUPS"))
+	limit, err := getMaxLogGroups()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMaxLogGroups, limit)
+
+	require.NoError(t, os.Setenv("MAX_LOG_GROUPS", "250"))
+	limit, err = getMaxLogGroups()
+	assert.NoError(t, err)
+	assert.Equal(t, 250, limit)
+
+	require.NoError(t, os.Setenv("MAX_LOG_GROUPS", "-1"))
+	limit, err = getMaxLogGroups()
+	assert.Error(t, err)
+	assert.Equal(t, defaultMaxLogGroups, limit)
+
+	require.NoError(t, os.Setenv("MAX_LOG_GROUPS", "lots"))
+	limit, err = getMaxLogGroups()
+	assert.EqualError(t, err, `invalid MAX_LOG_GROUPS "lots", using the default of 10000`)
+	assert.Equal(t, defaultMaxLogGroups, limit)
+}
+
+func TestWarnInvalidMaxLogGroups(t *testing.T) {
+	previous := maxLogGroupsErr
+	defer func() { maxLogGroupsErr = previous }()
+
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core).With(zap.String("region", "us-west-2"))
+	maxLogGroupsErr = nil
+	warnInvalidMaxLogGroups(logger)
+	assert.Equal(t, 0, logs.Len())
+
+	maxLogGroupsErr = errors.New("invalid MAX_LOG_GROUPS")
+	warnInvalidMaxLogGroups(logger)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "us-west-2", logs.All()[0].ContextMap()["region"])
+}
+
+func TestBuildCloudWatchLogsLogGroupSnapshotSubscriptionFilters(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcAll()
+
+	snapshot := buildCloudWatchLogsLogGroupSnapshot(
+		zap.L(), mockSvc, nil, nil, awstest.ExampleDescribeLogGroups.LogGroups[0], true)
+
+	require.NotNil(t, snapshot)
+	mockSvc.AssertCalled(t, "DescribeSubscriptionFilters", &cloudwatchlogs.DescribeSubscriptionFiltersInput{
+		LogGroupName: aws.String("LogGroup-1"),
+	})
+	assert.Equal(t, int64(1), *snapshot.SubscriptionFilterCount)
+	// The example ingestion time is long ago, but the log group has a retention period and a subscription
+	assert.False(t, *snapshot.RecentlyIngested)
+	assert.False(t, *snapshot.Orphaned)
+}
+
+func TestGetSubscriptionFilterCountError(t *testing.T) {
+	mockSvc := awstest.BuildMockCloudWatchLogsSvcError([]string{"DescribeSubscriptionFilters"})
+
+	assert.Nil(t, getSubscriptionFilterCount(zap.L(), mockSvc, aws.String("LogGroup-1")))
+}
+
+func TestSetLogGroupUsage(t *testing.T) {
+	now := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	recent := strfmt.DateTime(now.Add(-24 * time.Hour))
+	old := strfmt.DateTime(now.Add(-90 * 24 * time.Hour))
+
+	// An orphaned log group, each test case changes one of its fields
+	orphan := func() *awsmodels.CloudWatchLogsLogGroup {
+		return &awsmodels.CloudWatchLogsLogGroup{
+			RetentionNeverExpires:   aws.Bool(true),
+			MetricFilterCount:       aws.Int64(0),
+			SubscriptionFilterCount: aws.Int64(0),
+			LastIngestionTime:       &old,
+			NeverIngested:           aws.Bool(false),
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		update           func(*awsmodels.CloudWatchLogsLogGroup)
+		recentlyIngested *bool
+		orphaned         *bool
+	}{
+		{"orphaned", func(*awsmodels.CloudWatchLogsLogGroup) {}, aws.Bool(false), aws.Bool(true)},
+		{"never ingested", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.LastIngestionTime, s.NeverIngested = nil, aws.Bool(true)
+		}, aws.Bool(false), aws.Bool(true)},
+		{"recently ingested", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.LastIngestionTime = &recent
+		}, aws.Bool(true), aws.Bool(false)},
+		{"retention", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.RetentionNeverExpires = aws.Bool(false)
+		}, aws.Bool(false), aws.Bool(false)},
+		{"metric filter", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.MetricFilterCount = aws.Int64(1)
+		}, aws.Bool(false), aws.Bool(false)},
+		{"subscription filter", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.SubscriptionFilterCount = aws.Int64(1)
+		}, aws.Bool(false), aws.Bool(false)},
+		{"unknown subscriptions", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.SubscriptionFilterCount = nil
+		}, aws.Bool(false), nil},
+		{"unknown ingestion", func(s *awsmodels.CloudWatchLogsLogGroup) {
+			s.LastIngestionTime, s.NeverIngested = nil, nil
+		}, nil, nil},
+	}
+	for _, tc := range testCases {
+		snapshot := orphan()
+		tc.update(snapshot)
+		setLogGroupUsage(snapshot, now)
+		assert.Equal(t, tc.recentlyIngested, snapshot.RecentlyIngested, tc.name)
+		assert.Equal(t, tc.orphaned, snapshot.Orphaned, tc.name)
+	}
+}
+
+func TestCloudWatchLogsLogGroupsDescribeRequestID(t *testing.T) {
+	mockSvc := &awstest.MockCloudWatchLogs{}
+	mockSvc.On("DescribeLogGroupsPages", mock.Anything).Return(awserr.NewRequestFailure(
+		awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "request-id"))
+
+	out, err := describeLogGroups(zap.L(), mockSvc)
+	require.Error(t, err)
+	assert.Nil(t, out)
+
+	var requestErr *utils.AWSRequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, "request-id", requestErr.RequestID)
+	assert.Equal(t, 400, requestErr.StatusCode)
+	assert.Equal(t, "ThrottlingException", requestErr.Code)
+	assert.Contains(t, err.Error(), "CloudWatchLogs.DescribeLogGroups: ThrottlingException: Rate exceeded")
+}
@@ -38,6 +38,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.DynamoDBTableSchema, PollDynamoDBTable)
+}
+
 const dynamoDBServiceNameSpace = "dynamodb"
 
 // Set as variables to be overridden in testing
@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2AmiSchema, PollEC2Image)
+}
+
 // PollEC2Image polls a single EC2 Image resource
 func PollEC2Image(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2InstanceSchema, PollEC2Instance)
+}
+
 var (
 	ec2Amis map[string][]*string
 )
@@ -34,6 +34,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2NetworkAclSchema, PollEC2NetworkACL)
+}
+
 // PollEC2NetworkACL polls a single EC2 Network ACL resource
 func PollEC2NetworkACL(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
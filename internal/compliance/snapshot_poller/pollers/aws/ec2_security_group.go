@@ -34,6 +34,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2SecurityGroupSchema, PollEC2SecurityGroup)
+}
+
 // PollEC2SecurityGroup polls a single EC2 Security Group resource
 func PollEC2SecurityGroup(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
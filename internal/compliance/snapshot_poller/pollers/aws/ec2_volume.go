@@ -34,6 +34,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2VolumeSchema, PollEC2Volume)
+}
+
 // PollEC2Volume polls a single EC2 Volume resource
 func PollEC2Volume(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
@@ -36,6 +36,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Ec2VpcSchema, PollEC2VPC)
+}
+
 var EC2ClientFunc = setupEC2Client
 
 func setupEC2Client(sess *session.Session, cfg *aws.Config) interface{} {
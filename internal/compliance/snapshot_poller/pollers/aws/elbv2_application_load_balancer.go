@@ -36,6 +36,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.Elbv2LoadBalancerSchema, PollELBV2LoadBalancer)
+}
+
 // Set as variables to be overridden in testing
 var (
 	Elbv2ClientFunc = setupElbv2Client
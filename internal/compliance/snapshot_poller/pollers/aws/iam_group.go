@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.IAMGroupSchema, PollIAMGroup)
+}
+
 // PollIAMGroup polls a single IAM Group resource
 func PollIAMGroup(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
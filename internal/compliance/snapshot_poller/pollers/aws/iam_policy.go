@@ -34,6 +34,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.IAMPolicySchema, PollIAMPolicy)
+}
+
 const (
 	localPolicyScope = "Local"
 )
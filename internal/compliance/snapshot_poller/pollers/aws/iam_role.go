@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.IAMRoleSchema, PollIAMRole)
+}
+
 // PollIAMRole polls a single IAM Role resource
 func PollIAMRole(
 	pollerResourceInput *awsmodels.ResourcePollerInput,
@@ -41,6 +41,11 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.IAMUserSchema, PollIAMUser)
+	RegisterSingleResourcePoller(awsmodels.IAMRootUserSchema, PollIAMRootUser)
+}
+
 const (
 	// Time to delay the requeue of a scan of IAM Users when the credential report times out
 	credentialReportRequeueDelaySeconds = 90
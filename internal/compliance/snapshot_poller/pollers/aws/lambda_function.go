@@ -33,6 +33,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.LambdaFunctionSchema, PollLambdaFunction)
+}
+
 // Set as variables to be overridden in testing
 var (
 	LambdaClientFunc = setupLambdaClient
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/pkg/errors"
@@ -45,37 +46,6 @@ var (
 
 	auditRoleName = os.Getenv("AUDIT_ROLE_NAME")
 
-	// IndividualARNResourcePollers maps resource types to their corresponding individual polling
-	// functions for resources whose ID is their ARN.
-	IndividualARNResourcePollers = map[string]func(
-		input *awsmodels.ResourcePollerInput, arn arn.ARN, entry *pollermodels.ScanEntry) (interface{}, error){
-		awsmodels.AcmCertificateSchema:      PollACMCertificate,
-		awsmodels.CloudFormationStackSchema: PollCloudFormationStack,
-		awsmodels.CloudTrailSchema:          PollCloudTrailTrail,
-		awsmodels.CloudWatchLogGroupSchema:  PollCloudWatchLogsLogGroup,
-		awsmodels.DynamoDBTableSchema:       PollDynamoDBTable,
-		awsmodels.Ec2AmiSchema:              PollEC2Image,
-		awsmodels.Ec2InstanceSchema:         PollEC2Instance,
-		awsmodels.Ec2NetworkAclSchema:       PollEC2NetworkACL,
-		awsmodels.Ec2SecurityGroupSchema:    PollEC2SecurityGroup,
-		awsmodels.Ec2VolumeSchema:           PollEC2Volume,
-		awsmodels.Ec2VpcSchema:              PollEC2VPC,
-		awsmodels.EcsClusterSchema:          PollECSCluster,
-		awsmodels.Elbv2LoadBalancerSchema:   PollELBV2LoadBalancer,
-		awsmodels.IAMGroupSchema:            PollIAMGroup,
-		awsmodels.IAMPolicySchema:           PollIAMPolicy,
-		awsmodels.IAMRoleSchema:             PollIAMRole,
-		awsmodels.IAMUserSchema:             PollIAMUser,
-		awsmodels.IAMRootUserSchema:         PollIAMRootUser,
-		awsmodels.KmsKeySchema:              PollKMSKey,
-		awsmodels.LambdaFunctionSchema:      PollLambdaFunction,
-		awsmodels.RDSInstanceSchema:         PollRDSInstance,
-		awsmodels.RedshiftClusterSchema:     PollRedshiftCluster,
-		awsmodels.S3BucketSchema:            PollS3Bucket,
-		awsmodels.WafWebAclSchema:           PollWAFWebACL,
-		awsmodels.WafRegionalWebAclSchema:   PollWAFRegionalWebACL,
-	}
-
 	// IndividualResourcePollers maps resource types to their corresponding individual polling
 	// functions for resources whose ID is not their ARN.
 	IndividualResourcePollers = map[string]func(
@@ -87,26 +57,27 @@ var (
 
 	// ServicePollers maps a resource type to its Poll function
 	ServicePollers = map[string]resourcePoller{
-		awsmodels.AcmCertificateSchema:      {"ACMCertificate", PollAcmCertificates},
-		awsmodels.CloudTrailSchema:          {"CloudTrail", PollCloudTrails},
-		awsmodels.Ec2AmiSchema:              {"EC2AMI", PollEc2Amis},
-		awsmodels.Ec2InstanceSchema:         {"EC2Instance", PollEc2Instances},
-		awsmodels.Ec2NetworkAclSchema:       {"EC2NetworkACL", PollEc2NetworkAcls},
-		awsmodels.Ec2SecurityGroupSchema:    {"EC2SecurityGroup", PollEc2SecurityGroups},
-		awsmodels.Ec2VolumeSchema:           {"EC2Volume", PollEc2Volumes},
-		awsmodels.Ec2VpcSchema:              {"EC2VPC", PollEc2Vpcs},
-		awsmodels.EcsClusterSchema:          {"ECSCluster", PollEcsClusters},
-		awsmodels.Elbv2LoadBalancerSchema:   {"ELBV2LoadBalancer", PollElbv2ApplicationLoadBalancers},
-		awsmodels.KmsKeySchema:              {"KMSKey", PollKmsKeys},
-		awsmodels.S3BucketSchema:            {"S3Bucket", PollS3Buckets},
-		awsmodels.WafWebAclSchema:           {"WAFWebAcl", PollWafWebAcls},
-		awsmodels.WafRegionalWebAclSchema:   {"WAFRegionalWebAcl", PollWafRegionalWebAcls},
-		awsmodels.CloudFormationStackSchema: {"CloudFormationStack", PollCloudFormationStacks},
-		awsmodels.CloudWatchLogGroupSchema:  {"CloudWatchLogGroup", PollCloudWatchLogsLogGroups},
-		awsmodels.ConfigServiceSchema:       {"ConfigService", PollConfigServices},
-		awsmodels.DynamoDBTableSchema:       {"DynamoDBTable", PollDynamoDBTables},
-		awsmodels.GuardDutySchema:           {"GuardDutyDetector", PollGuardDutyDetectors},
-		awsmodels.IAMUserSchema:             {"IAMUser", PollIAMUsers},
+		awsmodels.AcmCertificateSchema:            {"ACMCertificate", PollAcmCertificates},
+		awsmodels.CloudTrailSchema:                {"CloudTrail", PollCloudTrails},
+		awsmodels.Ec2AmiSchema:                    {"EC2AMI", PollEc2Amis},
+		awsmodels.Ec2InstanceSchema:               {"EC2Instance", PollEc2Instances},
+		awsmodels.Ec2NetworkAclSchema:             {"EC2NetworkACL", PollEc2NetworkAcls},
+		awsmodels.Ec2SecurityGroupSchema:          {"EC2SecurityGroup", PollEc2SecurityGroups},
+		awsmodels.Ec2VolumeSchema:                 {"EC2Volume", PollEc2Volumes},
+		awsmodels.Ec2VpcSchema:                    {"EC2VPC", PollEc2Vpcs},
+		awsmodels.EcsClusterSchema:                {"ECSCluster", PollEcsClusters},
+		awsmodels.Elbv2LoadBalancerSchema:         {"ELBV2LoadBalancer", PollElbv2ApplicationLoadBalancers},
+		awsmodels.KmsKeySchema:                    {"KMSKey", PollKmsKeys},
+		awsmodels.S3BucketSchema:                  {"S3Bucket", PollS3Buckets},
+		awsmodels.WafWebAclSchema:                 {"WAFWebAcl", PollWafWebAcls},
+		awsmodels.WafRegionalWebAclSchema:         {"WAFRegionalWebAcl", PollWafRegionalWebAcls},
+		awsmodels.CloudFormationStackSchema:       {"CloudFormationStack", PollCloudFormationStacks},
+		awsmodels.CloudWatchLogGroupSchema:        {"CloudWatchLogGroup", PollCloudWatchLogsLogGroups},
+		awsmodels.CloudWatchLogsDestinationSchema: {"CloudWatchLogsDestination", PollCloudWatchLogsDestinations},
+		awsmodels.ConfigServiceSchema:             {"ConfigService", PollConfigServices},
+		awsmodels.DynamoDBTableSchema:             {"DynamoDBTable", PollDynamoDBTables},
+		awsmodels.GuardDutySchema:                 {"GuardDutyDetector", PollGuardDutyDetectors},
+		awsmodels.IAMUserSchema:                   {"IAMUser", PollIAMUsers},
 		// Service scan for the resource type IAMRootUserSchema is not defined! Do not do it!
 		awsmodels.IAMRoleSchema:         {"IAMRoles", PollIAMRoles},
 		awsmodels.IAMGroupSchema:        {"IAMGroups", PollIamGroups},
@@ -149,7 +120,9 @@ func Poll(scanRequest *pollermodels.ScanEntry) (
 		// This will be overwritten if this is not a single resource or single region service scan
 		Regions: []*string{scanRequest.Region},
 		// Note: The resources-api expects a strfmt.DateTime formatted string.
-		Timestamp: utils.DateTimeFormat(utils.TimeNowFunc()),
+		Timestamp:            utils.DateTimeFormat(utils.TimeNowFunc()),
+		ResolveKMSKeys:       aws.BoolValue(scanRequest.ResolveKMSKeys),
+		ResolveIngestionTime: aws.BoolValue(scanRequest.ResolveIngestionTime),
 	}
 
 	// If this is an individual resource scan or the region is provided,
@@ -212,21 +185,38 @@ func Poll(scanRequest *pollermodels.ScanEntry) (
 	return nil, nil
 }
 
+// serviceScan runs each poller and returns all the resources they generated.
+//
+// A poller which returns utils.RegionErrors along with its resources (e.g. for unsupported regions) only
+// failed in those regions: its resources are kept, and the region errors are returned with the resources
+// once every poller ran. Any other error stops the scan and no resources are returned.
 func serviceScan(
 	pollers []resourcePoller,
 	pollerInput *awsmodels.ResourcePollerInput,
-) (generatedEvents []*resourcesapimodels.AddResourceEntry, err error) {
+) ([]*resourcesapimodels.AddResourceEntry, error) {
 
-	var generatedResources []*resourcesapimodels.AddResourceEntry
+	var (
+		generatedEvents []*resourcesapimodels.AddResourceEntry
+		regionErrors    utils.RegionErrors
+	)
 	for _, resourcePoller := range pollers {
-		generatedResources, err = resourcePoller.resourcePoller(pollerInput)
-		if err != nil {
+		generatedResources, pollErr := resourcePoller.resourcePoller(pollerInput)
+		if partialErrors, ok := pollErr.(utils.RegionErrors); ok {
+			if regionErrors == nil {
+				regionErrors = make(utils.RegionErrors)
+			}
+			for region, regionErr := range partialErrors {
+				regionErrors[region] = regionErr
+			}
+			pollErr = nil
+		}
+		if pollErr != nil {
 			zap.L().Error(
 				"an error occurred while polling",
 				zap.String("resourcePoller", resourcePoller.description),
-				zap.String("errorMessage", err.Error()),
+				zap.String("errorMessage", pollErr.Error()),
 			)
-			return
+			return nil, pollErr
 		} else if generatedResources != nil {
 			zap.L().Info(
 				"resources generated",
@@ -236,7 +226,10 @@ func serviceScan(
 			generatedEvents = append(generatedEvents, generatedResources...)
 		}
 	}
-	return
+	if len(regionErrors) > 0 {
+		return generatedEvents, regionErrors
+	}
+	return generatedEvents, nil
 }
 
 func singleResourceScan(
@@ -254,7 +247,7 @@ func singleResourceScan(
 		if err != nil {
 			return nil, errors.Wrapf(err, "could not scan %#v", *scanRequest)
 		}
-	} else if pollFunction, ok := IndividualARNResourcePollers[*scanRequest.ResourceType]; ok {
+	} else {
 		// Handle cases where the ResourceID is an ARN
 		resourceARN, err := arn.Parse(*scanRequest.ResourceID)
 		if err != nil {
@@ -263,7 +256,7 @@ func singleResourceScan(
 			)
 			return nil, err
 		}
-		resource, err = pollFunction(pollerInput, resourceARN, scanRequest)
+		resource, err = PollResource(*scanRequest.ResourceType, resourceARN, pollerInput, scanRequest)
 		if err != nil {
 			return nil, errors.Wrapf(err, "could not scan %#v", *scanRequest)
 		}
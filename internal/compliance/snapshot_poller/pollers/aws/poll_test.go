@@ -19,12 +19,52 @@ package aws
  */
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	resourcesapimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
 // Unit tests
 func TestAssumeRoleMissingParams(t *testing.T) {
 	assert.Panics(t, func() { _ = assumeRole(nil, nil, "") })
 }
+
+func TestServiceScanRegionErrors(t *testing.T) {
+	regionErrors := utils.RegionErrors{"uswest2": errors.New(`unsupported region "uswest2"`)}
+	pollers := []resourcePoller{
+		{"partial", func(*awsmodels.ResourcePollerInput) ([]*resourcesapimodels.AddResourceEntry, error) {
+			return []*resourcesapimodels.AddResourceEntry{{ID: "resource-1"}}, regionErrors
+		}},
+		{"ok", func(*awsmodels.ResourcePollerInput) ([]*resourcesapimodels.AddResourceEntry, error) {
+			return []*resourcesapimodels.AddResourceEntry{{ID: "resource-2"}}, nil
+		}},
+	}
+
+	// The resources are kept and the region errors are reported once every poller ran
+	resources, err := serviceScan(pollers, &awsmodels.ResourcePollerInput{})
+	assert.Equal(t, regionErrors, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, resourcesapimodels.ResourceID("resource-1"), resources[0].ID)
+	assert.Equal(t, resourcesapimodels.ResourceID("resource-2"), resources[1].ID)
+}
+
+func TestServiceScanError(t *testing.T) {
+	pollers := []resourcePoller{
+		{"ok", func(*awsmodels.ResourcePollerInput) ([]*resourcesapimodels.AddResourceEntry, error) {
+			return []*resourcesapimodels.AddResourceEntry{{ID: "resource-1"}}, nil
+		}},
+		{"failed", func(*awsmodels.ResourcePollerInput) ([]*resourcesapimodels.AddResourceEntry, error) {
+			return nil, errors.New("access denied")
+		}},
+	}
+
+	resources, err := serviceScan(pollers, &awsmodels.ResourcePollerInput{})
+	assert.EqualError(t, err, "access denied")
+	assert.Nil(t, resources)
+}
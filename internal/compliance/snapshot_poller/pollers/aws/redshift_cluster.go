@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.RedshiftClusterSchema, PollRedshiftCluster)
+}
+
 // Set as variables to be overridden in testing
 var (
 	RedshiftClientFunc = setupRedshiftClient
@@ -0,0 +1,64 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/pkg/errors"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+)
+
+// SingleResourcePoller polls an individual resource whose ID is its ARN
+type SingleResourcePoller func(
+	input *awsmodels.ResourcePollerInput, arn arn.ARN, entry *pollermodels.ScanEntry) (interface{}, error)
+
+// singleResourcePollers maps resource types to their SingleResourcePoller.
+//
+// Each poller registers itself from an init function in its own file.
+var singleResourcePollers = make(map[string]SingleResourcePoller)
+
+// RegisterSingleResourcePoller makes the individual poller for a resource type available to PollResource.
+//
+// It panics if a poller is already registered for the resource type, since that is a programming error.
+func RegisterSingleResourcePoller(resourceType string, poller SingleResourcePoller) {
+	if poller == nil {
+		panic("nil single resource poller for resource type " + resourceType)
+	}
+	if _, exists := singleResourcePollers[resourceType]; exists {
+		panic("single resource poller already registered for resource type " + resourceType)
+	}
+	singleResourcePollers[resourceType] = poller
+}
+
+// PollResource polls an individual resource with the poller registered for its resource type
+func PollResource(
+	resourceType string,
+	resourceARN arn.ARN,
+	input *awsmodels.ResourcePollerInput,
+	entry *pollermodels.ScanEntry,
+) (interface{}, error) {
+
+	poller, ok := singleResourcePollers[resourceType]
+	if !ok {
+		return nil, errors.Errorf("no single resource poller registered for resource type '%s'", resourceType)
+	}
+	return poller(input, resourceARN, entry)
+}
@@ -0,0 +1,109 @@
+package aws
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+)
+
+const fakeResourceType = "AWS.Fake.Resource"
+
+func TestPollResource(t *testing.T) {
+	defer delete(singleResourcePollers, fakeResourceType)
+	resourceARN := arn.ARN{Partition: "aws", Service: "fake", Region: "us-west-2", Resource: "resource/1"}
+
+	var polledARN arn.ARN
+	RegisterSingleResourcePoller(fakeResourceType,
+		func(input *awsmodels.ResourcePollerInput, resourceARN arn.ARN, entry *pollermodels.ScanEntry) (interface{}, error) {
+			polledARN = resourceARN
+			return "fake resource", nil
+		})
+
+	resource, err := PollResource(fakeResourceType, resourceARN, &awsmodels.ResourcePollerInput{}, &pollermodels.ScanEntry{})
+	require.NoError(t, err)
+	assert.Equal(t, "fake resource", resource)
+	assert.Equal(t, resourceARN, polledARN)
+}
+
+func TestPollResourceError(t *testing.T) {
+	defer delete(singleResourcePollers, fakeResourceType)
+	RegisterSingleResourcePoller(fakeResourceType,
+		func(*awsmodels.ResourcePollerInput, arn.ARN, *pollermodels.ScanEntry) (interface{}, error) {
+			return nil, errors.New("poll failed")
+		})
+
+	resource, err := PollResource(fakeResourceType, arn.ARN{}, &awsmodels.ResourcePollerInput{}, &pollermodels.ScanEntry{})
+	assert.EqualError(t, err, "poll failed")
+	assert.Nil(t, resource)
+}
+
+func TestPollResourceUnregistered(t *testing.T) {
+	resource, err := PollResource(fakeResourceType, arn.ARN{}, &awsmodels.ResourcePollerInput{}, &pollermodels.ScanEntry{})
+	assert.EqualError(t, err, "no single resource poller registered for resource type 'AWS.Fake.Resource'")
+	assert.Nil(t, resource)
+}
+
+func TestRegisterSingleResourcePollerDuplicate(t *testing.T) {
+	assert.Panics(t, func() { RegisterSingleResourcePoller(awsmodels.S3BucketSchema, PollS3Bucket) })
+}
+
+func TestRegisterSingleResourcePollerNil(t *testing.T) {
+	assert.Panics(t, func() { RegisterSingleResourcePoller(fakeResourceType, nil) })
+}
+
+func TestSingleResourcePollersRegistered(t *testing.T) {
+	for _, resourceType := range []string{
+		awsmodels.AcmCertificateSchema,
+		awsmodels.CloudFormationStackSchema,
+		awsmodels.CloudTrailSchema,
+		awsmodels.CloudWatchLogGroupSchema,
+		awsmodels.CloudWatchLogsDestinationSchema,
+		awsmodels.DynamoDBTableSchema,
+		awsmodels.Ec2AmiSchema,
+		awsmodels.Ec2InstanceSchema,
+		awsmodels.Ec2NetworkAclSchema,
+		awsmodels.Ec2SecurityGroupSchema,
+		awsmodels.Ec2VolumeSchema,
+		awsmodels.Ec2VpcSchema,
+		awsmodels.EcsClusterSchema,
+		awsmodels.Elbv2LoadBalancerSchema,
+		awsmodels.IAMGroupSchema,
+		awsmodels.IAMPolicySchema,
+		awsmodels.IAMRoleSchema,
+		awsmodels.IAMUserSchema,
+		awsmodels.IAMRootUserSchema,
+		awsmodels.KmsKeySchema,
+		awsmodels.LambdaFunctionSchema,
+		awsmodels.RDSInstanceSchema,
+		awsmodels.RedshiftClusterSchema,
+		awsmodels.S3BucketSchema,
+		awsmodels.WafWebAclSchema,
+		awsmodels.WafRegionalWebAclSchema,
+	} {
+		assert.Contains(t, singleResourcePollers, resourceType)
+	}
+}
@@ -35,6 +35,10 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.S3BucketSchema, PollS3Bucket)
+}
+
 var (
 	// S3BucketSnapshots is a mapping between bucket name and its snapshot.
 	S3BucketSnapshots map[string]*awsmodels.S3Bucket
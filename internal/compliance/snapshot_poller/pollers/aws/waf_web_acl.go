@@ -37,6 +37,11 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
 )
 
+func init() {
+	RegisterSingleResourcePoller(awsmodels.WafWebAclSchema, PollWAFWebACL)
+	RegisterSingleResourcePoller(awsmodels.WafRegionalWebAclSchema, PollWAFRegionalWebACL)
+}
+
 // Set as variables to be overridden in testing
 var (
 	// Functions to initialize the WAF and WAF Regional client functions
@@ -116,36 +121,33 @@ func PollWAFRegionalWebACL(
 	return snapshot, nil
 }
 
-// listWebAclsRecursive is a helper function for listWebAcls, used to enumerate the web ACLs in an account
-func listWebAclsRecursive(wafSvc wafiface.WAFAPI, webAclsSummaryIn []*waf.WebACLSummary, next *string) (
-	webAclsSummaryOut []*waf.WebACLSummary) {
-
-	webAclsOutput, err := wafSvc.ListWebACLs(&waf.ListWebACLsInput{NextMarker: next})
+// listWebAcls returns a list web ACLs in the account
+//
+// The AWS go SDK's do not appear to have built in functions to handle pagination for this API call,
+// so it is being done here explicitly.
+func listWebAcls(wafSvc wafiface.WAFAPI) (webAclsSummaryOut []*waf.WebACLSummary) {
+	err := utils.Paginate(func(marker *string) (*string, error) {
+		webAclsOutput, err := wafSvc.ListWebACLs(&waf.ListWebACLsInput{NextMarker: marker})
+		if err != nil {
+			return nil, err
+		}
+		// there is no way to know if a particular response is the last response without
+		// requesting the next response and seeing that you get nothing
+		if len(webAclsOutput.WebACLs) == 0 {
+			return nil, nil
+		}
+		webAclsSummaryOut = append(webAclsSummaryOut, webAclsOutput.WebACLs...)
+		return webAclsOutput.NextMarker, nil
+	})
 	if err != nil {
 		if _, ok := wafSvc.(wafregionaliface.WAFRegionalAPI); ok {
 			utils.LogAWSError("WAF.Regional.ListWebAcls", err)
 		} else {
 			utils.LogAWSError("WAF.ListWebAcls", err)
 		}
-		return
-	}
-
-	// base case, there is no way to know if a particular response is the last response without
-	// requesting the next response and seeing that you get nothing
-	if len(webAclsOutput.WebACLs) == 0 {
-		return webAclsSummaryIn
+		return nil
 	}
-	webAclsSummaryOut = append(webAclsSummaryIn, webAclsOutput.WebACLs...)
-	return listWebAclsRecursive(wafSvc, webAclsSummaryOut, webAclsOutput.NextMarker)
-}
-
-// listWebAcls returns a list web ACLs in the account
-//
-// The AWS go SDK's do not appear to have built in functions to handle pagination for this API call,
-// so it is being done here explicitly.
-func listWebAcls(wafSvc wafiface.WAFAPI) (webAclsSummaryOut []*waf.WebACLSummary) {
-	var emptyWebAcls []*waf.WebACLSummary
-	return listWebAclsRecursive(wafSvc, emptyWebAcls, nil)
+	return webAclsSummaryOut
 }
 
 // getWebACL gets detailed information about a given WEB acl
@@ -88,7 +88,11 @@ func Handle(ctx context.Context, event events.SQSEvent) (err error) {
 			resources, pollErr := pollers.Poll(entry)
 			if pollErr != nil {
 				operation.LogError(errors.Wrap(pollErr, "poll failed"), zap.Any("sqsEntry", entry))
-				continue
+				// Resources are only returned with an error if some regions could not be polled,
+				// the resources of the other regions are still saved
+				if resources == nil {
+					continue
+				}
 			}
 
 			// Send data to the Resources API
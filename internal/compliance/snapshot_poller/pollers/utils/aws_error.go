@@ -0,0 +1,68 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+)
+
+// AWSRequestError is a failed AWS API call, with the request ID needed to find it in CloudTrail or open a support case.
+type AWSRequestError struct {
+	APICall    string
+	Code       string
+	StatusCode int
+	RequestID  string
+	Err        error
+}
+
+// Error has the same message as errors.Wrap(err, apiCall)
+func (e *AWSRequestError) Error() string {
+	return e.APICall + ": " + e.Err.Error()
+}
+
+// Cause returns the original error, see errors.Cause
+func (e *AWSRequestError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the original error, see errors.As
+func (e *AWSRequestError) Unwrap() error {
+	return e.Err
+}
+
+// WrapAWSError annotates the error of an AWS API call.
+//
+// If AWS received the request, the request ID and HTTP status are kept in an AWSRequestError.
+// Other errors are wrapped with errors.Wrap.
+func WrapAWSError(apiCall string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if requestErr, ok := err.(awserr.RequestFailure); ok {
+		return &AWSRequestError{
+			APICall:    apiCall,
+			Code:       requestErr.Code(),
+			StatusCode: requestErr.StatusCode(),
+			RequestID:  requestErr.RequestID(),
+			Err:        err,
+		}
+	}
+	return errors.Wrap(err, apiCall)
+}
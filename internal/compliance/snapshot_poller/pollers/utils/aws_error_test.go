@@ -0,0 +1,51 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapAWSError(t *testing.T) {
+	assert.NoError(t, WrapAWSError("CloudWatchLogs.DescribeLogGroups", nil))
+
+	cause := awserr.NewRequestFailure(awserr.New("ThrottlingException", "Rate exceeded", nil), 400, "request-id")
+	err := WrapAWSError("CloudWatchLogs.DescribeLogGroups", cause)
+	var requestErr *AWSRequestError
+	require.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, &AWSRequestError{
+		APICall:    "CloudWatchLogs.DescribeLogGroups",
+		Code:       "ThrottlingException",
+		StatusCode: 400,
+		RequestID:  "request-id",
+		Err:        cause,
+	}, requestErr)
+	assert.Equal(t, errors.Wrap(cause, "CloudWatchLogs.DescribeLogGroups").Error(), err.Error())
+	assert.Equal(t, cause, errors.Cause(err))
+
+	// Errors without a response from AWS have no request ID
+	err = WrapAWSError("CloudWatchLogs.DescribeLogGroups", errors.New("connection reset"))
+	assert.False(t, errors.As(err, &requestErr))
+	assert.EqualError(t, err, "CloudWatchLogs.DescribeLogGroups: connection reset")
+}
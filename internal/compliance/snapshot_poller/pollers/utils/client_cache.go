@@ -0,0 +1,97 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sync"
+	"time"
+)
+
+// Clients are evicted this long before their credentials expire, so they are never used with credentials
+// which may expire in the middle of a poll.
+const clientExpiryWindow = time.Minute
+
+// ClientCacheKey identifies the client of a service in a region, acting as an assumed role
+type ClientCacheKey struct {
+	Service string
+	Region  string
+	RoleARN string
+}
+
+// ClientBuilder builds a new client, returning it along with the expiration of its credentials.
+//
+// Clients with a zero expiration are not cached.
+type ClientBuilder func() (client interface{}, expiresAt time.Time, err error)
+
+// ClientCache is a concurrency safe cache of AWS service clients, which evicts each client
+// when the credentials it was built with are about to expire.
+type ClientCache struct {
+	lock    sync.Mutex
+	clients map[ClientCacheKey]cachedClient
+	now     func() time.Time
+}
+
+type cachedClient struct {
+	client    interface{}
+	expiresAt time.Time
+}
+
+// NewClientCache returns an empty client cache
+func NewClientCache() *ClientCache {
+	return &ClientCache{
+		clients: make(map[ClientCacheKey]cachedClient),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached client for the key, or builds and caches a new one if there is none
+// or its credentials are about to expire.
+//
+// The lock is not held while building, so polling different regions concurrently is not serialized
+// on building their clients. At worst a client is built more than once, and the last one is cached.
+func (c *ClientCache) Get(key ClientCacheKey, build ClientBuilder) (interface{}, error) {
+	c.lock.Lock()
+	cached, ok := c.clients[key]
+	if ok && !c.now().Before(cached.expiresAt.Add(-clientExpiryWindow)) {
+		delete(c.clients, key)
+		ok = false
+	}
+	c.lock.Unlock()
+	if ok {
+		return cached.client, nil
+	}
+
+	client, expiresAt, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if !expiresAt.IsZero() {
+		c.lock.Lock()
+		c.clients[key] = cachedClient{client: client, expiresAt: expiresAt}
+		c.lock.Unlock()
+	}
+	return client, nil
+}
+
+// Purge removes all the clients from the cache
+func (c *ClientCache) Purge() {
+	c.lock.Lock()
+	c.clients = make(map[ClientCacheKey]cachedClient)
+	c.lock.Unlock()
+}
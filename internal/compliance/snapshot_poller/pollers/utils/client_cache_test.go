@@ -0,0 +1,145 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testClientKey = ClientCacheKey{
+	Service: "cloudwatchlogs",
+	Region:  "us-west-2",
+	RoleARN: "arn:aws:iam::123456789012:role/PantherAuditRole-us-east-1",
+}
+
+// Returns a builder which counts how many clients it built, each valid for an hour after now
+func countingBuilder(now time.Time, built *int) ClientBuilder {
+	return func() (interface{}, time.Time, error) {
+		*built++
+		return *built, now.Add(time.Hour), nil
+	}
+}
+
+func TestClientCacheReuse(t *testing.T) {
+	cache := NewClientCache()
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	built := 0
+
+	first, err := cache.Get(testClientKey, countingBuilder(now, &built))
+	require.NoError(t, err)
+	second, err := cache.Get(testClientKey, countingBuilder(now, &built))
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, built)
+
+	// Each service, region and role has its own client
+	otherRegion := testClientKey
+	otherRegion.Region = "us-east-1"
+	otherRole := testClientKey
+	otherRole.RoleARN = "arn:aws:iam::210987654321:role/PantherAuditRole-us-east-1"
+	_, err = cache.Get(otherRegion, countingBuilder(now, &built))
+	require.NoError(t, err)
+	_, err = cache.Get(otherRole, countingBuilder(now, &built))
+	require.NoError(t, err)
+	assert.Equal(t, 3, built)
+}
+
+func TestClientCacheExpiry(t *testing.T) {
+	cache := NewClientCache()
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	cache.now = func() time.Time { return now }
+	built := 0
+
+	_, err := cache.Get(testClientKey, countingBuilder(start, &built))
+	require.NoError(t, err)
+
+	// Still valid until just before the expiry window
+	now = start.Add(time.Hour - clientExpiryWindow - time.Second)
+	client, err := cache.Get(testClientKey, countingBuilder(now, &built))
+	require.NoError(t, err)
+	assert.Equal(t, 1, client)
+
+	// Rebuilt once the credentials are about to expire
+	now = start.Add(time.Hour - clientExpiryWindow)
+	client, err = cache.Get(testClientKey, countingBuilder(now, &built))
+	require.NoError(t, err)
+	assert.Equal(t, 2, client)
+}
+
+func TestClientCacheNoExpiration(t *testing.T) {
+	cache := NewClientCache()
+	built := 0
+	build := func() (interface{}, time.Time, error) {
+		built++
+		return built, time.Time{}, nil
+	}
+
+	_, err := cache.Get(testClientKey, build)
+	require.NoError(t, err)
+	_, err = cache.Get(testClientKey, build)
+	require.NoError(t, err)
+	assert.Equal(t, 2, built)
+}
+
+func TestClientCacheError(t *testing.T) {
+	cache := NewClientCache()
+	client, err := cache.Get(testClientKey, func() (interface{}, time.Time, error) {
+		return nil, time.Time{}, errors.New("assume role failed")
+	})
+	assert.EqualError(t, err, "assume role failed")
+	assert.Nil(t, client)
+	assert.Empty(t, cache.clients)
+}
+
+func TestClientCachePurge(t *testing.T) {
+	cache := NewClientCache()
+	built := 0
+	_, err := cache.Get(testClientKey, countingBuilder(time.Now(), &built))
+	require.NoError(t, err)
+
+	cache.Purge()
+	_, err = cache.Get(testClientKey, countingBuilder(time.Now(), &built))
+	require.NoError(t, err)
+	assert.Equal(t, 2, built)
+}
+
+func TestClientCacheConcurrent(t *testing.T) {
+	cache := NewClientCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Get(testClientKey, func() (interface{}, time.Time, error) {
+				return "client", time.Now().Add(time.Hour), nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, cache.clients, 1)
+}
@@ -22,16 +22,36 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
 )
 
+// PollerLogger returns a logger scoped to the integration and account being polled.
+func PollerLogger(pollerInput *awsmodels.ResourcePollerInput) *zap.Logger {
+	fields := []zap.Field{zap.String("accountId", pollerInput.AuthSourceParsedARN.AccountID)}
+	if pollerInput.IntegrationID != nil {
+		fields = append(fields, zap.String("integrationId", *pollerInput.IntegrationID))
+	}
+	return zap.L().With(fields...)
+}
+
 // LogAWSError logs an AWS error to zap in a digestable format.
 func LogAWSError(apiCall string, err error) {
+	LogAWSErrorTo(zap.L(), apiCall, err)
+}
+
+// LogAWSErrorTo logs an AWS error to the given logger in a digestable format.
+func LogAWSErrorTo(logger *zap.Logger, apiCall string, err error) {
 	if awsErr, ok := err.(awserr.Error); ok {
-		zap.L().Error(
-			apiCall,
+		fields := []zap.Field{
 			zap.String("errorCode", awsErr.Code()),
 			zap.String("errorMessage", awsErr.Message()),
-			zap.Error(errors.Wrap(err, "AWS API call failed")),
-		)
+		}
+		if requestErr, ok := err.(awserr.RequestFailure); ok {
+			fields = append(fields,
+				zap.String("requestId", requestErr.RequestID()),
+				zap.Int("statusCode", requestErr.StatusCode()))
+		}
+		logger.Error(apiCall, append(fields, zap.Error(errors.Wrap(err, "AWS API call failed")))...)
 	}
 }
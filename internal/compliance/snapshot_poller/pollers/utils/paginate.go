@@ -0,0 +1,49 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "github.com/pkg/errors"
+
+// PageFunc requests the page starting at marker (nil for the first page) and collects its items.
+//
+// It returns the marker of the next page, or nil if this was the last page.
+type PageFunc func(marker *string) (next *string, err error)
+
+// Paginate calls fetchPage for every page of a marker based AWS API, for the APIs which have no
+// built in pagination in the SDK.
+//
+// Pagination stops when fetchPage returns a nil or empty marker. Since some APIs always return a
+// marker, fetchPage should return nil when a page has no items. If the API returns the marker of the
+// page which was just requested, an error is returned rather than requesting the same page forever.
+func Paginate(fetchPage PageFunc) error {
+	var marker *string
+	for {
+		next, err := fetchPage(marker)
+		if err != nil {
+			return err
+		}
+		if next == nil || *next == "" {
+			return nil
+		}
+		if marker != nil && *next == *marker {
+			return errors.Errorf("pagination marker %q was repeated", *next)
+		}
+		marker = next
+	}
+}
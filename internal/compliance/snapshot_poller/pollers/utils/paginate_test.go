@@ -0,0 +1,84 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pages builds a PageFunc which serves the given marker -> next marker table, recording requested markers
+func pages(table map[string]*string, requested *[]string) PageFunc {
+	return func(marker *string) (*string, error) {
+		key := aws.StringValue(marker)
+		*requested = append(*requested, key)
+		return table[key], nil
+	}
+}
+
+func TestPaginateSinglePage(t *testing.T) {
+	var requested []string
+	require.NoError(t, Paginate(pages(map[string]*string{"": nil}, &requested)))
+	assert.Equal(t, []string{""}, requested)
+}
+
+func TestPaginateEmptyMarker(t *testing.T) {
+	var requested []string
+	require.NoError(t, Paginate(pages(map[string]*string{"": aws.String("")}, &requested)))
+	assert.Equal(t, []string{""}, requested)
+}
+
+func TestPaginateMultiplePages(t *testing.T) {
+	var requested []string
+	table := map[string]*string{
+		"":   aws.String("m1"),
+		"m1": aws.String("m2"),
+		"m2": nil,
+	}
+	require.NoError(t, Paginate(pages(table, &requested)))
+	assert.Equal(t, []string{"", "m1", "m2"}, requested)
+}
+
+func TestPaginateRepeatedMarker(t *testing.T) {
+	var requested []string
+	table := map[string]*string{
+		"":   aws.String("m1"),
+		"m1": aws.String("m1"),
+	}
+	err := Paginate(pages(table, &requested))
+	require.Error(t, err)
+	assert.Equal(t, []string{"", "m1"}, requested)
+}
+
+func TestPaginateError(t *testing.T) {
+	calls := 0
+	err := Paginate(func(marker *string) (*string, error) {
+		calls++
+		if marker == nil {
+			return aws.String("m1"), nil
+		}
+		return aws.String("m2"), errors.New("throttled")
+	})
+	require.EqualError(t, err, "throttled")
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,94 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+)
+
+// RegionPollFunc polls the resources of a single region.
+type RegionPollFunc func(region string) ([]*apimodels.AddResourceEntry, error)
+
+// RegionErrors maps each region which failed to be polled to its error.
+type RegionErrors map[string]error
+
+func (e RegionErrors) Error() string {
+	regions := make([]string, 0, len(e))
+	for region := range e {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	messages := make([]string, len(regions))
+	for i, region := range regions {
+		messages[i] = region + ": " + e[region].Error()
+	}
+	return "failed to poll regions: " + strings.Join(messages, "; ")
+}
+
+// PollRegions polls each region concurrently, with at most maxParallel regions polled at the same time.
+//
+// A region failing doesn't stop the others: the resources of every region which succeeded are returned,
+// in the order of the regions, along with the errors of the regions which failed (nil if none did).
+// Each region is polled by a single call, so its pagination is independent of the other regions.
+func PollRegions(regions []*string, maxParallel int, poll RegionPollFunc) ([]*apimodels.AddResourceEntry, RegionErrors) {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	results := make([][]*apimodels.AddResourceEntry, len(regions))
+	var (
+		errs   RegionErrors
+		errsMu sync.Mutex
+		wg     sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, maxParallel)
+	for i, region := range regions {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, region string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			resources, err := poll(region)
+			if err != nil {
+				errsMu.Lock()
+				if errs == nil {
+					errs = make(RegionErrors)
+				}
+				errs[region] = err
+				errsMu.Unlock()
+				return
+			}
+			results[i] = resources
+		}(i, *region)
+	}
+	wg.Wait()
+
+	var merged []*apimodels.AddResourceEntry
+	for _, resources := range results {
+		merged = append(merged, resources...)
+	}
+	return merged, errs
+}
@@ -0,0 +1,70 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+)
+
+func TestPollRegions(t *testing.T) {
+	regions := aws.StringSlice([]string{"us-east-1", "us-west-2", "eu-west-1", "ap-south-1"})
+	var inFlight, maxInFlight int32
+
+	resources, errs := PollRegions(regions, 2, func(region string) ([]*apimodels.AddResourceEntry, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if region == "eu-west-1" {
+			return nil, errors.New("access denied")
+		}
+		return []*apimodels.AddResourceEntry{{ID: apimodels.ResourceID(region)}}, nil
+	})
+
+	// Results are in region order and the failed region doesn't abort the others
+	assert.Equal(t, []*apimodels.AddResourceEntry{
+		{ID: "us-east-1"}, {ID: "us-west-2"}, {ID: "ap-south-1"},
+	}, resources)
+	assert.Equal(t, RegionErrors{"eu-west-1": errors.New("access denied")}, errs)
+	assert.EqualError(t, errs, "failed to poll regions: eu-west-1: access denied")
+	assert.LessOrEqual(t, maxInFlight, int32(2))
+}
+
+func TestPollRegionsNoErrors(t *testing.T) {
+	resources, errs := PollRegions(aws.StringSlice([]string{"us-east-1"}), 0,
+		func(region string) ([]*apimodels.AddResourceEntry, error) {
+			return nil, nil
+		})
+	assert.Empty(t, resources)
+	assert.Nil(t, errs)
+}
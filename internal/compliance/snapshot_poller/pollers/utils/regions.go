@@ -22,9 +22,22 @@ import (
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
+// ValidateRegion returns an error if the region can not belong to any of the AWS partitions.
+//
+// Regions match if they are known to the SDK or have the name format of a partition (e.g. "us-<name>-<number>"),
+// so regions launched after this SDK release are accepted. A malformed region would otherwise fail deep in the
+// first API call, with a confusing endpoint error.
+func ValidateRegion(region string) error {
+	if _, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); !ok {
+		return errors.Errorf("unsupported region %q", region)
+	}
+	return nil
+}
+
 // GetRegions returns all the active AWS regions for a given account.
 func GetRegions(ec2Svc ec2iface.EC2API) (regions []*string) {
 	regionsOutput, err := ec2Svc.DescribeRegions(&ec2.DescribeRegionsInput{})
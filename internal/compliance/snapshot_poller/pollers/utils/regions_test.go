@@ -0,0 +1,41 @@
+package utils
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegion(t *testing.T) {
+	for _, region := range []string{"us-west-2", "eu-central-1", "us-gov-west-1", "cn-north-1"} {
+		assert.NoError(t, ValidateRegion(region), region)
+	}
+	// Regions which are not known to the SDK yet
+	assert.NoError(t, ValidateRegion("us-west-99"))
+	assert.NoError(t, ValidateRegion("ap-southeast-9"))
+
+	err := ValidateRegion("uswest2")
+	require.Error(t, err)
+	assert.Equal(t, `unsupported region "uswest2"`, err.Error())
+	assert.Error(t, ValidateRegion("xx-west-2"))
+	assert.Error(t, ValidateRegion(""))
+}
@@ -0,0 +1,188 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+const (
+	// Consecutive failures only open the circuit if they all happened within this window
+	breakerWindow = 5 * time.Minute
+	// How long an open circuit rejects sends before a single probe is allowed through
+	breakerCooldown = time.Minute
+)
+
+// The circuit breaker is disabled if ALERT_CIRCUIT_BREAKER_THRESHOLD is 0
+func getBreakerThreshold() int {
+	threshold := os.Getenv("ALERT_CIRCUIT_BREAKER_THRESHOLD")
+	if threshold == "" {
+		return 5
+	}
+	return mustParseInt(threshold)
+}
+
+// The breaker is shared by all alerts (and warm invocations), so a failing output is skipped everywhere
+var breaker = newCircuitBreaker(getBreakerThreshold(), breakerWindow, breakerCooldown)
+
+// circuitBreaker stops sending to an output after it fails threshold times in a row.
+//
+// Once the circuit for an output is open, sends are rejected until the cooldown has passed.
+// Then a single probe is let through (half-open): if it succeeds the circuit closes, otherwise it opens again.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	outputs map[string]*breakerState // keyed by output ID
+}
+
+type breakerState struct {
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time // zero if the circuit is closed
+	probing      bool      // a half-open probe is in flight
+}
+
+func newCircuitBreaker(threshold int, window, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		now:       time.Now,
+		outputs:   make(map[string]*breakerState),
+	}
+}
+
+// allow returns false if the circuit for the output is open and sends should be skipped.
+func (b *circuitBreaker) allow(outputID string) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.outputs[outputID]
+	if !ok || state.openedAt.IsZero() {
+		return true
+	}
+	if state.probing || b.now().Sub(state.openedAt) < b.cooldown {
+		return false
+	}
+
+	zap.L().Info("circuit half-open, probing output", zap.String("outputID", outputID))
+	state.probing = true
+	return true
+}
+
+// record updates the circuit for the output with the result of a send.
+func (b *circuitBreaker) record(outputID string, success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.outputs[outputID]
+	if success {
+		if ok && !state.openedAt.IsZero() {
+			zap.L().Info("circuit closed, output recovered", zap.String("outputID", outputID))
+		}
+		delete(b.outputs, outputID)
+		return
+	}
+
+	now := b.now()
+	if !ok {
+		state = &breakerState{}
+		b.outputs[outputID] = state
+	}
+	if state.probing {
+		// The half-open probe failed, wait for another cooldown
+		state.probing = false
+		state.openedAt = now
+		zap.L().Warn("circuit re-opened, probe failed", zap.String("outputID", outputID))
+		return
+	}
+	if !state.openedAt.IsZero() {
+		return // a send which started before the circuit opened
+	}
+
+	if state.failures == 0 || now.Sub(state.firstFailure) > b.window {
+		state.failures = 0
+		state.firstFailure = now
+	}
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openedAt = now
+		zap.L().Error("circuit opened, skipping output",
+			zap.String("outputID", outputID),
+			zap.Int("consecutiveFailures", state.failures),
+			zap.Duration("cooldown", b.cooldown),
+		)
+	}
+}
+
+// recordFailure updates the circuit for the output with a failed send, if the output was at fault.
+func (b *circuitBreaker) recordFailure(outputID string, err *outputs.AlertDeliveryError) {
+	if isOutputFailure(err) {
+		b.record(outputID, false)
+	} else {
+		b.release(outputID)
+	}
+}
+
+// release ends a half-open probe which says nothing about the health of the output, so the next send probes again.
+func (b *circuitBreaker) release(outputID string) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state, ok := b.outputs[outputID]; ok {
+		state.probing = false
+	}
+}
+
+// isOutputFailure returns true if the output could not be reached, failed (5xx) or throttled the request.
+//
+// Permanent errors caused by the alert itself (e.g. a payload which is too large, a message template which fails
+// to render or a request rejected as invalid) must not open the circuit for all of the other alerts.
+func isOutputFailure(err *outputs.AlertDeliveryError) bool {
+	switch {
+	case err.TimedOut, err.StatusCode >= http.StatusInternalServerError, err.StatusCode == http.StatusTooManyRequests:
+		return true
+	case err.StatusCode == 0:
+		// A network error, as opposed to failing to build the request
+		return !err.Permanent
+	default:
+		// Any other 4xx: the output is up, it rejected this alert
+		return false
+	}
+}
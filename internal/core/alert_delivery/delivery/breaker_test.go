@@ -0,0 +1,154 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+// Returns a circuit breaker with a clock which can be moved forward by the test
+func testBreaker(threshold int) (*circuitBreaker, *time.Time) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(threshold, time.Minute, 10*time.Second)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreakerOpens(t *testing.T) {
+	b, _ := testBreaker(3)
+	for i := 0; i < 2; i++ {
+		b.record("output-id", false)
+		assert.True(t, b.allow("output-id"))
+	}
+	b.record("output-id", false)
+	assert.False(t, b.allow("output-id"))
+	assert.True(t, b.allow("other-output-id"))
+}
+
+func TestBreakerSuccessResets(t *testing.T) {
+	b, _ := testBreaker(2)
+	b.record("output-id", false)
+	b.record("output-id", true)
+	b.record("output-id", false)
+	assert.True(t, b.allow("output-id"))
+}
+
+func TestBreakerWindow(t *testing.T) {
+	b, now := testBreaker(2)
+	b.record("output-id", false)
+	*now = now.Add(2 * time.Minute)
+	b.record("output-id", false)
+	assert.True(t, b.allow("output-id"))
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b, now := testBreaker(1)
+	b.record("output-id", false)
+	assert.False(t, b.allow("output-id"))
+
+	// After the cooldown, only one probe is allowed through
+	*now = now.Add(11 * time.Second)
+	assert.True(t, b.allow("output-id"))
+	assert.False(t, b.allow("output-id"))
+
+	// A failed probe re-opens the circuit for another cooldown
+	b.record("output-id", false)
+	assert.False(t, b.allow("output-id"))
+	*now = now.Add(11 * time.Second)
+	assert.True(t, b.allow("output-id"))
+
+	// A successful probe closes it
+	b.record("output-id", true)
+	assert.True(t, b.allow("output-id"))
+	assert.True(t, b.allow("output-id"))
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b, _ := testBreaker(0)
+	for i := 0; i < 10; i++ {
+		b.record("output-id", false)
+	}
+	assert.True(t, b.allow("output-id"))
+}
+
+func TestSendCircuitOpen(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	defer setCaches() // reset the shared breaker for other tests
+	breaker, _ = testBreaker(2)
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{}).Twice()
+	ch := make(chan outputStatus, 1)
+
+	for i := 0; i < 3; i++ {
+		send(sampleAlert(), alertOutput, ch)
+		require.Equal(t, outputStatus{outputID: *alertOutput.OutputID, needsRetry: true}, <-ch)
+	}
+	// The third send was skipped without calling the output
+	mockClient.AssertExpectations(t)
+}
+
+func TestIsOutputFailure(t *testing.T) {
+	assert.True(t, isOutputFailure(&outputs.AlertDeliveryError{Message: "network error"}))
+	assert.True(t, isOutputFailure(&outputs.AlertDeliveryError{TimedOut: true}))
+	assert.True(t, isOutputFailure(&outputs.AlertDeliveryError{StatusCode: 503}))
+	assert.True(t, isOutputFailure(&outputs.AlertDeliveryError{StatusCode: 429}))
+
+	assert.False(t, isOutputFailure(&outputs.AlertDeliveryError{StatusCode: 400}))
+	assert.False(t, isOutputFailure(&outputs.AlertDeliveryError{StatusCode: 413, Permanent: true}))
+	assert.False(t, isOutputFailure(&outputs.AlertDeliveryError{Message: "failed to render message template", Permanent: true}))
+}
+
+func TestBreakerIgnoresAlertFailures(t *testing.T) {
+	b, now := testBreaker(1)
+	b.recordFailure("output-id", &outputs.AlertDeliveryError{StatusCode: 413, Permanent: true})
+	assert.True(t, b.allow("output-id"))
+
+	// A probe which fails because of the alert itself lets the next send probe again
+	b.recordFailure("output-id", &outputs.AlertDeliveryError{StatusCode: 500})
+	*now = now.Add(11 * time.Second)
+	assert.True(t, b.allow("output-id"))
+	b.recordFailure("output-id", &outputs.AlertDeliveryError{StatusCode: 400})
+	assert.True(t, b.allow("output-id"))
+	assert.False(t, b.allow("output-id"))
+}
+
+func TestSendPermanentAlertFailureKeepsCircuitClosed(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	defer setCaches() // reset the shared breaker for other tests
+	breaker, _ = testBreaker(2)
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(
+		&outputs.AlertDeliveryError{Message: "payload too large", Permanent: true, StatusCode: 413}).Times(3)
+	ch := make(chan outputStatus, 1)
+
+	for i := 0; i < 3; i++ {
+		send(sampleAlert(), alertOutput, ch)
+		require.Equal(t, outputStatus{outputID: *alertOutput.OutputID, statusCode: 413}, <-ch)
+	}
+	mockClient.AssertExpectations(t)
+}
@@ -19,7 +19,11 @@ package delivery
  */
 
 import (
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -38,6 +42,10 @@ var (
 
 	// Lazy-load the SQS client - we only need it to retry failed alerts
 	sqsClient sqsiface.SQSAPI
+
+	// Lazy-load the DynamoDB client - we only need it if the deliveries table is configured
+	ddbClient     dynamodbiface.DynamoDBAPI
+	ddbClientOnce sync.Once
 )
 
 func getSQSClient() sqsiface.SQSAPI {
@@ -46,3 +54,13 @@ func getSQSClient() sqsiface.SQSAPI {
 	}
 	return sqsClient
 }
+
+// Outputs are sent concurrently, so the DynamoDB client must be created only once
+func getDDBClient() dynamodbiface.DynamoDBAPI {
+	ddbClientOnce.Do(func() {
+		if ddbClient == nil {
+			ddbClient = dynamodb.New(awsSession)
+		}
+	})
+	return ddbClient
+}
@@ -38,6 +38,32 @@ func (m *mockOutputsClient) Slack(alert *alertmodels.Alert, config *outputmodels
 	return args.Get(0).(*outputs.AlertDeliveryError)
 }
 
+func (m *mockOutputsClient) SlackDigest(
+	alerts []*alertmodels.Alert, config *outputmodels.SlackConfig) *outputs.AlertDeliveryError {
+
+	args := m.Called(alerts, config)
+	return args.Get(0).(*outputs.AlertDeliveryError)
+}
+
+func (m *mockOutputsClient) Sns(
+	alert *alertmodels.Alert, config *outputmodels.SnsConfig) (string, *outputs.AlertDeliveryError) {
+
+	args := m.Called(alert, config)
+	return args.String(0), args.Get(1).(*outputs.AlertDeliveryError)
+}
+
+func (m *mockOutputsClient) Email(
+	alert *alertmodels.Alert, config *outputmodels.EmailConfig) (string, *outputs.AlertDeliveryError) {
+
+	args := m.Called(alert, config)
+	return args.String(0), args.Get(1).(*outputs.AlertDeliveryError)
+}
+
+func (m *mockOutputsClient) Webhook(alert *alertmodels.Alert, config *outputmodels.WebhookConfig) *outputs.AlertDeliveryError {
+	args := m.Called(alert, config)
+	return args.Get(0).(*outputs.AlertDeliveryError)
+}
+
 type mockLambdaClient struct {
 	lambdaiface.LambdaAPI
 	mock.Mock
@@ -19,6 +19,9 @@ package delivery
  */
 
 import (
+	"os"
+	"time"
+
 	"go.uber.org/zap"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -26,11 +29,53 @@ import (
 	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
 )
 
+func getMaxConcurrentSends() int {
+	concurrency := os.Getenv("ALERT_DELIVERY_CONCURRENCY")
+	if concurrency == "" {
+		concurrency = "20"
+	}
+	if val := mustParseInt(concurrency); val > 0 {
+		return val
+	}
+	return 1
+}
+
+// The maximum number of alert -> output pairs which are sent at the same time
+var maxConcurrentSends = getMaxConcurrentSends()
+
+// Digests are disabled unless ALERT_DIGEST_THRESHOLD is at least 2
+func getDigestThreshold() int {
+	threshold := os.Getenv("ALERT_DIGEST_THRESHOLD")
+	if threshold == "" {
+		return 0
+	}
+	return mustParseInt(threshold)
+}
+
+// When at least this many alerts in a batch are sent to the same output, and the output type
+// supports it, they are delivered as a single digest message instead of one message each.
+var digestThreshold = getDigestThreshold()
+
+// The output types which can deliver a digest of several alerts
+var digestOutputTypes = map[string]bool{
+	"slack": true,
+}
+
 // outputStatus communicates parallelized alert delivery status via channels.
 type outputStatus struct {
 	outputID   string
 	success    bool
 	needsRetry bool
+	// The alert was not sent, because it was already successfully sent to this output
+	alreadyDelivered bool
+	// The output did not respond in time (the send can be retried)
+	timedOut bool
+	// The HTTP status code returned by the output, if it rejected the request
+	statusCode int
+	// The alert was not sent, because it was raised during a maintenance window
+	suppressed bool
+	// The ID the output assigned to the message, for outputs which report one (e.g. the SES message ID of emails)
+	messageID string
 }
 
 // Send an alert to one specific output (run as a child goroutine).
@@ -46,16 +91,30 @@ func send(alert *alertmodels.Alert, output *outputmodels.AlertOutput, statusChan
 		// Otherwise, the main routine will wait forever for this to finish.
 		if r := recover(); r != nil {
 			zap.L().Error("panic sending alert", append(commonFields, zap.Any("panic", r))...)
+			breaker.release(*output.OutputID)
 			statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: false}
 		}
 	}()
 
+	if alreadyDelivered(alert, *output.OutputID) {
+		zap.L().Info("alert was already delivered to output, skipping", commonFields...)
+		statusChannel <- outputStatus{outputID: *output.OutputID, alreadyDelivered: true}
+		return
+	}
+
+	if !breaker.allow(*output.OutputID) {
+		zap.L().Warn("output unavailable (circuit open), skipping", commonFields...)
+		statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: true}
+		return
+	}
+
 	zap.L().Info(
 		"sending alert",
 		append(commonFields, zap.String("name", *output.DisplayName))...,
 	)
 
 	var alertDeliveryError *outputs.AlertDeliveryError
+	var messageID string
 	switch *output.OutputType {
 	case "slack":
 		alertDeliveryError = outputClient.Slack(alert, output.OutputConfig.Slack)
@@ -72,24 +131,118 @@ func send(alert *alertmodels.Alert, output *outputmodels.AlertOutput, statusChan
 	case "sqs":
 		alertDeliveryError = outputClient.Sqs(alert, output.OutputConfig.Sqs)
 	case "sns":
-		alertDeliveryError = outputClient.Sns(alert, output.OutputConfig.Sns)
+		messageID, alertDeliveryError = outputClient.Sns(alert, output.OutputConfig.Sns)
 	case "asana":
 		alertDeliveryError = outputClient.Asana(alert, output.OutputConfig.Asana)
 	case "customwebhook":
 		alertDeliveryError = outputClient.CustomWebhook(alert, output.OutputConfig.CustomWebhook)
+	case "webhook":
+		alertDeliveryError = outputClient.Webhook(alert, output.OutputConfig.Webhook)
+	case "email":
+		messageID, alertDeliveryError = outputClient.Email(alert, output.OutputConfig.Email)
 	default:
 		zap.L().Warn("unsupported output type", commonFields...)
 		statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: false}
 		return
 	}
 	if alertDeliveryError != nil {
-		zap.L().Warn("failed to send alert", append(commonFields, zap.Error(alertDeliveryError))...)
+		if alertDeliveryError.TimedOut {
+			zap.L().Warn("timed out sending alert", append(commonFields, zap.Error(alertDeliveryError))...)
+		} else {
+			zap.L().Warn("failed to send alert", append(commonFields, zap.Error(alertDeliveryError))...)
+		}
+		breaker.recordFailure(*output.OutputID, alertDeliveryError)
 		statusChannel <- outputStatus{
-			outputID: *output.OutputID, success: false, needsRetry: !alertDeliveryError.Permanent}
+			outputID:   *output.OutputID,
+			success:    false,
+			needsRetry: !alertDeliveryError.Permanent,
+			timedOut:   alertDeliveryError.TimedOut,
+			statusCode: alertDeliveryError.StatusCode,
+		}
 		return
 	}
 
+	if messageID != "" {
+		commonFields = append(commonFields, zap.String("messageId", messageID))
+	}
 	zap.L().Info("alert success", commonFields...)
+	breaker.record(*output.OutputID, true)
+	recordDelivery(alert, *output.OutputID)
+	statusChannel <- outputStatus{outputID: *output.OutputID, success: true, needsRetry: false, messageID: messageID}
+}
+
+// Send a digest of several alerts to one specific output (run as a child goroutine).
+//
+// Alerts which were already delivered to the output are left out of the digest.
+// The statusChannel will be sent a single message with the result for all of the alerts.
+func sendDigest(alerts []*alertmodels.Alert, output *outputmodels.AlertOutput, statusChannel chan outputStatus) {
+	commonFields := []zap.Field{
+		zap.String("outputID", *output.OutputID),
+		zap.Int("alerts", len(alerts)),
+	}
+	defer func() {
+		// If we panic when sending the digest, log an error and report back to the channel.
+		if r := recover(); r != nil {
+			zap.L().Error("panic sending alert digest", append(commonFields, zap.Any("panic", r))...)
+			breaker.release(*output.OutputID)
+			statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: false}
+		}
+	}()
+
+	var pending []*alertmodels.Alert
+	for _, alert := range alerts {
+		if !alreadyDelivered(alert, *output.OutputID) {
+			pending = append(pending, alert)
+		}
+	}
+	if len(pending) == 0 {
+		zap.L().Info("alerts were already delivered to output, skipping digest", commonFields...)
+		statusChannel <- outputStatus{outputID: *output.OutputID, alreadyDelivered: true}
+		return
+	}
+
+	if !breaker.allow(*output.OutputID) {
+		zap.L().Warn("output unavailable (circuit open), skipping digest", commonFields...)
+		statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: true}
+		return
+	}
+
+	zap.L().Info(
+		"sending alert digest",
+		append(commonFields, zap.String("name", *output.DisplayName))...,
+	)
+
+	var alertDeliveryError *outputs.AlertDeliveryError
+	switch *output.OutputType {
+	case "slack":
+		alertDeliveryError = outputClient.SlackDigest(pending, output.OutputConfig.Slack)
+	default:
+		zap.L().Warn("unsupported output type for digests", commonFields...)
+		statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: false}
+		return
+	}
+	if alertDeliveryError != nil {
+		if alertDeliveryError.TimedOut {
+			zap.L().Warn("timed out sending alert digest", append(commonFields, zap.Error(alertDeliveryError))...)
+		} else {
+			zap.L().Warn("failed to send alert digest", append(commonFields, zap.Error(alertDeliveryError))...)
+		}
+		breaker.recordFailure(*output.OutputID, alertDeliveryError)
+		statusChannel <- outputStatus{
+			outputID:   *output.OutputID,
+			success:    false,
+			needsRetry: !alertDeliveryError.Permanent,
+			timedOut:   alertDeliveryError.TimedOut,
+			statusCode: alertDeliveryError.StatusCode,
+		}
+		return
+	}
+
+	zap.L().Info("alert digest success", commonFields...)
+	breaker.record(*output.OutputID, true)
+	for _, alert := range pending {
+		recordDelivery(alert, *output.OutputID)
+	}
 	statusChannel <- outputStatus{outputID: *output.OutputID, success: true, needsRetry: false}
 }
 
@@ -97,50 +250,195 @@ func send(alert *alertmodels.Alert, output *outputmodels.AlertOutput, statusChan
 //
 // Returns true if the alert was sent successfully, false if it needs to be retried.
 func dispatch(alert *alertmodels.Alert) bool {
-	alertOutputs, err := getAlertOutputs(alert)
-
-	if err != nil {
-		zap.L().Warn("failed to get the outputs for the alert",
-			zap.String("policyId", alert.AnalysisID),
-			zap.String("severity", alert.Severity),
-			zap.Error(err),
-		)
-		return false
-	}
-
-	if len(alertOutputs) == 0 {
-		zap.L().Info("no outputs configured",
-			zap.String("policyId", alert.AnalysisID),
-			zap.String("severity", alert.Severity),
-		)
-		return true
-	}
-
-	// Dispatch all outputs in parallel.
-	// This ensures one slow or failing output won't block the others.
-	statusChannel := make(chan outputStatus)
-	for _, output := range alertOutputs {
-		go send(alert, output, statusChannel)
-	}
-
-	// Wait until all outputs have finished, gathering any that need to be retried.
-	var retryOutputs []string
-	for range alertOutputs {
-		status := <-statusChannel
-		if status.needsRetry {
-			retryOutputs = append(retryOutputs, status.outputID)
-		} else if !status.success {
+	return dispatchBatch([]*alertmodels.Alert{alert})[0]
+}
+
+// deliveryJob is a single alert -> output pair to be sent by a worker,
+// or a digest of several alerts if there is more than one alert index.
+type deliveryJob struct {
+	alertIndexes []int
+	output       *outputmodels.AlertOutput
+}
+
+// deliveryResult is the outcome of a deliveryJob, which applies to all of its alerts.
+type deliveryResult struct {
+	alertIndexes []int
+	status       outputStatus
+}
+
+// digestJobs rolls the jobs for each digest capable output into a single digest job,
+// if there are at least digestThreshold of them.
+func digestJobs(jobs []deliveryJob) []deliveryJob {
+	if digestThreshold < 2 {
+		return jobs
+	}
+
+	// output ID -> alert indexes which can be delivered in a digest
+	digestable := make(map[string][]int)
+	for _, job := range jobs {
+		if digestOutputTypes[*job.output.OutputType] {
+			digestable[*job.output.OutputID] = append(digestable[*job.output.OutputID], job.alertIndexes...)
+		}
+	}
+
+	var result []deliveryJob
+	digested := make(map[string]bool)
+	for _, job := range jobs {
+		outputID := *job.output.OutputID
+		if len(digestable[outputID]) < digestThreshold {
+			result = append(result, job)
+			continue
+		}
+		if !digested[outputID] {
+			digested[outputID] = true
+			result = append(result, deliveryJob{alertIndexes: digestable[outputID], output: job.output})
+		}
+	}
+	return result
+}
+
+// dispatchBatch sends each alert to each of its designated outputs.
+//
+// All alert -> output pairs in the batch are sent in parallel by a bounded pool of workers,
+// so one slow or failing output won't block the others and we never exceed maxConcurrentSends
+// outstanding requests. Since each worker sends synchronously, any per-output throttling done
+// while sending holds its worker and composes with the pool limit.
+//
+// If digests are enabled, alerts sent to the same output may be delivered as a single message.
+// Alerts sent to an ordered output are delivered one at a time in creation order (see orderedOutputs).
+//
+// During a maintenance window, alerts are not sent unless they ignore maintenance. They are reported
+// as suppressed for each of their outputs instead, and are not retried.
+//
+// Returns, for each alert in order, true if it was sent successfully, false if it needs to be retried.
+func dispatchBatch(alerts []*alertmodels.Alert) []bool {
+	results, _ := dispatchBatchResults(alerts)
+	return results
+}
+
+// dispatchBatchResults is dispatchBatch, additionally returning the outcome of every delivery job.
+func dispatchBatchResults(alerts []*alertmodels.Alert) ([]bool, []deliveryResult) {
+	results := make([]bool, len(alerts))
+	var jobs []deliveryJob
+	var deliveries []deliveryResult
+	maintenance := inMaintenance(time.Now())
+	for i, alert := range alerts {
+		enrichAlert(alert)
+		alertOutputs, err := getAlertOutputs(alert)
+		if err != nil {
+			zap.L().Warn("failed to get the outputs for the alert",
+				zap.String("policyId", alert.AnalysisID),
+				zap.String("severity", alert.Severity),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if maintenance && !alert.IgnoreMaintenance {
+			zap.L().Info("suppressing alert during maintenance window",
+				zap.String("policyId", alert.AnalysisID),
+				zap.String("severity", alert.Severity),
+				zap.Int("outputs", len(alertOutputs)),
+			)
+			results[i] = true
+			for _, output := range alertOutputs {
+				deliveries = append(deliveries, deliveryResult{
+					alertIndexes: []int{i},
+					status:       outputStatus{outputID: *output.OutputID, suppressed: true},
+				})
+			}
+			continue
+		}
+
+		if output := getEscalationOutput(alert, alertOutputs); output != nil {
+			zap.L().Warn("adding escalation output after repeated delivery failures",
+				zap.String("policyId", alert.AnalysisID),
+				zap.String("severity", alert.Severity),
+				zap.Int("retryCount", alert.RetryCount),
+				zap.String("escalationOutputID", *output.OutputID),
+			)
+			alertOutputs = append(alertOutputs, output)
+			alert.Escalated = true
+		}
+
+		results[i] = true
+		if len(alertOutputs) == 0 {
+			zap.L().Info("no outputs configured",
+				zap.String("policyId", alert.AnalysisID),
+				zap.String("severity", alert.Severity),
+			)
+			continue
+		}
+
+		for _, output := range alertOutputs {
+			jobs = append(jobs, deliveryJob{alertIndexes: []int{i}, output: output})
+		}
+	}
+	jobs = digestJobs(jobs)
+
+	if len(jobs) == 0 {
+		return results, deliveries
+	}
+
+	groups := groupJobs(jobs, alerts)
+	workers := maxConcurrentSends
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+
+	groupChannel := make(chan []deliveryJob)
+	resultChannel := make(chan deliveryResult)
+	for w := 0; w < workers; w++ {
+		go func() {
+			statusChannel := make(chan outputStatus, 1)
+			for group := range groupChannel {
+				for _, job := range group {
+					if len(job.alertIndexes) == 1 {
+						send(alerts[job.alertIndexes[0]], job.output, statusChannel)
+					} else {
+						digest := make([]*alertmodels.Alert, len(job.alertIndexes))
+						for i, alertIndex := range job.alertIndexes {
+							digest[i] = alerts[alertIndex]
+						}
+						sendDigest(digest, job.output, statusChannel)
+					}
+					resultChannel <- deliveryResult{alertIndexes: job.alertIndexes, status: <-statusChannel}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, group := range groups {
+			groupChannel <- group
+		}
+		close(groupChannel)
+	}()
+
+	// Wait until all pairs have finished, gathering any outputs that need to be retried.
+	retryOutputs := make(map[int][]string)
+	for range jobs {
+		result := <-resultChannel
+		deliveries = append(deliveries, result)
+		if result.status.alreadyDelivered {
+			continue
+		}
+		if result.status.needsRetry {
+			for _, i := range result.alertIndexes {
+				retryOutputs[i] = append(retryOutputs[i], result.status.outputID)
+			}
+		} else if !result.status.success {
 			zap.L().Error(
 				"permanently failed to send alert to output",
-				zap.String("outputID", status.outputID),
+				zap.String("outputID", result.status.outputID),
+				zap.Int("statusCode", result.status.statusCode),
 			)
 		}
 	}
 
-	if len(retryOutputs) > 0 {
-		alert.OutputIds = retryOutputs // Replace the outputs with the set that failed
-		return false
+	for i, outputIDs := range retryOutputs {
+		alerts[i].OutputIds = outputIDs // Replace the outputs with the set that failed
+		results[i] = false
 	}
 
-	return true
+	return results, deliveries
 }
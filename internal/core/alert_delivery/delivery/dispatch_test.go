@@ -19,6 +19,7 @@ package delivery
  */
 
 import (
+	"os"
 	"testing"
 	"time"
 
@@ -55,6 +56,7 @@ var alertOutput = &outputmodels.AlertOutput{
 }
 
 func setCaches() {
+	breaker = newCircuitBreaker(getBreakerThreshold(), breakerWindow, breakerCooldown)
 	cache = &outputsCache{
 		Outputs:   []*outputmodels.AlertOutput{alertOutput},
 		Timestamp: time.Now(),
@@ -97,6 +99,30 @@ func TestSendTransientFailure(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestSendRejected(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	ch := make(chan outputStatus, 1)
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{StatusCode: 429})
+
+	send(sampleAlert(), alertOutput, ch)
+	assert.Equal(t, outputStatus{outputID: *alertOutput.OutputID, needsRetry: true, statusCode: 429}, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSendTimeout(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	ch := make(chan outputStatus, 1)
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{TimedOut: true})
+
+	send(sampleAlert(), alertOutput, ch)
+	assert.Equal(t, outputStatus{outputID: *alertOutput.OutputID, needsRetry: true, timedOut: true}, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
 func TestSendSuccess(t *testing.T) {
 	mockClient := &mockOutputsClient{}
 	outputClient = mockClient
@@ -109,6 +135,47 @@ func TestSendSuccess(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestSendWebhook(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	webhookOutput := &outputmodels.AlertOutput{
+		OutputType:  aws.String("webhook"),
+		DisplayName: aws.String("webhook:alerts"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Webhook: &outputmodels.WebhookConfig{WebhookURL: "https://example.com", SigningSecret: "secret"},
+		},
+		OutputID: aws.String("output-id"),
+	}
+	mockClient.On("Webhook", mock.Anything, webhookOutput.OutputConfig.Webhook).Return(
+		&outputs.AlertDeliveryError{Message: "request failed with status code 503: unavailable"})
+	ch := make(chan outputStatus, 1)
+
+	send(sampleAlert(), webhookOutput, ch)
+	assert.Equal(t, outputStatus{outputID: *webhookOutput.OutputID, needsRetry: true}, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSendEmail(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	emailOutput := &outputmodels.AlertOutput{
+		OutputType:  aws.String("email"),
+		DisplayName: aws.String("email:security"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Email: &outputmodels.EmailConfig{FromAddress: "alerts@example.com", Recipients: []string{"security@example.com"}},
+		},
+		OutputID: aws.String("email-id"),
+	}
+	mockClient.On("Email", mock.Anything, emailOutput.OutputConfig.Email).Return(
+		"ses-message-id", (*outputs.AlertDeliveryError)(nil))
+	ch := make(chan outputStatus, 1)
+
+	send(sampleAlert(), emailOutput, ch)
+	assert.Equal(t, outputStatus{outputID: "email-id", success: true, messageID: "ses-message-id"}, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
 func TestDispatchFailure(t *testing.T) {
 	mockClient := &mockOutputsClient{}
 	outputClient = mockClient
@@ -193,3 +260,109 @@ func TestAllGoRoutinesShouldComplete(t *testing.T) {
 	assert.True(t, dispatch(alert))
 	mockLambdaClient.AssertExpectations(t)
 }
+
+func TestDispatchBatch(t *testing.T) {
+	defer func(concurrency int) { maxConcurrentSends = concurrency }(maxConcurrentSends)
+	maxConcurrentSends = 2
+
+	failingOutput := &outputmodels.AlertOutput{
+		OutputType:  aws.String("slack"),
+		DisplayName: aws.String("slack:failing"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Slack: &outputmodels.SlackConfig{WebhookURL: "https://slack.com/failing"},
+		},
+		OutputID: aws.String("output-id-failing"),
+	}
+	cache = &outputsCache{
+		Outputs:   []*outputmodels.AlertOutput{alertOutput, failingOutput},
+		Timestamp: time.Now(),
+	}
+
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, alertOutput.OutputConfig.Slack).
+		Return((*outputs.AlertDeliveryError)(nil)).Times(3)
+	mockClient.On("Slack", mock.Anything, failingOutput.OutputConfig.Slack).
+		Return(&outputs.AlertDeliveryError{}).Once()
+
+	failingAlert := sampleAlert()
+	failingAlert.OutputIds = []string{"output-id", "output-id-failing"}
+	alerts := []*alertmodels.Alert{sampleAlert(), failingAlert, sampleAlert()}
+
+	assert.Equal(t, []bool{true, false, true}, dispatchBatch(alerts))
+	assert.Equal(t, []string{"output-id-failing"}, failingAlert.OutputIds)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDispatchBatchNoOutputs(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+
+	alert := sampleAlert()
+	alert.OutputIds = []string{"unknown-output-id"}
+	assert.Equal(t, []bool{true}, dispatchBatch([]*alertmodels.Alert{alert}))
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetMaxConcurrentSends(t *testing.T) {
+	defer os.Unsetenv("ALERT_DELIVERY_CONCURRENCY")
+
+	os.Setenv("ALERT_DELIVERY_CONCURRENCY", "5")
+	assert.Equal(t, 5, getMaxConcurrentSends())
+	os.Setenv("ALERT_DELIVERY_CONCURRENCY", "0")
+	assert.Equal(t, 1, getMaxConcurrentSends())
+	os.Unsetenv("ALERT_DELIVERY_CONCURRENCY")
+	assert.Equal(t, 20, getMaxConcurrentSends())
+}
+
+func TestDigestJobs(t *testing.T) {
+	defer func(threshold int) { digestThreshold = threshold }(digestThreshold)
+	webhookOutput := &outputmodels.AlertOutput{OutputType: aws.String("customwebhook"), OutputID: aws.String("webhook-id")}
+	jobs := []deliveryJob{
+		{alertIndexes: []int{0}, output: alertOutput},
+		{alertIndexes: []int{0}, output: webhookOutput},
+		{alertIndexes: []int{1}, output: alertOutput},
+		{alertIndexes: []int{1}, output: webhookOutput},
+		{alertIndexes: []int{2}, output: alertOutput},
+	}
+
+	digestThreshold = 0
+	assert.Equal(t, jobs, digestJobs(jobs))
+
+	digestThreshold = 4
+	assert.Equal(t, jobs, digestJobs(jobs))
+
+	digestThreshold = 3
+	assert.Equal(t, []deliveryJob{
+		{alertIndexes: []int{0, 1, 2}, output: alertOutput},
+		{alertIndexes: []int{0}, output: webhookOutput},
+		{alertIndexes: []int{1}, output: webhookOutput},
+	}, digestJobs(jobs))
+}
+
+func TestDispatchBatchDigest(t *testing.T) {
+	defer func(threshold int) { digestThreshold = threshold }(digestThreshold)
+	digestThreshold = 2
+
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	alerts := []*alertmodels.Alert{sampleAlert(), sampleAlert()}
+	mockClient.On("SlackDigest", alerts, alertOutput.OutputConfig.Slack).
+		Return(&outputs.AlertDeliveryError{}).Once()
+
+	assert.Equal(t, []bool{false, false}, dispatchBatch(alerts))
+	assert.Equal(t, []string{"output-id"}, alerts[0].OutputIds)
+	assert.Equal(t, []string{"output-id"}, alerts[1].OutputIds)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetDigestThreshold(t *testing.T) {
+	defer os.Unsetenv("ALERT_DIGEST_THRESHOLD")
+
+	os.Setenv("ALERT_DIGEST_THRESHOLD", "5")
+	assert.Equal(t, 5, getDigestThreshold())
+	os.Unsetenv("ALERT_DIGEST_THRESHOLD")
+	assert.Equal(t, 0, getDigestThreshold())
+}
@@ -0,0 +1,87 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// accountMetadataSource looks up the organization-specific fields of an account, e.g. {"costCenter": "1234"}.
+//
+// A nil result means there are no fields for the account. Other sources (e.g. a table) can be plugged in
+// by replacing accountMetadata.
+type accountMetadataSource interface {
+	lookup(accountID string) (map[string]string, error)
+}
+
+// staticAccountMetadata is the account metadata configured in the environment
+type staticAccountMetadata map[string]map[string]string
+
+func (m staticAccountMetadata) lookup(accountID string) (map[string]string, error) {
+	return m[accountID], nil
+}
+
+// The fields of each account, e.g. {"123456789012": {"costCenter": "1234", "environment": "prod"}}
+func getAccountMetadata() staticAccountMetadata {
+	result := make(staticAccountMetadata)
+	if config := os.Getenv("ALERT_ACCOUNT_METADATA"); config != "" {
+		if err := jsoniter.UnmarshalFromString(config, &result); err != nil {
+			panic(err)
+		}
+	}
+	return result
+}
+
+var accountMetadata accountMetadataSource = getAccountMetadata()
+
+// enrichAlert adds the metadata of the alert's account to its context before it is formatted for the outputs.
+//
+// Enrichment is best-effort: alerts without an account, or with an account which has no metadata or fails
+// the lookup, are delivered without the extra fields. Fields already in the alert's context are kept.
+func enrichAlert(alert *alertmodels.Alert) {
+	if alert.AccountID == nil {
+		return
+	}
+	fields, err := accountMetadata.lookup(*alert.AccountID)
+	if err != nil {
+		zap.L().Warn("failed to look up the account metadata of the alert",
+			zap.String("policyId", alert.AnalysisID),
+			zap.String("accountId", *alert.AccountID),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	merged := make(map[string]string, len(fields)+len(alert.Context))
+	for key, value := range fields {
+		merged[key] = value
+	}
+	for key, value := range alert.Context {
+		merged[key] = value
+	}
+	alert.Context = merged
+}
@@ -0,0 +1,87 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingAccountMetadata struct{}
+
+func (failingAccountMetadata) lookup(string) (map[string]string, error) {
+	return nil, errors.New("table unavailable")
+}
+
+func setAccountMetadata(t *testing.T, source accountMetadataSource) {
+	previous := accountMetadata
+	accountMetadata = source
+	t.Cleanup(func() { accountMetadata = previous })
+}
+
+func TestGetAccountMetadata(t *testing.T) {
+	defer os.Unsetenv("ALERT_ACCOUNT_METADATA")
+
+	os.Unsetenv("ALERT_ACCOUNT_METADATA")
+	assert.Empty(t, getAccountMetadata())
+	os.Setenv("ALERT_ACCOUNT_METADATA", `{"123456789012": {"costCenter": "1234"}}`)
+	assert.Equal(t, staticAccountMetadata{"123456789012": {"costCenter": "1234"}}, getAccountMetadata())
+	os.Setenv("ALERT_ACCOUNT_METADATA", `["123456789012"]`)
+	assert.Panics(t, func() { getAccountMetadata() })
+}
+
+func TestEnrichAlert(t *testing.T) {
+	setAccountMetadata(t, staticAccountMetadata{
+		"123456789012": {"costCenter": "1234", "environment": "prod"},
+	})
+
+	alert := sampleAlert()
+	alert.AccountID = aws.String("123456789012")
+	alert.Context = map[string]string{"environment": "staging"}
+	enrichAlert(alert)
+	// Fields already in the alert's context are kept
+	assert.Equal(t, map[string]string{"costCenter": "1234", "environment": "staging"}, alert.Context)
+}
+
+func TestEnrichAlertMiss(t *testing.T) {
+	setAccountMetadata(t, staticAccountMetadata{
+		"123456789012": {"costCenter": "1234"},
+	})
+
+	alert := sampleAlert()
+	enrichAlert(alert)
+	assert.Nil(t, alert.Context)
+
+	alert.AccountID = aws.String("210987654321")
+	enrichAlert(alert)
+	assert.Nil(t, alert.Context)
+}
+
+func TestEnrichAlertLookupFailure(t *testing.T) {
+	setAccountMetadata(t, failingAccountMetadata{})
+
+	alert := sampleAlert()
+	alert.AccountID = aws.String("123456789012")
+	enrichAlert(alert)
+	assert.Nil(t, alert.Context)
+}
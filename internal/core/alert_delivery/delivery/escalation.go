@@ -0,0 +1,83 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// Alerts which have been retried at least this many times are escalated
+func getEscalationRetries() int {
+	retries := os.Getenv("ALERT_ESCALATION_RETRIES")
+	if retries == "" {
+		retries = "3"
+	}
+	return mustParseInt(retries)
+}
+
+// The escalation output ID for each severity, e.g. {"CRITICAL": "output-id"}
+func getEscalationOutputs() map[string]string {
+	result := make(map[string]string)
+	if config := os.Getenv("ALERT_ESCALATION_OUTPUTS"); config != "" {
+		if err := jsoniter.UnmarshalFromString(config, &result); err != nil {
+			panic(err)
+		}
+	}
+	return result
+}
+
+var (
+	escalationRetries = getEscalationRetries()
+	escalationOutputs = getEscalationOutputs()
+)
+
+// Returns the output to add to an alert which keeps failing delivery, nil if it should not be escalated.
+//
+// The escalation output is added once, and only if it isn't one of the alert's outputs already.
+func getEscalationOutput(
+	alert *alertmodels.Alert, alertOutputs []*outputmodels.AlertOutput) *outputmodels.AlertOutput {
+
+	if alert.Escalated || alert.RetryCount < escalationRetries {
+		return nil
+	}
+	outputID, ok := escalationOutputs[alert.Severity]
+	if !ok {
+		return nil
+	}
+
+	for _, output := range alertOutputs {
+		if *output.OutputID == outputID {
+			return nil
+		}
+	}
+	if cache == nil {
+		return nil
+	}
+	for _, output := range cache.Outputs {
+		if *output.OutputID == outputID {
+			return output
+		}
+	}
+	return nil
+}
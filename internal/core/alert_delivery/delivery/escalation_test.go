@@ -0,0 +1,92 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+var escalationOutput = &outputmodels.AlertOutput{
+	OutputType:  aws.String("slack"),
+	DisplayName: aws.String("slack:escalation"),
+	OutputConfig: &outputmodels.OutputConfig{
+		Slack: &outputmodels.SlackConfig{WebhookURL: "https://slack.com/escalation"},
+	},
+	OutputID: aws.String("escalation-output-id"),
+}
+
+func setEscalation(t *testing.T) {
+	previousOutputs := escalationOutputs
+	t.Cleanup(func() { escalationOutputs = previousOutputs })
+	escalationOutputs = map[string]string{"INFO": "escalation-output-id"}
+
+	setCaches()
+	cache = &outputsCache{
+		Outputs:   []*outputmodels.AlertOutput{alertOutput, escalationOutput},
+		Timestamp: time.Now(),
+	}
+}
+
+func TestGetEscalationOutput(t *testing.T) {
+	setEscalation(t)
+	alert := sampleAlert()
+	alertOutputs := []*outputmodels.AlertOutput{alertOutput}
+
+	assert.Nil(t, getEscalationOutput(alert, alertOutputs))
+
+	alert.RetryCount = escalationRetries
+	assert.Equal(t, escalationOutput, getEscalationOutput(alert, alertOutputs))
+
+	// Already targeted by the alert
+	assert.Nil(t, getEscalationOutput(alert, append(alertOutputs, escalationOutput)))
+
+	// Already escalated
+	alert.Escalated = true
+	assert.Nil(t, getEscalationOutput(alert, alertOutputs))
+
+	// No escalation output for the severity
+	alert.Escalated = false
+	alert.Severity = "HIGH"
+	assert.Nil(t, getEscalationOutput(alert, alertOutputs))
+}
+
+func TestDispatchEscalates(t *testing.T) {
+	setEscalation(t)
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, alertOutput.OutputConfig.Slack).
+		Return(&outputs.AlertDeliveryError{}).Once()
+	mockClient.On("Slack", mock.Anything, escalationOutput.OutputConfig.Slack).
+		Return((*outputs.AlertDeliveryError)(nil)).Once()
+
+	alert := sampleAlert()
+	alert.RetryCount = escalationRetries
+	assert.False(t, dispatch(alert))
+	assert.True(t, alert.Escalated)
+	assert.Equal(t, []string{"output-id"}, alert.OutputIds)
+	mockClient.AssertExpectations(t)
+}
@@ -46,8 +46,9 @@ func HandleAlerts(alerts []*models.Alert) {
 
 	zap.L().Info("starting processing alerts", zap.Int("alerts", len(alerts)))
 
-	for _, alert := range alerts {
-		if !dispatch(alert) {
+	results, deliveries := dispatchBatchResults(alerts)
+	for i, alert := range alerts {
+		if !results[i] {
 			if time.Since(alert.CreatedAt) > getMaxRetryDuration() {
 				zap.L().Error(
 					"alert delivery permanently failed, exceeded max retry duration",
@@ -61,6 +62,7 @@ func HandleAlerts(alerts []*models.Alert) {
 					zap.String("policyId", alert.AnalysisID),
 					zap.String("severity", alert.Severity),
 				)
+				alert.RetryCount++
 				failedAlerts = append(failedAlerts, alert)
 			}
 		}
@@ -69,4 +71,6 @@ func HandleAlerts(alerts []*models.Alert) {
 	if len(failedAlerts) > 0 {
 		retry(failedAlerts)
 	}
+
+	sendStatusCallback(alerts, results, deliveries)
 }
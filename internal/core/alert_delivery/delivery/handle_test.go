@@ -69,4 +69,5 @@ func TestHandleAlertsTemporarilyFailed(t *testing.T) {
 
 	HandleAlerts(alerts)
 	assert.Equal(t, 3, sqsMessages)
+	assert.Equal(t, 3, alert.RetryCount) // the same alert was retried 3 times
 }
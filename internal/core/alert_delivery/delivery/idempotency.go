@@ -0,0 +1,117 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// Deliveries are recorded in this table to avoid sending the same alert to an output twice,
+// e.g. when SQS redelivers a batch after the lambda failed part way through.
+// If the table is not configured, every alert is sent.
+//
+// Each row has a "key" (see idempotencyKey) and "expiresAt", the epoch seconds after which
+// the alert can be sent to the output again. "expiresAt" is also the TTL attribute of the table.
+var deliveriesTable = os.Getenv("ALERT_DELIVERIES_TABLE")
+
+// Retries of an alert are grouped in buckets of this many retries, 0 puts all of them in the same bucket
+func getRetryBucketSize() int {
+	size := os.Getenv("ALERT_IDEMPOTENCY_RETRY_BUCKET")
+	if size == "" {
+		return 10
+	}
+	return mustParseInt(size)
+}
+
+var retryBucketSize = getRetryBucketSize()
+
+// Build the key identifying the delivery of an alert to an output.
+//
+// Rule alerts have a unique ID, policy alerts are identified by their policy and creation time.
+// Redeliveries and retries in the same retry bucket share the key, so outputs which already succeeded are skipped.
+// An alert still failing after a whole bucket of retries is sent to all of its outputs again, as a reminder.
+func idempotencyKey(alert *alertmodels.Alert, outputID string) string {
+	alertID := aws.StringValue(alert.AlertID)
+	if alertID == "" {
+		alertID = alert.AnalysisID + ":" + alert.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	bucket := 0
+	if retryBucketSize > 0 {
+		bucket = alert.RetryCount / retryBucketSize
+	}
+	return alertID + ":" + outputID + ":" + strconv.Itoa(bucket)
+}
+
+// alreadyDelivered returns true if the alert was successfully sent to the output within the retry window.
+//
+// Errors are logged and treated as not delivered: a duplicate alert is better than a missing one.
+func alreadyDelivered(alert *alertmodels.Alert, outputID string) bool {
+	if deliveriesTable == "" {
+		return false
+	}
+
+	key := idempotencyKey(alert, outputID)
+	response, err := getDDBClient().GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{"key": {S: aws.String(key)}},
+		TableName:      aws.String(deliveriesTable),
+	})
+	if err != nil {
+		zap.L().Warn("failed to check previous alert deliveries", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	if response.Item == nil || response.Item["expiresAt"] == nil {
+		return false
+	}
+
+	// Expired items can remain in the table until the TTL process removes them
+	expiresAt, err := strconv.ParseInt(aws.StringValue(response.Item["expiresAt"].N), 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < expiresAt
+}
+
+// recordDelivery marks the alert as successfully sent to the output for the duration of the retry window.
+func recordDelivery(alert *alertmodels.Alert, outputID string) {
+	if deliveriesTable == "" {
+		return
+	}
+
+	key := idempotencyKey(alert, outputID)
+	expiresAt := time.Now().Add(getMaxRetryDuration()).Unix()
+	_, err := getDDBClient().PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"key":       {S: aws.String(key)},
+			"expiresAt": {N: aws.String(strconv.FormatInt(expiresAt, 10))},
+		},
+		TableName: aws.String(deliveriesTable),
+	})
+	if err != nil {
+		zap.L().Warn("failed to record alert delivery", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,195 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func setDeliveriesTable(t *testing.T) *testutils.DynamoDBMock {
+	deliveriesTable = "panther-alert-deliveries"
+	mockDDB := &testutils.DynamoDBMock{}
+	ddbClient = mockDDB
+	t.Cleanup(func() { deliveriesTable = "" })
+	return mockDDB
+}
+
+func deliveryItem(expiresAt time.Time) *dynamodb.GetItemOutput {
+	return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"key":       {S: aws.String("key")},
+		"expiresAt": {N: aws.String(strconv.FormatInt(expiresAt.Unix(), 10))},
+	}}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	alert := sampleAlert()
+	alert.CreatedAt = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "test-rule-id:2020-01-02T03:04:05Z:output-id:0", idempotencyKey(alert, "output-id"))
+
+	alert.AlertID = aws.String("alert-id")
+	assert.Equal(t, "alert-id:output-id:0", idempotencyKey(alert, "output-id"))
+}
+
+func TestIdempotencyKeyRetryBucket(t *testing.T) {
+	defer func(size int) { retryBucketSize = size }(retryBucketSize)
+	retryBucketSize = 3
+	alert := sampleAlert()
+	alert.AlertID = aws.String("alert-id")
+
+	// Retries in the same bucket share the key
+	alert.RetryCount = 2
+	assert.Equal(t, "alert-id:output-id:0", idempotencyKey(alert, "output-id"))
+	alert.RetryCount = 3
+	assert.Equal(t, "alert-id:output-id:1", idempotencyKey(alert, "output-id"))
+
+	retryBucketSize = 0
+	assert.Equal(t, "alert-id:output-id:0", idempotencyKey(alert, "output-id"))
+}
+
+func TestAlreadyDeliveredNoTable(t *testing.T) {
+	mockDDB := &testutils.DynamoDBMock{}
+	ddbClient = mockDDB
+	assert.False(t, alreadyDelivered(sampleAlert(), "output-id"))
+	mockDDB.AssertExpectations(t)
+}
+
+func TestAlreadyDelivered(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(deliveryItem(time.Now().Add(time.Hour)), nil).Once()
+	assert.True(t, alreadyDelivered(sampleAlert(), "output-id"))
+	mockDDB.AssertExpectations(t)
+}
+
+func TestAlreadyDeliveredExpired(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(deliveryItem(time.Now().Add(-time.Hour)), nil).Once()
+	assert.False(t, alreadyDelivered(sampleAlert(), "output-id"))
+	mockDDB.AssertExpectations(t)
+}
+
+func TestAlreadyDeliveredNotFound(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	assert.False(t, alreadyDelivered(sampleAlert(), "output-id"))
+	mockDDB.AssertExpectations(t)
+}
+
+func TestAlreadyDeliveredError(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return((*dynamodb.GetItemOutput)(nil), errors.New("throttled")).Once()
+	assert.False(t, alreadyDelivered(sampleAlert(), "output-id"))
+	mockDDB.AssertExpectations(t)
+}
+
+func TestSendAlreadyDelivered(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(deliveryItem(time.Now().Add(time.Hour)), nil).Once()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	ch := make(chan outputStatus, 1)
+
+	send(sampleAlert(), alertOutput, ch)
+	assert.Equal(t, outputStatus{outputID: *alertOutput.OutputID, alreadyDelivered: true}, <-ch)
+	mockClient.AssertExpectations(t) // the output is never called
+	mockDDB.AssertExpectations(t)
+}
+
+func TestSendRecordsDelivery(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockDDB.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return((*outputs.AlertDeliveryError)(nil))
+	os.Setenv("ALERT_RETRY_DURATION_MINS", "5")
+	ch := make(chan outputStatus, 1)
+
+	send(sampleAlert(), alertOutput, ch)
+	assert.Equal(t, outputStatus{outputID: *alertOutput.OutputID, success: true}, <-ch)
+	mockClient.AssertExpectations(t)
+	mockDDB.AssertExpectations(t)
+}
+
+func TestDispatchAlreadyDelivered(t *testing.T) {
+	mockDDB := setDeliveriesTable(t)
+	mockDDB.On("GetItem", mock.Anything).Return(deliveryItem(time.Now().Add(time.Hour)), nil).Once()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+
+	alert := sampleAlert()
+	assert.True(t, dispatch(alert))
+	assert.Equal(t, []string{"output-id"}, alert.OutputIds)
+	mockClient.AssertExpectations(t)
+}
+
+// SQS redelivers the whole batch if the lambda fails part way through:
+// only the outputs which were not delivered before are attempted again.
+func TestDispatchRedeliveryResumesPendingOutputs(t *testing.T) {
+	alert := sampleAlert()
+	alert.OutputIds = []string{"output-id", "sns-id"}
+
+	mockDDB := setDeliveriesTable(t)
+	deliveredKey := func(outputID string) interface{} {
+		return mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+			return *input.Key["key"].S == idempotencyKey(alert, outputID)
+		})
+	}
+	mockDDB.On("GetItem", deliveredKey("output-id")).Return(deliveryItem(time.Now().Add(time.Hour)), nil).Once()
+	mockDDB.On("GetItem", deliveredKey("sns-id")).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockDDB.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Sns", mock.Anything, mock.Anything).Return("sns-message-id", (*outputs.AlertDeliveryError)(nil)).Once()
+	os.Setenv("ALERT_RETRY_DURATION_MINS", "5")
+
+	setCaches()
+	snsOutput := &outputmodels.AlertOutput{
+		OutputType:  aws.String("sns"),
+		DisplayName: aws.String("sns:alerts"),
+		OutputConfig: &outputmodels.OutputConfig{
+			Sns: &outputmodels.SnsConfig{TopicArn: "arn:aws:sns:us-west-2:123456789012:alerts"},
+		},
+		OutputID: aws.String("sns-id"),
+	}
+	cache.Outputs = append(cache.Outputs, snsOutput)
+
+	results, deliveries := dispatchBatchResults([]*alertmodels.Alert{alert})
+	assert.Equal(t, []bool{true}, results)
+	assert.ElementsMatch(t, []deliveryResult{
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "output-id", alreadyDelivered: true}},
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "sns-id", success: true, messageID: "sns-message-id"}},
+	}, deliveries)
+	mockClient.AssertExpectations(t) // slack is never called
+	mockDDB.AssertExpectations(t)
+}
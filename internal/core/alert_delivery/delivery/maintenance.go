@@ -0,0 +1,136 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// maintenanceWindow is a period during which alerts are recorded but not sent to their outputs.
+//
+// One-off windows have a start and an end, e.g. {"start": "2020-07-04T22:00:00Z", "end": "2020-07-05T02:00:00Z"}
+// Recurring windows open at startTime (UTC) every day, or only on the given days, and stay open for durationMins,
+// e.g. {"days": ["Saturday", "Sunday"], "startTime": "22:00", "durationMins": 240}
+type maintenanceWindow struct {
+	Start        *time.Time `json:"start,omitempty"`
+	End          *time.Time `json:"end,omitempty"`
+	Days         []string   `json:"days,omitempty"`
+	StartTime    string     `json:"startTime,omitempty"`
+	DurationMins int        `json:"durationMins,omitempty"`
+}
+
+const maintenanceStartTimeLayout = "15:04"
+
+func (w *maintenanceWindow) validate() error {
+	if w.Start != nil || w.End != nil {
+		if w.Start == nil || w.End == nil || !w.End.After(*w.Start) {
+			return errors.New("one-off maintenance windows need a start before their end")
+		}
+		return nil
+	}
+	if _, err := time.Parse(maintenanceStartTimeLayout, w.StartTime); err != nil {
+		return errors.New("recurring maintenance windows need a startTime formatted as HH:MM")
+	}
+	if w.DurationMins <= 0 {
+		return errors.New("recurring maintenance windows need a positive durationMins")
+	}
+	for _, day := range w.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return errors.New("unknown maintenance window day " + day)
+		}
+	}
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// Returns true if the recurring window opens on the given day
+func (w *maintenanceWindow) opensOn(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if day, _ := parseWeekday(name); day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the window is open at the given time
+func (w *maintenanceWindow) active(now time.Time) bool {
+	if w.Start != nil {
+		return !now.Before(*w.Start) && now.Before(*w.End)
+	}
+
+	now = now.UTC()
+	clock, _ := time.Parse(maintenanceStartTimeLayout, w.StartTime) // validated when the windows are loaded
+	duration := time.Duration(w.DurationMins) * time.Minute
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Long windows which opened on one of the previous days may still be open
+	for daysAgo := 0; daysAgo <= int(duration/(24*time.Hour))+1; daysAgo++ {
+		start := midnight.AddDate(0, 0, -daysAgo).Add(
+			time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+		if w.opensOn(start.Weekday()) && !now.Before(start) && now.Before(start.Add(duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// The maintenance windows, e.g. [{"days": ["Saturday"], "startTime": "22:00", "durationMins": 240}]
+func getMaintenanceWindows() []*maintenanceWindow {
+	var result []*maintenanceWindow
+	if config := os.Getenv("ALERT_MAINTENANCE_WINDOWS"); config != "" {
+		if err := jsoniter.UnmarshalFromString(config, &result); err != nil {
+			panic(err)
+		}
+	}
+	for _, window := range result {
+		if err := window.validate(); err != nil {
+			panic(err)
+		}
+	}
+	return result
+}
+
+var maintenanceWindows = getMaintenanceWindows()
+
+// Returns true if alerts raised at the given time should not be sent to their outputs.
+func inMaintenance(now time.Time) bool {
+	for _, window := range maintenanceWindows {
+		if window.active(now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+// Replace the maintenance windows for the duration of the test
+func setMaintenanceWindows(t *testing.T, windows ...*maintenanceWindow) {
+	previousWindows := maintenanceWindows
+	t.Cleanup(func() { maintenanceWindows = previousWindows })
+	maintenanceWindows = windows
+}
+
+func TestMaintenanceWindowOneOff(t *testing.T) {
+	start := time.Date(2020, 7, 4, 22, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+	window := &maintenanceWindow{Start: &start, End: &end}
+	require.NoError(t, window.validate())
+
+	assert.False(t, window.active(start.Add(-time.Second)))
+	assert.True(t, window.active(start))
+	assert.True(t, window.active(start.Add(2*time.Hour)))
+	assert.False(t, window.active(end))
+}
+
+func TestMaintenanceWindowRecurring(t *testing.T) {
+	// Every Saturday from 22:00 until 02:00 on Sunday
+	window := &maintenanceWindow{Days: []string{"saturday"}, StartTime: "22:00", DurationMins: 240}
+	require.NoError(t, window.validate())
+
+	saturday := time.Date(2020, 7, 4, 0, 0, 0, 0, time.UTC)
+	assert.False(t, window.active(saturday.Add(21*time.Hour+59*time.Minute)))
+	assert.True(t, window.active(saturday.Add(22*time.Hour)))
+	assert.True(t, window.active(saturday.Add(25*time.Hour+59*time.Minute))) // Sunday 01:59
+	assert.False(t, window.active(saturday.Add(26*time.Hour)))               // Sunday 02:00
+	assert.False(t, window.active(saturday.Add(46*time.Hour)))               // Sunday 22:00
+	assert.True(t, window.active(saturday.AddDate(0, 0, 7).Add(23*time.Hour)))
+
+	// Times in other zones are compared in UTC
+	pacific := time.FixedZone("PDT", -7*60*60)
+	assert.True(t, window.active(time.Date(2020, 7, 4, 16, 0, 0, 0, pacific)))
+}
+
+func TestMaintenanceWindowDaily(t *testing.T) {
+	window := &maintenanceWindow{StartTime: "09:30", DurationMins: 30}
+	require.NoError(t, window.validate())
+
+	for day := 0; day < 7; day++ {
+		midnight := time.Date(2020, 7, 1+day, 0, 0, 0, 0, time.UTC)
+		assert.True(t, window.active(midnight.Add(9*time.Hour+45*time.Minute)))
+		assert.False(t, window.active(midnight.Add(10*time.Hour)))
+	}
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start := time.Date(2020, 7, 4, 22, 0, 0, 0, time.UTC)
+	assert.Error(t, (&maintenanceWindow{Start: &start}).validate())
+	assert.Error(t, (&maintenanceWindow{Start: &start, End: &start}).validate())
+	assert.Error(t, (&maintenanceWindow{StartTime: "10pm", DurationMins: 60}).validate())
+	assert.Error(t, (&maintenanceWindow{StartTime: "22:00"}).validate())
+	assert.Error(t, (&maintenanceWindow{Days: []string{"Caturday"}, StartTime: "22:00", DurationMins: 60}).validate())
+}
+
+func TestGetMaintenanceWindows(t *testing.T) {
+	defer os.Unsetenv("ALERT_MAINTENANCE_WINDOWS")
+
+	assert.Empty(t, getMaintenanceWindows())
+
+	os.Setenv("ALERT_MAINTENANCE_WINDOWS",
+		`[{"start": "2020-07-04T22:00:00Z", "end": "2020-07-05T02:00:00Z"}, {"startTime": "22:00", "durationMins": 60}]`)
+	windows := getMaintenanceWindows()
+	require.Len(t, windows, 2)
+	assert.Equal(t, time.Date(2020, 7, 5, 2, 0, 0, 0, time.UTC), windows[0].End.UTC())
+	assert.Equal(t, "22:00", windows[1].StartTime)
+
+	os.Setenv("ALERT_MAINTENANCE_WINDOWS", `[{"startTime": "22:00"}]`)
+	assert.Panics(t, func() { getMaintenanceWindows() })
+}
+
+// An open one-off window around the current time
+func openMaintenanceWindow() *maintenanceWindow {
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	return &maintenanceWindow{Start: &start, End: &end}
+}
+
+func TestDispatchInMaintenance(t *testing.T) {
+	setMaintenanceWindows(t, openMaintenanceWindow())
+	setCaches()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+
+	alerts := []*alertmodels.Alert{sampleAlert()}
+	results, deliveries := dispatchBatchResults(alerts)
+	assert.Equal(t, []bool{true}, results)
+	assert.Equal(t, []deliveryResult{
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "output-id", suppressed: true}},
+	}, deliveries)
+	mockClient.AssertNotCalled(t, "Slack", mock.Anything, mock.Anything)
+}
+
+func TestDispatchInMaintenanceIgnored(t *testing.T) {
+	setMaintenanceWindows(t, openMaintenanceWindow())
+	setCaches()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, alertOutput.OutputConfig.Slack).
+		Return((*outputs.AlertDeliveryError)(nil)).Once()
+
+	alert := sampleAlert()
+	alert.IgnoreMaintenance = true
+	assert.True(t, dispatch(alert))
+	mockClient.AssertExpectations(t)
+}
+
+func TestDispatchOutsideMaintenance(t *testing.T) {
+	start, end := time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	setMaintenanceWindows(t, &maintenanceWindow{Start: &start, End: &end})
+	setCaches()
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, alertOutput.OutputConfig.Slack).
+		Return((*outputs.AlertDeliveryError)(nil)).Once()
+
+	results, deliveries := dispatchBatchResults([]*alertmodels.Alert{sampleAlert()})
+	assert.Equal(t, []bool{true}, results)
+	require.Len(t, deliveries, 1)
+	assert.True(t, deliveries[0].status.success)
+	mockClient.AssertExpectations(t)
+}
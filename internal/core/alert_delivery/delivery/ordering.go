@@ -0,0 +1,85 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"sort"
+
+	jsoniter "github.com/json-iterator/go"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// The IDs of the outputs which receive alerts one at a time in creation order, e.g. ["output-id"]
+func getOrderedOutputs() map[string]bool {
+	var outputIDs []string
+	if config := os.Getenv("ALERT_ORDERED_OUTPUTS"); config != "" {
+		if err := jsoniter.UnmarshalFromString(config, &outputIDs); err != nil {
+			panic(err)
+		}
+	}
+	result := make(map[string]bool, len(outputIDs))
+	for _, outputID := range outputIDs {
+		result[outputID] = true
+	}
+	return result
+}
+
+// Deliveries to these outputs are serialized, so incidents show up in the order the alerts were raised.
+// Other outputs are sent alerts in parallel, in no particular order.
+var orderedOutputs = getOrderedOutputs()
+
+// groupJobs splits the jobs into groups which are each sent by a single worker, one job after the other.
+//
+// All the jobs of an ordered output are in the same group, sorted by the CreatedAt of their (first) alert.
+// Every other job is a group of its own. Groups are returned in the order of their first job.
+func groupJobs(jobs []deliveryJob, alerts []*alertmodels.Alert) [][]deliveryJob {
+	var groups [][]deliveryJob
+	// output ID -> index of its group
+	orderedGroups := make(map[string]int)
+	for _, job := range jobs {
+		outputID := *job.output.OutputID
+		if !orderedOutputs[outputID] {
+			groups = append(groups, []deliveryJob{job})
+			continue
+		}
+		if i, ok := orderedGroups[outputID]; ok {
+			groups[i] = append(groups[i], job)
+			continue
+		}
+		orderedGroups[outputID] = len(groups)
+		groups = append(groups, []deliveryJob{job})
+	}
+
+	createdAt := func(job deliveryJob) int64 {
+		return alerts[job.alertIndexes[0]].CreatedAt.UnixNano()
+	}
+	for _, i := range orderedGroups {
+		group := groups[i]
+		for _, job := range group {
+			// Alerts in a digest are listed in creation order too
+			sort.SliceStable(job.alertIndexes, func(a, b int) bool {
+				return alerts[job.alertIndexes[a]].CreatedAt.Before(alerts[job.alertIndexes[b]].CreatedAt)
+			})
+		}
+		sort.SliceStable(group, func(a, b int) bool { return createdAt(group[a]) < createdAt(group[b]) })
+	}
+	return groups
+}
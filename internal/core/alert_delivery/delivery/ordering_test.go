@@ -0,0 +1,132 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+func setOrderedOutputs(t *testing.T, outputIDs ...string) {
+	previous := orderedOutputs
+	orderedOutputs = make(map[string]bool)
+	for _, outputID := range outputIDs {
+		orderedOutputs[outputID] = true
+	}
+	t.Cleanup(func() { orderedOutputs = previous })
+}
+
+func TestGetOrderedOutputs(t *testing.T) {
+	defer os.Unsetenv("ALERT_ORDERED_OUTPUTS")
+
+	os.Unsetenv("ALERT_ORDERED_OUTPUTS")
+	assert.Empty(t, getOrderedOutputs())
+	os.Setenv("ALERT_ORDERED_OUTPUTS", `["output-1", "output-2"]`)
+	assert.Equal(t, map[string]bool{"output-1": true, "output-2": true}, getOrderedOutputs())
+	os.Setenv("ALERT_ORDERED_OUTPUTS", `{"output-1": true}`)
+	assert.Panics(t, func() { getOrderedOutputs() })
+}
+
+func TestGroupJobs(t *testing.T) {
+	setOrderedOutputs(t, "output-id")
+	webhookOutput := &outputmodels.AlertOutput{OutputType: aws.String("customwebhook"), OutputID: aws.String("webhook-id")}
+	now := time.Now()
+	alerts := []*alertmodels.Alert{
+		{CreatedAt: now.Add(2 * time.Minute)},
+		{CreatedAt: now},
+		{CreatedAt: now.Add(time.Minute)},
+	}
+	jobs := []deliveryJob{
+		{alertIndexes: []int{0}, output: alertOutput},
+		{alertIndexes: []int{0}, output: webhookOutput},
+		{alertIndexes: []int{1}, output: alertOutput},
+		{alertIndexes: []int{1}, output: webhookOutput},
+		{alertIndexes: []int{2}, output: alertOutput},
+	}
+
+	assert.Equal(t, [][]deliveryJob{
+		{
+			{alertIndexes: []int{1}, output: alertOutput},
+			{alertIndexes: []int{2}, output: alertOutput},
+			{alertIndexes: []int{0}, output: alertOutput},
+		},
+		{{alertIndexes: []int{0}, output: webhookOutput}},
+		{{alertIndexes: []int{1}, output: webhookOutput}},
+	}, groupJobs(jobs, alerts))
+
+	// Digests are sorted as well
+	digest := []deliveryJob{{alertIndexes: []int{0, 1, 2}, output: alertOutput}}
+	assert.Equal(t, [][]deliveryJob{{{alertIndexes: []int{1, 2, 0}, output: alertOutput}}}, groupJobs(digest, alerts))
+}
+
+func TestGroupJobsUnordered(t *testing.T) {
+	setOrderedOutputs(t)
+	alerts := []*alertmodels.Alert{sampleAlert(), sampleAlert()}
+	jobs := []deliveryJob{
+		{alertIndexes: []int{0}, output: alertOutput},
+		{alertIndexes: []int{1}, output: alertOutput},
+	}
+	assert.Equal(t, [][]deliveryJob{{jobs[0]}, {jobs[1]}}, groupJobs(jobs, alerts))
+}
+
+func TestDispatchBatchOrdered(t *testing.T) {
+	defer func(concurrency int) { maxConcurrentSends = concurrency }(maxConcurrentSends)
+	maxConcurrentSends = 10
+	setOrderedOutputs(t, "output-id")
+
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+
+	now := time.Now().UTC()
+	var alerts []*alertmodels.Alert
+	for _, minutes := range []int{5, 1, 4, 2, 3, 0} {
+		alert := sampleAlert()
+		alert.CreatedAt = now.Add(time.Duration(minutes) * time.Minute)
+		alerts = append(alerts, alert)
+	}
+
+	var mu sync.Mutex
+	var sent []time.Time
+	mockClient.On("Slack", mock.Anything, alertOutput.OutputConfig.Slack).Run(func(args mock.Arguments) {
+		// Give later alerts a chance to overtake this one if deliveries were parallel
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, args.Get(0).(*alertmodels.Alert).CreatedAt)
+	}).Return((*outputs.AlertDeliveryError)(nil)).Times(len(alerts))
+
+	assert.Equal(t, []bool{true, true, true, true, true, true}, dispatchBatch(alerts))
+	mockClient.AssertExpectations(t)
+	expected := make([]time.Time, len(alerts))
+	for i := range expected {
+		expected[i] = now.Add(time.Duration(i) * time.Minute)
+	}
+	assert.Equal(t, expected, sent)
+}
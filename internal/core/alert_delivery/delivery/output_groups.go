@@ -0,0 +1,76 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+)
+
+// Alerts refer to an output group in place of output IDs with this prefix, e.g. "group:cloud-sec"
+const outputGroupPrefix = "group:"
+
+// The output IDs of each named output group, e.g. {"cloud-sec": ["<slack output id>", "<pagerduty output id>"]}
+func getOutputGroups() map[string][]string {
+	result := make(map[string][]string)
+	if config := os.Getenv("ALERT_OUTPUT_GROUPS"); config != "" {
+		if err := jsoniter.UnmarshalFromString(config, &result); err != nil {
+			panic(err)
+		}
+	}
+	return result
+}
+
+var outputGroups = getOutputGroups()
+
+// expandOutputGroups replaces the output groups in a list of output IDs with the IDs of their outputs.
+//
+// Each output ID is listed once, in the order it first appears, even if groups overlap.
+// Unknown groups are logged and skipped: retrying can't fix the configuration, and the alert
+// should still reach the rest of its outputs.
+func expandOutputGroups(outputIDs []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(outputIDs))
+	add := func(outputID string) {
+		if !seen[outputID] {
+			seen[outputID] = true
+			result = append(result, outputID)
+		}
+	}
+
+	for _, outputID := range outputIDs {
+		if !strings.HasPrefix(outputID, outputGroupPrefix) {
+			add(outputID)
+			continue
+		}
+		name := strings.TrimPrefix(outputID, outputGroupPrefix)
+		group, ok := outputGroups[name]
+		if !ok {
+			zap.L().Error("unknown output group, skipping", zap.String("group", name))
+			continue
+		}
+		for _, groupOutputID := range group {
+			add(groupOutputID)
+		}
+	}
+	return result
+}
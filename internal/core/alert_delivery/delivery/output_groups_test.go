@@ -0,0 +1,93 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func setOutputGroups(t *testing.T, groups map[string][]string) {
+	previous := outputGroups
+	outputGroups = groups
+	t.Cleanup(func() { outputGroups = previous })
+}
+
+func TestGetOutputGroups(t *testing.T) {
+	defer os.Unsetenv("ALERT_OUTPUT_GROUPS")
+
+	os.Unsetenv("ALERT_OUTPUT_GROUPS")
+	assert.Empty(t, getOutputGroups())
+	os.Setenv("ALERT_OUTPUT_GROUPS", `{"cloud-sec": ["slack-id", "pd-id"]}`)
+	assert.Equal(t, map[string][]string{"cloud-sec": {"slack-id", "pd-id"}}, getOutputGroups())
+	os.Setenv("ALERT_OUTPUT_GROUPS", `["slack-id"]`)
+	assert.Panics(t, func() { getOutputGroups() })
+}
+
+func TestExpandOutputGroups(t *testing.T) {
+	setOutputGroups(t, map[string][]string{
+		"cloud-sec": {"slack-id", "pd-id"},
+		"on-call":   {"pd-id", "opsgenie-id"},
+	})
+
+	assert.Equal(t, []string{"slack-id", "pd-id", "webhook-id"}, expandOutputGroups([]string{"group:cloud-sec", "webhook-id"}))
+
+	// Overlapping groups and IDs are listed once
+	assert.Equal(t, []string{"slack-id", "pd-id", "opsgenie-id"},
+		expandOutputGroups([]string{"slack-id", "group:cloud-sec", "group:on-call", "group:cloud-sec"}))
+
+	// Unknown groups are skipped, the other outputs are kept
+	assert.Equal(t, []string{"slack-id", "pd-id"}, expandOutputGroups([]string{"slack-id", "group:unknown", "pd-id"}))
+
+	assert.Empty(t, expandOutputGroups(nil))
+}
+
+func TestGetAlertOutputsGroups(t *testing.T) {
+	setOutputGroups(t, map[string][]string{"cloud-sec": {"output-id", "output-id-2"}})
+	cache = &outputsCache{
+		Outputs: []*outputmodels.AlertOutput{
+			{OutputID: aws.String("output-id")},
+			{OutputID: aws.String("output-id-2")},
+			{OutputID: aws.String("output-id-3")},
+		},
+		Timestamp: time.Now(),
+	}
+	alert := sampleAlert()
+
+	alert.OutputIds = []string{"group:cloud-sec", "output-id"}
+	result, err := getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "output-id", *result[0].OutputID)
+	assert.Equal(t, "output-id-2", *result[1].OutputID)
+
+	// The outputs listed next to an unknown group are still returned
+	alert.OutputIds = []string{"group:missing", "output-id-3"}
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "output-id-3", *result[0].OutputID)
+}
@@ -69,11 +69,17 @@ func getAlertOutputs(alert *alertmodels.Alert) ([]*outputmodels.AlertOutput, err
 		return getOutputsBySeverity(alert.Severity), nil
 	}
 
+	// Output groups are expanded to the IDs of their outputs, see outputGroups
+	outputIDs := expandOutputGroups(alert.OutputIds)
+
+	// Each output is returned at most once, even if its ID is listed more than once,
+	// so the alert is never sent twice to the same destination.
 	result := []*outputmodels.AlertOutput{}
 	for _, output := range cache.Outputs {
-		for _, alertOutputID := range alert.OutputIds {
+		for _, alertOutputID := range outputIDs {
 			if *output.OutputID == alertOutputID {
 				result = append(result, output)
+				break
 			}
 		}
 	}
@@ -90,6 +96,7 @@ func getOutputsBySeverity(severity string) []*outputmodels.AlertOutput {
 		for _, outputSeverity := range output.DefaultForSeverity {
 			if severity == *outputSeverity {
 				result = append(result, output)
+				break
 			}
 		}
 	}
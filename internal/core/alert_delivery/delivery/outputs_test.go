@@ -21,6 +21,7 @@ package delivery
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
@@ -120,6 +121,46 @@ func TestGetAlertOutputsFromOutputIds(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestGetAlertOutputsDeduplicated(t *testing.T) {
+	cache = &outputsCache{
+		Outputs: []*outputmodels.AlertOutput{
+			{
+				OutputID:           aws.String("output-id"),
+				DefaultForSeverity: aws.StringSlice([]string{"INFO", "INFO"}),
+			},
+			{
+				OutputID:           aws.String("output-id-2"),
+				DefaultForSeverity: aws.StringSlice([]string{"INFO"}),
+			},
+		},
+		Timestamp: time.Now(),
+	}
+	alert := sampleAlert()
+
+	// Partial overlap
+	alert.OutputIds = []string{"output-id", "output-id-2", "output-id"}
+	result, err := getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "output-id", *result[0].OutputID)
+	assert.Equal(t, "output-id-2", *result[1].OutputID)
+
+	// Full overlap
+	alert.OutputIds = []string{"output-id-2", "output-id-2"}
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "output-id-2", *result[0].OutputID)
+
+	// Default outputs listing the severity more than once
+	alert.OutputIds = nil
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "output-id", *result[0].OutputID)
+	assert.Equal(t, "output-id-2", *result[1].OutputID)
+}
+
 func TestGetAlertOutputsIdsError(t *testing.T) {
 	mockClient := &mockLambdaClient{}
 	lambdaClient = mockClient
@@ -0,0 +1,219 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+)
+
+// Delivery status values in the status callback
+const (
+	deliveryStatusSuccess          = "SUCCESS"
+	deliveryStatusFailed           = "FAILED"
+	deliveryStatusRetry            = "RETRY"
+	deliveryStatusTimedOut         = "TIMED_OUT"
+	deliveryStatusAlreadyDelivered = "ALREADY_DELIVERED"
+	deliveryStatusSuppressed       = "SUPPRESSED_MAINTENANCE"
+)
+
+func getStatusCallbackTimeout() time.Duration {
+	timeout := os.Getenv("ALERT_STATUS_CALLBACK_TIMEOUT_SECS")
+	if timeout == "" {
+		return 5 * time.Second
+	}
+	return time.Duration(mustParseInt(timeout)) * time.Second
+}
+
+var (
+	// If set, a summary of the delivery results of every batch of alerts is posted to this URL
+	statusCallbackURL     = os.Getenv("ALERT_STATUS_CALLBACK_URL")
+	statusCallbackTimeout = getStatusCallbackTimeout()
+
+	statusCallbackClient outputs.HTTPiface = &http.Client{}
+)
+
+// statusReport is the body posted to the status callback
+type statusReport struct {
+	// Summarizes the status codes of all the alerts, see aggregateStatusCode
+	StatusCode int                  `json:"statusCode"`
+	Alerts     []*alertStatusReport `json:"alerts"`
+}
+
+type alertStatusReport struct {
+	AlertID    *string `json:"alertId,omitempty"`
+	AnalysisID string  `json:"analysisId"`
+	Severity   string  `json:"severity"`
+	// False if the alert will be retried
+	Delivered bool `json:"delivered"`
+	// Summarizes the status of all the outputs, see aggregateStatusCode
+	StatusCode int                   `json:"statusCode"`
+	Outputs    []*outputStatusReport `json:"outputs"`
+}
+
+type outputStatusReport struct {
+	OutputID   string `json:"outputId"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	// The ID the output assigned to the message, e.g. the SES message ID of emails
+	MessageID string `json:"messageId,omitempty"`
+}
+
+// buildStatusReport summarizes the result of every output of every alert in the batch
+func buildStatusReport(alerts []*alertmodels.Alert, results []bool, deliveries []deliveryResult) *statusReport {
+	report := &statusReport{Alerts: make([]*alertStatusReport, len(alerts))}
+	for i, alert := range alerts {
+		report.Alerts[i] = &alertStatusReport{
+			AlertID:    alert.AlertID,
+			AnalysisID: alert.AnalysisID,
+			Severity:   alert.Severity,
+			Delivered:  results[i],
+			Outputs:    []*outputStatusReport{},
+		}
+	}
+
+	for _, delivery := range deliveries {
+		output := &outputStatusReport{
+			OutputID:   delivery.status.outputID,
+			Status:     deliveryStatus(delivery.status),
+			StatusCode: delivery.status.statusCode,
+			MessageID:  delivery.status.messageID,
+		}
+		for _, i := range delivery.alertIndexes {
+			report.Alerts[i].Outputs = append(report.Alerts[i].Outputs, output)
+		}
+	}
+
+	var alertsSucceeded, alertsFailed int
+	for _, alert := range report.Alerts {
+		var succeeded, failed int
+		for _, output := range alert.Outputs {
+			if output.succeeded() {
+				succeeded++
+			} else {
+				failed++
+			}
+		}
+		alert.StatusCode = aggregateStatusCode(succeeded, failed)
+		if !alert.Delivered && len(alert.Outputs) == 0 {
+			// The alert was not sent anywhere, e.g. its outputs could not be loaded
+			alert.StatusCode = http.StatusBadGateway
+		}
+
+		switch alert.StatusCode {
+		case http.StatusOK:
+			alertsSucceeded++
+		case http.StatusBadGateway:
+			alertsFailed++
+		default: // partial success
+			alertsSucceeded++
+			alertsFailed++
+		}
+	}
+	report.StatusCode = aggregateStatusCode(alertsSucceeded, alertsFailed)
+	return report
+}
+
+// Returns false if the alert still has to be sent to the output, or could not be sent to it at all.
+// Alerts which were suppressed during a maintenance window did not fail.
+func (output *outputStatusReport) succeeded() bool {
+	switch output.Status {
+	case deliveryStatusSuccess, deliveryStatusAlreadyDelivered, deliveryStatusSuppressed:
+		return true
+	default:
+		return false
+	}
+}
+
+// aggregateStatusCode summarizes several results in a single HTTP-style status code:
+//
+// 200 (OK) if none failed, including when there are no results at all
+// 207 (Multi-Status) if some succeeded and some failed
+// 502 (Bad Gateway) if all failed
+func aggregateStatusCode(succeeded, failed int) int {
+	switch {
+	case failed == 0:
+		return http.StatusOK
+	case succeeded == 0:
+		return http.StatusBadGateway
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
+func deliveryStatus(status outputStatus) string {
+	switch {
+	case status.suppressed:
+		return deliveryStatusSuppressed
+	case status.alreadyDelivered:
+		return deliveryStatusAlreadyDelivered
+	case status.success:
+		return deliveryStatusSuccess
+	case status.timedOut:
+		return deliveryStatusTimedOut
+	case status.needsRetry:
+		return deliveryStatusRetry
+	default:
+		return deliveryStatusFailed
+	}
+}
+
+// sendStatusCallback posts the delivery results to the status callback URL, if one is configured.
+//
+// This is only for the customer's own tracking: failures are logged, but never change the delivery result.
+func sendStatusCallback(alerts []*alertmodels.Alert, results []bool, deliveries []deliveryResult) {
+	if statusCallbackURL == "" || len(alerts) == 0 {
+		return
+	}
+
+	body, err := jsoniter.Marshal(buildStatusReport(alerts, results, deliveries))
+	if err != nil {
+		zap.L().Error("failed to marshal delivery status callback", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusCallbackTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, "POST", statusCallbackURL, bytes.NewReader(body))
+	if err != nil {
+		zap.L().Error("failed to build delivery status callback request", zap.Error(err))
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := statusCallbackClient.Do(request)
+	if err != nil {
+		zap.L().Warn("failed to send delivery status callback", zap.Error(err))
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		zap.L().Warn("delivery status callback was rejected", zap.Int("statusCode", response.StatusCode))
+	}
+}
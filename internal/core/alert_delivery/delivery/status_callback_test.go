@@ -0,0 +1,220 @@
+package delivery
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+func statusCallbackAlerts() []*alertmodels.Alert {
+	return []*alertmodels.Alert{
+		{AlertID: aws.String("alert-1"), AnalysisID: "rule-1", Severity: "HIGH"},
+		{AlertID: aws.String("alert-2"), AnalysisID: "rule-2", Severity: "LOW"},
+	}
+}
+
+func statusCallbackDeliveries() []deliveryResult {
+	return []deliveryResult{
+		{alertIndexes: []int{0, 1}, status: outputStatus{outputID: "slack", success: true}},
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "webhook", needsRetry: true, statusCode: 503}},
+		{alertIndexes: []int{1}, status: outputStatus{outputID: "webhook", needsRetry: true, timedOut: true}},
+		{alertIndexes: []int{1}, status: outputStatus{outputID: "sns", alreadyDelivered: true}},
+	}
+}
+
+func TestBuildStatusReport(t *testing.T) {
+	report := buildStatusReport(statusCallbackAlerts(), []bool{false, false}, statusCallbackDeliveries())
+
+	assert.Equal(t, &statusReport{StatusCode: http.StatusMultiStatus, Alerts: []*alertStatusReport{
+		{
+			AlertID:    aws.String("alert-1"),
+			AnalysisID: "rule-1",
+			Severity:   "HIGH",
+			StatusCode: http.StatusMultiStatus,
+			Outputs: []*outputStatusReport{
+				{OutputID: "slack", Status: deliveryStatusSuccess},
+				{OutputID: "webhook", Status: deliveryStatusRetry, StatusCode: 503},
+			},
+		},
+		{
+			AlertID:    aws.String("alert-2"),
+			AnalysisID: "rule-2",
+			Severity:   "LOW",
+			StatusCode: http.StatusMultiStatus,
+			Outputs: []*outputStatusReport{
+				{OutputID: "slack", Status: deliveryStatusSuccess},
+				{OutputID: "webhook", Status: deliveryStatusTimedOut},
+				{OutputID: "sns", Status: deliveryStatusAlreadyDelivered},
+			},
+		},
+	}}, report)
+}
+
+func TestBuildStatusReportMessageID(t *testing.T) {
+	report := buildStatusReport(statusCallbackAlerts()[:1], []bool{true}, []deliveryResult{
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "email", success: true, messageID: "ses-message-id"}},
+	})
+	assert.Equal(t, []*outputStatusReport{
+		{OutputID: "email", Status: deliveryStatusSuccess, MessageID: "ses-message-id"},
+	}, report.Alerts[0].Outputs)
+}
+
+func TestBuildStatusReportNotDeliveredWithoutOutputs(t *testing.T) {
+	alerts := statusCallbackAlerts()
+	report := buildStatusReport(alerts, []bool{true, false}, []deliveryResult{
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "slack", success: true}},
+	})
+	assert.Equal(t, http.StatusOK, report.Alerts[0].StatusCode)
+	assert.False(t, report.Alerts[1].Delivered)
+	assert.Empty(t, report.Alerts[1].Outputs)
+	assert.Equal(t, http.StatusBadGateway, report.Alerts[1].StatusCode)
+	assert.Equal(t, http.StatusMultiStatus, report.StatusCode)
+
+	report = buildStatusReport(alerts[1:], []bool{false}, nil)
+	assert.Equal(t, http.StatusBadGateway, report.StatusCode)
+}
+
+func TestAggregateStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusOK, aggregateStatusCode(3, 0))
+	assert.Equal(t, http.StatusOK, aggregateStatusCode(0, 0))
+	assert.Equal(t, http.StatusMultiStatus, aggregateStatusCode(1, 2))
+	assert.Equal(t, http.StatusBadGateway, aggregateStatusCode(0, 3))
+}
+
+func TestBuildStatusReportStatusCodes(t *testing.T) {
+	alerts := append(statusCallbackAlerts(), &alertmodels.Alert{AnalysisID: "rule-3", Severity: "INFO"})
+
+	// Every output succeeded, was already delivered or was suppressed
+	report := buildStatusReport(alerts, []bool{true, true, true}, []deliveryResult{
+		{alertIndexes: []int{0, 1}, status: outputStatus{outputID: "slack", success: true}},
+		{alertIndexes: []int{1}, status: outputStatus{outputID: "sns", alreadyDelivered: true}},
+		{alertIndexes: []int{2}, status: outputStatus{outputID: "slack", suppressed: true}},
+	})
+	assert.Equal(t, http.StatusOK, report.StatusCode)
+	for _, alert := range report.Alerts {
+		assert.Equal(t, http.StatusOK, alert.StatusCode)
+	}
+
+	// Some alerts succeeded, the others failed
+	report = buildStatusReport(alerts, []bool{true, false, true}, []deliveryResult{
+		{alertIndexes: []int{0}, status: outputStatus{outputID: "slack", success: true}},
+		{alertIndexes: []int{1}, status: outputStatus{outputID: "slack", needsRetry: true}},
+	})
+	assert.Equal(t, http.StatusMultiStatus, report.StatusCode)
+	assert.Equal(t, http.StatusOK, report.Alerts[0].StatusCode)
+	assert.Equal(t, http.StatusBadGateway, report.Alerts[1].StatusCode)
+	assert.Equal(t, http.StatusOK, report.Alerts[2].StatusCode) // no outputs
+
+	// Every output failed
+	report = buildStatusReport(alerts[:2], []bool{false, false}, []deliveryResult{
+		{alertIndexes: []int{0, 1}, status: outputStatus{outputID: "slack", needsRetry: true, timedOut: true}},
+		{alertIndexes: []int{1}, status: outputStatus{outputID: "webhook", statusCode: 400}},
+	})
+	assert.Equal(t, http.StatusBadGateway, report.StatusCode)
+	assert.Equal(t, http.StatusBadGateway, report.Alerts[0].StatusCode)
+	assert.Equal(t, http.StatusBadGateway, report.Alerts[1].StatusCode)
+}
+
+func TestDeliveryStatusFailed(t *testing.T) {
+	assert.Equal(t, deliveryStatusFailed, deliveryStatus(outputStatus{outputID: "slack"}))
+}
+
+func TestDeliveryStatusSuppressed(t *testing.T) {
+	assert.Equal(t, deliveryStatusSuppressed, deliveryStatus(outputStatus{outputID: "slack", suppressed: true}))
+}
+
+// Point the status callback at a test server for the duration of the test
+func setStatusCallback(t *testing.T, url string, timeout time.Duration) {
+	originalURL, originalTimeout := statusCallbackURL, statusCallbackTimeout
+	statusCallbackURL, statusCallbackTimeout = url, timeout
+	t.Cleanup(func() { statusCallbackURL, statusCallbackTimeout = originalURL, originalTimeout })
+}
+
+func TestSendStatusCallback(t *testing.T) {
+	var received statusReport
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = jsoniter.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+	setStatusCallback(t, server.URL, time.Second)
+
+	sendStatusCallback(statusCallbackAlerts(), []bool{true, true}, statusCallbackDeliveries()[:1])
+
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, http.StatusOK, received.StatusCode)
+	require.Len(t, received.Alerts, 2)
+	assert.True(t, received.Alerts[0].Delivered)
+	assert.Equal(t, "alert-1", *received.Alerts[0].AlertID)
+	assert.Equal(t, []*outputStatusReport{{OutputID: "slack", Status: deliveryStatusSuccess}}, received.Alerts[1].Outputs)
+}
+
+func TestSendStatusCallbackTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done) // unblock the handler so the server can shut down
+	setStatusCallback(t, server.URL, 50*time.Millisecond)
+
+	start := time.Now()
+	sendStatusCallback(statusCallbackAlerts(), []bool{true, true}, nil)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestSendStatusCallbackRejected(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	setStatusCallback(t, server.URL, time.Second)
+
+	// Only logged, there is nothing to assert besides not panicking
+	sendStatusCallback(statusCallbackAlerts(), []bool{true, true}, nil)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSendStatusCallbackDisabled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+	defer server.Close()
+	setStatusCallback(t, "", time.Second)
+
+	sendStatusCallback(statusCallbackAlerts(), []bool{true, true}, nil)
+	assert.Equal(t, 0, calls)
+}
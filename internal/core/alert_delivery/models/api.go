@@ -42,7 +42,7 @@ type Alert struct {
 	// Severity is the alert severity at the time of creation.
 	Severity string `json:"severity" validate:"oneof=INFO LOW MEDIUM HIGH CRITICAL"`
 
-	// OutputIds is the set of outputs for this alert.
+	// OutputIds is the set of outputs for this alert, output groups are listed as "group:<name>".
 	OutputIds []string `json:"outputIds,omitempty"`
 
 	// AnalysisDescription is the description of the rule that triggered the alert.
@@ -65,4 +65,22 @@ type Alert struct {
 
 	// Title is the optional title for the alert generated by Python Rules engine
 	Title *string `json:"title,omitempty"`
+
+	// LogTypes is the set of log types of the events which triggered a rule alert.
+	LogTypes []string `json:"logTypes,omitempty"`
+
+	// AccountID is the account of the resource or log source which triggered the alert, if known.
+	AccountID *string `json:"accountId,omitempty"`
+
+	// Context is the organization-specific fields of the alert's account, e.g. {"costCenter": "1234"}.
+	Context map[string]string `json:"context,omitempty"`
+
+	// RetryCount is the number of times delivery of the alert has been retried.
+	RetryCount int `json:"retryCount,omitempty"`
+
+	// Escalated is set once the escalation output for the severity has been added to the outputs.
+	Escalated bool `json:"escalated,omitempty"`
+
+	// IgnoreMaintenance sends the alert even during a maintenance window, e.g. when it is re-delivered manually.
+	IgnoreMaintenance bool `json:"ignoreMaintenance,omitempty"`
 }
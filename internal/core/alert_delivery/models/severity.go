@@ -0,0 +1,51 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// SeverityLevel is how a severity is displayed by the outputs, and its place in the severity order.
+type SeverityLevel struct {
+	// Rank orders the severities, from 0 (INFO) to 4 (CRITICAL)
+	Rank int
+	// Color is the hex color of the severity in the Panther UI
+	Color string
+	// Emoji is shown next to the severity in text messages
+	Emoji string
+	// TeamsColor is the closest of the named colors supported by Microsoft Teams Adaptive Cards
+	TeamsColor string
+}
+
+// SeverityLevels is the single source of truth for the display and the order of the alert severities.
+var SeverityLevels = map[string]SeverityLevel{
+	"INFO":     {Rank: 0, Color: "#47b881", Emoji: "🟢", TeamsColor: "good"},
+	"LOW":      {Rank: 1, Color: "#f7d154", Emoji: "🟡", TeamsColor: "accent"},
+	"MEDIUM":   {Rank: 2, Color: "#d9822b", Emoji: "🟠", TeamsColor: "warning"},
+	"HIGH":     {Rank: 3, Color: "#cb2e2e", Emoji: "🔴", TeamsColor: "attention"},
+	"CRITICAL": {Rank: 4, Color: "#425a70", Emoji: "🚨", TeamsColor: "attention"},
+}
+
+// Severities lists the alert severities from most to least severe
+var Severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO"}
+
+// SeverityRank returns the rank of a severity (higher is more severe), -1 if the severity is unknown.
+func SeverityRank(severity string) int {
+	if level, ok := SeverityLevels[severity]; ok {
+		return level.Rank
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package models
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverityLevels(t *testing.T) {
+	// Every severity an alert can have is listed
+	field, ok := reflect.TypeOf(Alert{}).FieldByName("Severity")
+	require.True(t, ok)
+	validSeverities := strings.Fields(strings.TrimPrefix(field.Tag.Get("validate"), "oneof="))
+	assert.ElementsMatch(t, validSeverities, Severities)
+	require.Len(t, SeverityLevels, len(Severities))
+
+	colors := make(map[string]bool)
+	for i, severity := range Severities {
+		level, ok := SeverityLevels[severity]
+		require.True(t, ok, severity)
+		assert.Equal(t, len(Severities)-1-i, level.Rank, severity)
+		assert.Equal(t, level.Rank, SeverityRank(severity))
+		assert.Regexp(t, "^#[0-9a-f]{6}$", level.Color, severity)
+		assert.NotEmpty(t, level.Emoji, severity)
+		assert.Contains(t, []string{"attention", "warning", "accent", "good"}, level.TeamsColor, severity)
+		assert.False(t, colors[level.Color], "%s has the color of another severity", severity)
+		colors[level.Color] = true
+	}
+	assert.Equal(t, -1, SeverityRank("UNKNOWN"))
+}
@@ -49,6 +49,7 @@ func (client *OutputClient) Asana(alert *alertmodels.Alert, config *outputmodels
 		headers: map[string]string{
 			AuthorizationHTTPHeader: fmt.Sprintf(asanaAuthorizationHeaderFormat, config.PersonalAccessToken),
 		},
+		timeout: config.Timeout(),
 	}
 	return client.httpWrapper.post(postInput)
 }
@@ -27,9 +27,14 @@ import (
 func (client *OutputClient) CustomWebhook(
 	alert *alertmodels.Alert, config *outputmodels.CustomWebhookConfig) *AlertDeliveryError {
 
+	body, err := fitPayload("customwebhook", alert, generateNotificationPayload)
+	if err != nil {
+		return err
+	}
 	postInput := &PostInput{
-		url:  config.WebhookURL,
-		body: generateNotificationFromAlert(alert),
+		url:     config.WebhookURL,
+		body:    body,
+		timeout: config.Timeout(),
 	}
 	return client.httpWrapper.post(postInput)
 }
@@ -60,6 +60,7 @@ func TestCustomWebhookAlert(t *testing.T) {
 		Description: alert.AnalysisDescription,
 		Runbook:     alert.Runbook,
 		Tags:        []string{},
+		Context:     map[string]string{},
 		Version:     alert.Version,
 		CreatedAt:   alert.CreatedAt,
 	}
@@ -0,0 +1,94 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// SlackDigest sends a single message summarizing several alerts to a slack channel.
+func (client *OutputClient) SlackDigest(
+	alerts []*alertmodels.Alert, config *outputmodels.SlackConfig) *AlertDeliveryError {
+
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		lines[i] = fmt.Sprintf("[%s] <%s|%s>", alert.Severity, generateURL(alert), generateAlertTitle(alert))
+	}
+
+	title := generateDigestTitle(alerts)
+	payload := map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{
+				"fallback": title,
+				"color":    alertmodels.SeverityLevels[highestSeverity(alerts)].Color,
+				"title":    title,
+				"text":     strings.Join(lines, "\n"),
+				"fields": []map[string]interface{}{
+					{
+						"title": "Severity",
+						"value": summarizeSeverities(alerts),
+						"short": false,
+					},
+				},
+			},
+		},
+	}
+	postInput := &PostInput{
+		url:     config.WebhookURL,
+		body:    payload,
+		timeout: config.Timeout(),
+	}
+
+	return client.httpWrapper.post(postInput)
+}
+
+func generateDigestTitle(alerts []*alertmodels.Alert) string {
+	return fmt.Sprintf("Alert Digest: %d new alerts", len(alerts))
+}
+
+// Returns the number of alerts of each severity, e.g. "2 HIGH, 1 LOW"
+func summarizeSeverities(alerts []*alertmodels.Alert) string {
+	counts := make(map[string]int)
+	for _, alert := range alerts {
+		counts[alert.Severity]++
+	}
+
+	// Summarized from most to least severe
+	var summary []string
+	for _, severity := range alertmodels.Severities {
+		if counts[severity] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	return strings.Join(summary, ", ")
+}
+
+func highestSeverity(alerts []*alertmodels.Alert) string {
+	highest := ""
+	for _, alert := range alerts {
+		if alertmodels.SeverityRank(alert.Severity) > alertmodels.SeverityRank(highest) {
+			highest = alert.Severity
+		}
+	}
+	return highest
+}
@@ -0,0 +1,86 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+func TestSlackDigest(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alerts := []*alertmodels.Alert{
+		{
+			AnalysisID:   "policyId",
+			CreatedAt:    time.Now(),
+			AnalysisName: aws.String("policyName"),
+			Severity:     "LOW",
+		},
+		{
+			AnalysisID:   "ruleId",
+			AlertID:      aws.String("alertId"),
+			Type:         alertmodels.RuleType,
+			CreatedAt:    time.Now(),
+			AnalysisName: aws.String("ruleName"),
+			Severity:     "HIGH",
+		},
+	}
+
+	expectedPostPayload := map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{
+				"fallback": "Alert Digest: 2 new alerts",
+				"color":    "#cb2e2e",
+				"title":    "Alert Digest: 2 new alerts",
+				"text": "[LOW] <https://panther.io/policies/policyId|Policy Failure: policyName>\n" +
+					"[HIGH] <https://panther.io/alerts/alertId|New Alert: ruleName>",
+				"fields": []map[string]interface{}{
+					{
+						"title": "Severity",
+						"value": "1 HIGH, 1 LOW",
+						"short": false,
+					},
+				},
+			},
+		},
+	}
+	expectedPostInput := &PostInput{
+		url:  slackConfig.WebhookURL,
+		body: expectedPostPayload,
+	}
+
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.SlackDigest(alerts, slackConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSummarizeSeverities(t *testing.T) {
+	alerts := []*alertmodels.Alert{{Severity: "INFO"}, {Severity: "CRITICAL"}, {Severity: "INFO"}}
+	assert.Equal(t, "1 CRITICAL, 2 INFO", summarizeSeverities(alerts))
+	assert.Equal(t, "CRITICAL", highestSeverity(alerts))
+}
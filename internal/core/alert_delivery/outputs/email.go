@@ -0,0 +1,212 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+const emailCharset = "UTF-8"
+
+// The HTML part of alert emails. Email clients ignore most CSS, so the layout is made of tables.
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #333333;">
+<table width="100%" cellpadding="12" cellspacing="0">
+<tr><td bgcolor="{{.Color}}" style="color: #ffffff; font-size: 18px; font-weight: bold;">{{.Title}}</td></tr>
+</table>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<table cellpadding="6" cellspacing="0" border="1" style="border-collapse: collapse;">
+{{- range .Fields}}
+<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Runbook}}
+<h3>Runbook</h3>
+<p style="white-space: pre-wrap;">{{.Runbook}}</p>
+{{- end}}
+<p><a href="{{.Link}}" style="background-color: #4a6fd3; color: #ffffff; padding: 10px 16px; text-decoration: none;">{{.LinkText}}</a></p>
+</body>
+</html>
+`))
+
+type emailData struct {
+	Title       string
+	Color       string
+	Description string
+	Fields      []alertField
+	Runbook     string
+	Link        string
+	LinkText    string
+}
+
+// Email sends an alert as an HTML email with a plain text fallback through SES.
+//
+// The HTML part is rendered from the custom template of the output, if it has one.
+// Returns the SES message ID when the email was sent.
+func (client *OutputClient) Email(
+	alert *alertmodels.Alert, config *outputmodels.EmailConfig) (string, *AlertDeliveryError) {
+
+	var body string
+	if config.MessageTemplate != "" {
+		tmpl, deliveryErr := parseHTMLMessageTemplate(config.MessageTemplate)
+		if deliveryErr != nil {
+			return "", deliveryErr
+		}
+		if body, deliveryErr = renderMessageTemplate(tmpl, alert); deliveryErr != nil {
+			return "", deliveryErr
+		}
+	} else {
+		var err error
+		if body, err = generateEmailHTML(alert); err != nil {
+			errorMsg := "Failed to render email"
+			zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
+			return "", &AlertDeliveryError{Message: errorMsg, Permanent: true}
+		}
+	}
+
+	input := &ses.SendEmailInput{
+		Source:      aws.String(config.FromAddress),
+		Destination: &ses.Destination{ToAddresses: aws.StringSlice(config.Recipients)},
+		Message: &ses.Message{
+			Subject: sesContent(generateAlertTitle(alert)),
+			Body: &ses.Body{
+				Html: sesContent(body),
+				Text: sesContent(generateDetailedAlertMessage(alert)),
+			},
+		},
+	}
+
+	response, err := client.getSesClient(config.Region, config.RoleArn).SendEmail(input)
+	if err != nil {
+		errorMsg := "Failed to send email through SES"
+		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
+		return "", sesDeliveryError(errorMsg, err)
+	}
+	return aws.StringValue(response.MessageId), nil
+}
+
+// sesDeliveryError retries throttling and server errors, other errors (e.g. an unverified sender) are permanent.
+func sesDeliveryError(errorMsg string, err error) *AlertDeliveryError {
+	result := &AlertDeliveryError{Message: errorMsg + ": " + err.Error()}
+	if requestErr, ok := err.(awserr.RequestFailure); ok {
+		result.StatusCode = requestErr.StatusCode()
+	}
+	result.Permanent = !request.IsErrorThrottle(err) && !request.IsErrorRetryable(err)
+	return result
+}
+
+func sesContent(data string) *ses.Content {
+	return &ses.Content{Charset: aws.String(emailCharset), Data: aws.String(data)}
+}
+
+// generateEmailHTML renders the HTML part of an alert email: the title in the color of the severity,
+// a table of the alert fields, the runbook and a button linking to the alert in Panther.
+func generateEmailHTML(alert *alertmodels.Alert) (string, error) {
+	data := emailData{
+		Title:       generateAlertTitle(alert),
+		Color:       alertmodels.SeverityLevels[alert.Severity].Color,
+		Description: aws.StringValue(alert.AnalysisDescription),
+		Fields:      generateAlertFields(alert),
+		Runbook:     aws.StringValue(alert.Runbook),
+		Link:        generateURL(alert),
+		LinkText:    viewInPantherText,
+	}
+	var body bytes.Buffer
+	if err := emailTemplate.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// alertField is a named detail of an alert, shown in email tables and available to message templates.
+type alertField struct {
+	Name  string
+	Value string
+}
+
+// generateAlertFields returns the details of an alert in display order:
+// the severity, the rule or policy name, the alert ID, the creation time, the tags and the context fields (by key).
+func generateAlertFields(alert *alertmodels.Alert) []alertField {
+	analysisKind := "Policy"
+	if alert.Type == alertmodels.RuleType {
+		analysisKind = "Rule"
+	}
+	fields := []alertField{
+		{Name: "Severity", Value: alert.Severity},
+		{Name: analysisKind, Value: getDisplayName(alert)},
+	}
+	if alert.AlertID != nil {
+		fields = append(fields, alertField{Name: "Alert ID", Value: *alert.AlertID})
+	}
+	fields = append(fields, alertField{Name: "Created", Value: alert.CreatedAt.UTC().Format(time.RFC3339)})
+	if len(alert.Tags) > 0 {
+		fields = append(fields, alertField{Name: "Tags", Value: strings.Join(alert.Tags, ", ")})
+	}
+	contextKeys := make([]string, 0, len(alert.Context))
+	for key := range alert.Context {
+		contextKeys = append(contextKeys, key)
+	}
+	sort.Strings(contextKeys)
+	for _, key := range contextKeys {
+		fields = append(fields, alertField{Name: key, Value: alert.Context[key]})
+	}
+	return fields
+}
+
+// getSesClient returns a client for the region (the region of the lambda if empty), assuming roleArn if set.
+func (client *OutputClient) getSesClient(region, roleArn string) sesiface.SESAPI {
+	cacheKey := region
+	if roleArn != "" {
+		cacheKey += "/" + roleArn
+	}
+	client.sesClientsLock.Lock()
+	defer client.sesClientsLock.Unlock()
+	sesClient, ok := client.sesClients[cacheKey]
+	if !ok {
+		config := aws.NewConfig()
+		if region != "" {
+			config = config.WithRegion(region)
+		}
+		if roleArn != "" {
+			config = config.WithCredentials(stscreds.NewCredentials(client.session, roleArn))
+		}
+		sesClient = ses.New(client.session, config)
+		client.sesClients[cacheKey] = sesClient
+	}
+	return sesClient
+}
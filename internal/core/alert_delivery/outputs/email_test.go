@@ -0,0 +1,163 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+type mockSes struct {
+	sesiface.SESAPI
+	mock.Mock
+}
+
+func (m *mockSes) SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*ses.SendEmailOutput), args.Error(1)
+}
+
+func emailAlert() *alertmodels.Alert {
+	return &alertmodels.Alert{
+		AlertID:             aws.String("alert-id"),
+		Type:                alertmodels.RuleType,
+		AnalysisID:          "rule-id",
+		AnalysisName:        aws.String("Suspicious Login"),
+		AnalysisDescription: aws.String("Detects logins from new countries"),
+		Severity:            "HIGH",
+		CreatedAt:           time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Runbook:             aws.String("Check <b>the</b> source IP & reset the password"),
+		Tags:                []string{"iam", "login"},
+		Context:             map[string]string{"environment": "prod", "costCenter": "1234"},
+	}
+}
+
+func TestGenerateEmailHTML(t *testing.T) {
+	body, err := generateEmailHTML(emailAlert())
+	require.NoError(t, err)
+
+	golden := filepath.Join("testdata", "email.html")
+	if *updateGolden {
+		require.NoError(t, ioutil.WriteFile(golden, []byte(body), 0644))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), body)
+}
+
+func TestGenerateEmailHTMLMinimal(t *testing.T) {
+	body, err := generateEmailHTML(&alertmodels.Alert{
+		Type:       alertmodels.PolicyType,
+		AnalysisID: "policy-id",
+		Severity:   "INFO",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, body, `<tr><th align="left">Policy</th><td>policy-id</td></tr>`)
+	assert.Contains(t, body, `<a href="https://panther.io/policies/policy-id"`)
+	assert.NotContains(t, body, "Runbook")
+	assert.NotContains(t, body, "Tags")
+}
+
+func TestSendEmail(t *testing.T) {
+	client := &mockSes{}
+	outputClient := &OutputClient{sesClients: map[string]sesiface.SESAPI{"us-east-1": client}}
+	config := &outputmodels.EmailConfig{
+		FromAddress: "alerts@example.com",
+		Recipients:  []string{"security@example.com", "oncall@example.com"},
+		Region:      "us-east-1",
+	}
+	alert := emailAlert()
+	html, err := generateEmailHTML(alert)
+	require.NoError(t, err)
+
+	client.On("SendEmail", &ses.SendEmailInput{
+		Source:      aws.String("alerts@example.com"),
+		Destination: &ses.Destination{ToAddresses: aws.StringSlice(config.Recipients)},
+		Message: &ses.Message{
+			Subject: sesContent("New Alert: Suspicious Login"),
+			Body: &ses.Body{
+				Html: sesContent(html),
+				Text: sesContent(generateDetailedAlertMessage(alert)),
+			},
+		},
+	}).Return(&ses.SendEmailOutput{MessageId: aws.String("ses-message-id")}, nil)
+
+	messageID, deliveryErr := outputClient.Email(alert, config)
+	assert.Nil(t, deliveryErr)
+	assert.Equal(t, "ses-message-id", messageID)
+	client.AssertExpectations(t)
+}
+
+func TestSendEmailThrottled(t *testing.T) {
+	client := &mockSes{}
+	outputClient := &OutputClient{sesClients: map[string]sesiface.SESAPI{"": client}}
+	client.On("SendEmail", mock.Anything).Return((*ses.SendEmailOutput)(nil), awserr.NewRequestFailure(
+		awserr.New("Throttling", "Maximum sending rate exceeded.", nil), 400, "request-id"))
+
+	messageID, deliveryErr := outputClient.Email(emailAlert(), &outputmodels.EmailConfig{})
+	assert.Empty(t, messageID)
+	require.NotNil(t, deliveryErr)
+	assert.False(t, deliveryErr.Permanent)
+	assert.Equal(t, 400, deliveryErr.StatusCode)
+}
+
+func TestSendEmailRejected(t *testing.T) {
+	client := &mockSes{}
+	outputClient := &OutputClient{sesClients: map[string]sesiface.SESAPI{"": client}}
+	client.On("SendEmail", mock.Anything).Return((*ses.SendEmailOutput)(nil), awserr.NewRequestFailure(
+		awserr.New(ses.ErrCodeMessageRejected, "Email address is not verified.", nil), 400, "request-id"))
+
+	_, deliveryErr := outputClient.Email(emailAlert(), &outputmodels.EmailConfig{})
+	require.NotNil(t, deliveryErr)
+	assert.True(t, deliveryErr.Permanent)
+}
+
+func TestGetSesClientConcurrent(t *testing.T) {
+	outputClient := New(session.Must(session.NewSession()))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputClient.getSesClient(fmt.Sprintf("us-west-%d", i%2+1), "")
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, outputClient.sesClients, 2)
+	assert.Same(t, outputClient.getSesClient("us-west-1", ""), outputClient.getSesClient("us-west-1", ""))
+}
@@ -27,6 +27,13 @@ type AlertDeliveryError struct {
 	// For example, outputs which don't exist or errors creating the request are permanent failures.
 	// But any error talking to the output itself can be retried by the Lambda function later.
 	Permanent bool
+
+	// TimedOut indicates the output did not respond in time, as opposed to rejecting the alert.
+	// Timeouts are never permanent.
+	TimedOut bool
+
+	// StatusCode is the HTTP status of the response, if the output rejected the request
+	StatusCode int
 }
 
 func (e *AlertDeliveryError) Error() string { return e.Message }
@@ -58,6 +58,7 @@ func (client *OutputClient) Github(
 		url:     repoURL,
 		body:    githubRequest,
 		headers: requestHeader,
+		timeout: config.Timeout(),
 	}
 	return client.httpWrapper.post(postInput)
 }
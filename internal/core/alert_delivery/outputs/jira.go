@@ -74,6 +74,7 @@ func (client *OutputClient) Jira(
 		url:     jiraRestURL,
 		body:    jiraRequest,
 		headers: requestHeader,
+		timeout: config.Timeout(),
 	}
 	return client.httpWrapper.post(postInput)
 }
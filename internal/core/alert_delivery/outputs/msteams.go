@@ -27,44 +27,75 @@ import (
 	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
-// MsTeams alert send an alert.
+// MsTeams sends an alert to a Microsoft Teams channel, as an Adaptive Card.
 func (client *OutputClient) MsTeams(
 	alert *alertmodels.Alert, config *outputmodels.MsTeamsConfig) *AlertDeliveryError {
 
-	link := "[Click here to view in the Panther UI](" + policyURLPrefix + alert.AnalysisID + ").\n"
+	postInput := &PostInput{
+		url:     config.WebhookURL,
+		body:    generateMsTeamsCard(alert),
+		timeout: config.Timeout(),
+	}
+	return client.httpWrapper.post(postInput)
+}
 
-	msTeamsRequestBody := map[string]interface{}{
-		"@context": "http://schema.org/extensions",
-		"@type":    "MessageCard",
-		"text":     generateAlertTitle(alert),
-		"sections": []interface{}{
-			map[string]interface{}{
-				"facts": []interface{}{
-					map[string]string{"name": "Description", "value": aws.StringValue(alert.AnalysisDescription)},
-					map[string]string{"name": "Runbook", "value": aws.StringValue(alert.Runbook)},
-					map[string]string{"name": "Severity", "value": alert.Severity},
-					map[string]string{"name": "Tags", "value": strings.Join(alert.Tags, ", ")},
-				},
-				"text": link,
-			},
-		},
-		"potentialAction": []interface{}{
-			map[string]interface{}{
-				"@type": "OpenUri",
-				"name":  "Click here to view in the Panther UI",
-				"targets": []interface{}{
-					map[string]string{
-						"os":  "default",
-						"uri": generateURL(alert),
+// generateMsTeamsCard builds the incoming webhook message for an alert.
+//
+// It has the same structure as the Slack message: a title colored by severity, the alert fields
+// and a link to the alert in Panther.
+func generateMsTeamsCard(alert *alertmodels.Alert) map[string]interface{} {
+	facts := []map[string]string{
+		{"title": "Severity", "value": alert.Severity},
+	}
+	// Omit empty fields entirely rather than rendering them blank
+	if description := aws.StringValue(alert.AnalysisDescription); description != "" {
+		facts = append(facts, map[string]string{"title": "Description", "value": description})
+	}
+	if runbook := aws.StringValue(alert.Runbook); runbook != "" {
+		facts = append(facts, map[string]string{"title": "Runbook", "value": runbook})
+	}
+	if len(alert.Tags) > 0 {
+		facts = append(facts, map[string]string{"title": "Tags", "value": strings.Join(alert.Tags, ", ")})
+	}
+
+	// Adaptive Cards only support a fixed set of named colors
+	color := "default"
+	if level, ok := alertmodels.SeverityLevels[alert.Severity]; ok {
+		color = level.TeamsColor
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.2",
+					"body": []map[string]interface{}{
+						{
+							"type":   "TextBlock",
+							"text":   generateAlertTitle(alert),
+							"size":   "Medium",
+							"weight": "Bolder",
+							"color":  color,
+							"wrap":   true,
+						},
+						{
+							"type":  "FactSet",
+							"facts": facts,
+						},
+					},
+					"actions": []map[string]interface{}{
+						{
+							"type":  "Action.OpenUrl",
+							"title": viewInPantherText,
+							"url":   generateURL(alert),
+						},
 					},
 				},
 			},
 		},
 	}
-
-	postInput := &PostInput{
-		url:  config.WebhookURL,
-		body: msTeamsRequestBody,
-	}
-	return client.httpWrapper.post(postInput)
 }
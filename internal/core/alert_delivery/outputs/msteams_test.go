@@ -23,6 +23,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -47,28 +49,36 @@ func TestMsTeamsAlert(t *testing.T) {
 	}
 
 	msTeamsPayload := map[string]interface{}{
-		"@context": "http://schema.org/extensions",
-		"@type":    "MessageCard",
-		"text":     "Policy Failure: policyName",
-		"sections": []interface{}{
-			map[string]interface{}{
-				"facts": []interface{}{
-					map[string]string{"name": "Description", "value": ""},
-					map[string]string{"name": "Runbook", "value": ""},
-					map[string]string{"name": "Severity", "value": "INFO"},
-					map[string]string{"name": "Tags", "value": ""},
-				},
-				"text": "[Click here to view in the Panther UI](https://panther.io/policies/policyId).\n",
-			},
-		},
-		"potentialAction": []interface{}{
-			map[string]interface{}{
-				"@type": "OpenUri",
-				"name":  "Click here to view in the Panther UI",
-				"targets": []interface{}{
-					map[string]string{
-						"os":  "default",
-						"uri": "https://panther.io/policies/policyId",
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.2",
+					"body": []map[string]interface{}{
+						{
+							"type":   "TextBlock",
+							"text":   "Policy Failure: policyName",
+							"size":   "Medium",
+							"weight": "Bolder",
+							"color":  "good",
+							"wrap":   true,
+						},
+						{
+							"type": "FactSet",
+							"facts": []map[string]string{
+								{"title": "Severity", "value": "INFO"},
+							},
+						},
+					},
+					"actions": []map[string]interface{}{
+						{
+							"type":  "Action.OpenUrl",
+							"title": "View in Panther",
+							"url":   "https://panther.io/policies/policyId",
+						},
 					},
 				},
 			},
@@ -87,3 +97,47 @@ func TestMsTeamsAlert(t *testing.T) {
 	require.Nil(t, client.MsTeams(alert, msTeamConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestMsTeamsAlertFailure(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := &alertmodels.Alert{AnalysisID: "policyId", Severity: "INFO"}
+
+	deliveryError := &AlertDeliveryError{Message: "request failed with status code 400: bad card", StatusCode: 400}
+	httpWrapper.On("post", mock.Anything).Return(deliveryError)
+
+	result := client.MsTeams(alert, msTeamConfig)
+	require.NotNil(t, result)
+	assert.Equal(t, 400, result.StatusCode)
+	assert.False(t, result.Permanent)
+}
+
+func TestGenerateMsTeamsCardFields(t *testing.T) {
+	alert := &alertmodels.Alert{
+		AnalysisID:          "ruleId",
+		AnalysisName:        aws.String("ruleName"),
+		AnalysisDescription: aws.String("description"),
+		Runbook:             aws.String("runbook"),
+		Tags:                []string{"tag1", "tag2"},
+		Severity:            "CRITICAL",
+		Type:                alertmodels.RuleType,
+		AlertID:             aws.String("alertId"),
+	}
+
+	card := generateMsTeamsCard(alert)
+	content := card["attachments"].([]map[string]interface{})[0]["content"].(map[string]interface{})
+	body := content["body"].([]map[string]interface{})
+	assert.Equal(t, "attention", body[0]["color"])
+	assert.Equal(t, []map[string]string{
+		{"title": "Severity", "value": "CRITICAL"},
+		{"title": "Description", "value": "description"},
+		{"title": "Runbook", "value": "runbook"},
+		{"title": "Tags", "value": "tag1, tag2"},
+	}, body[1]["facts"])
+}
+
+func TestGenerateMsTeamsCardUnknownSeverity(t *testing.T) {
+	card := generateMsTeamsCard(&alertmodels.Alert{AnalysisID: "policyId", Severity: "UNKNOWN"})
+	content := card["attachments"].([]map[string]interface{})[0]["content"].(map[string]interface{})
+	assert.Equal(t, "default", content["body"].([]map[string]interface{})[0]["color"])
+}
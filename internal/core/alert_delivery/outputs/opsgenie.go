@@ -61,6 +61,7 @@ func (client *OutputClient) Opsgenie(
 		url:     opsgenieEndpoint,
 		body:    opsgenieRequest,
 		headers: requestHeader,
+		timeout: config.Timeout(),
 	}
 	return client.httpWrapper.post(postInput)
 }
@@ -22,10 +22,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 
@@ -37,8 +40,19 @@ import (
 var (
 	policyURLPrefix = os.Getenv("POLICY_URL_PREFIX")
 	alertURLPrefix  = os.Getenv("ALERT_URL_PREFIX")
+
+	// HTTP requests to an output are cancelled after this long, unless the output configures its own timeout
+	defaultHTTPTimeout = getDefaultHTTPTimeout()
 )
 
+func getDefaultHTTPTimeout() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("ALERT_OUTPUT_TIMEOUT_SECS"))
+	if err != nil || seconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // HTTPWrapper encapsulates the Golang's http client
 type HTTPWrapper struct {
 	httpClient HTTPiface
@@ -49,6 +63,10 @@ type PostInput struct {
 	url     string
 	body    interface{}
 	headers map[string]string
+	// If set, the payload is signed with HMAC-SHA256 in the X-Panther-Signature header
+	signingSecret string
+	// The request is cancelled after this long (defaultHTTPTimeout if not set)
+	timeout time.Duration
 }
 
 // HTTPWrapperiface is the interface for our wrapper around Golang's http client
@@ -64,15 +82,18 @@ type HTTPiface interface {
 // API is the interface for output delivery that can be used for mocks in tests.
 type API interface {
 	Slack(*alertmodels.Alert, *outputmodels.SlackConfig) *AlertDeliveryError
+	SlackDigest([]*alertmodels.Alert, *outputmodels.SlackConfig) *AlertDeliveryError
 	PagerDuty(*alertmodels.Alert, *outputmodels.PagerDutyConfig) *AlertDeliveryError
 	Github(*alertmodels.Alert, *outputmodels.GithubConfig) *AlertDeliveryError
 	Jira(*alertmodels.Alert, *outputmodels.JiraConfig) *AlertDeliveryError
 	Opsgenie(*alertmodels.Alert, *outputmodels.OpsgenieConfig) *AlertDeliveryError
 	MsTeams(*alertmodels.Alert, *outputmodels.MsTeamsConfig) *AlertDeliveryError
 	Sqs(*alertmodels.Alert, *outputmodels.SqsConfig) *AlertDeliveryError
-	Sns(*alertmodels.Alert, *outputmodels.SnsConfig) *AlertDeliveryError
+	Sns(*alertmodels.Alert, *outputmodels.SnsConfig) (string, *AlertDeliveryError)
 	Asana(*alertmodels.Alert, *outputmodels.AsanaConfig) *AlertDeliveryError
 	CustomWebhook(*alertmodels.Alert, *outputmodels.CustomWebhookConfig) *AlertDeliveryError
+	Webhook(*alertmodels.Alert, *outputmodels.WebhookConfig) *AlertDeliveryError
+	Email(*alertmodels.Alert, *outputmodels.EmailConfig) (string, *AlertDeliveryError)
 }
 
 // OutputClient encapsulates the clients that allow sending alerts to multiple outputs
@@ -82,6 +103,10 @@ type OutputClient struct {
 	// Map from region -> client
 	sqsClients map[string]sqsiface.SQSAPI
 	snsClients map[string]snsiface.SNSAPI
+	sesClients map[string]sesiface.SESAPI
+	// Alerts are sent by concurrent workers which create the clients on first use
+	snsClientsLock sync.Mutex
+	sesClientsLock sync.Mutex
 }
 
 // OutputClient must satisfy the API interface.
@@ -95,9 +120,13 @@ func New(sess *session.Session) *OutputClient {
 		// TODO Lazy initialization of clients
 		sqsClients: make(map[string]sqsiface.SQSAPI),
 		snsClients: make(map[string]snsiface.SNSAPI),
+		sesClients: make(map[string]sesiface.SESAPI),
 	}
 }
 
+// The text of the links back to the alert (or policy) in the Panther UI
+const viewInPantherText = "View in Panther"
+
 const detailedMessageTemplate = "%s\nFor more details please visit: %s\nSeverity: %s\nRunbook: %s\nDescription: %s"
 
 // The default payload delivered by all outputs to destinations
@@ -141,6 +170,9 @@ type Notification struct {
 
 	// Version is the S3 object version for the policy
 	Version *string `json:"version"`
+
+	// Context is the organization-specific fields of the alert's account, e.g. {"costCenter": "1234"}
+	Context map[string]string `json:"context"`
 }
 
 func generateNotificationFromAlert(alert *alertmodels.Alert) Notification {
@@ -157,6 +189,7 @@ func generateNotificationFromAlert(alert *alertmodels.Alert) Notification {
 		Tags:        alert.Tags,
 		Version:     alert.Version,
 		CreatedAt:   alert.CreatedAt,
+		Context:     alert.Context,
 	}
 	gatewayapi.ReplaceMapSliceNils(&notification)
 	return notification
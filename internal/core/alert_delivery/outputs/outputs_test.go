@@ -82,3 +82,16 @@ func TestGenerateAlertTitlePolicyId(t *testing.T) {
 	}
 	assert.Equal(t, "Policy Failure: policy.id", generateAlertTitle(alert))
 }
+
+func TestGenerateNotificationContext(t *testing.T) {
+	alert := &alertModel.Alert{
+		AnalysisID: "policyId",
+		Type:       alertModel.PolicyType,
+		Context:    map[string]string{"costCenter": "1234"},
+	}
+	assert.Equal(t, map[string]string{"costCenter": "1234"}, generateNotificationFromAlert(alert).Context)
+
+	// Alerts without context are delivered with an empty object, never null
+	alert.Context = nil
+	assert.Equal(t, map[string]string{}, generateNotificationFromAlert(alert).Context)
+}
@@ -49,11 +49,18 @@ func (client *OutputClient) PagerDuty(alert *alertmodels.Alert, config *outputmo
 		"payload":      payload,
 		"routing_key":  config.IntegrationKey,
 		"event_action": triggerEventAction,
+		"links": []map[string]string{
+			{
+				"href": generateURL(alert),
+				"text": viewInPantherText,
+			},
+		},
 	}
 
 	postInput := &PostInput{
-		url:  pagerDutyEndpoint,
-		body: pagerDutyRequest,
+		url:     pagerDutyEndpoint,
+		body:    pagerDutyRequest,
+		timeout: config.Timeout(),
 	}
 
 	return client.httpWrapper.post(postInput)
@@ -62,6 +62,7 @@ func TestSendPagerDutyAlert(t *testing.T) {
 				Name:      aws.String("policyName"),
 				Runbook:   aws.String("runbook"),
 				Tags:      []string{},
+				Context:   map[string]string{},
 			},
 			"severity":  "info",
 			"source":    "pantherlabs",
@@ -69,6 +70,12 @@ func TestSendPagerDutyAlert(t *testing.T) {
 			"timestamp": "2019-05-03T11:40:13Z",
 		},
 		"routing_key": "integrationKey",
+		"links": []map[string]string{
+			{
+				"href": "https://panther.io/policies/policyId",
+				"text": "View in Panther",
+			},
+		},
 	}
 	requestEndpoint := "https://events.pagerduty.com/v2/enqueue"
 	expectedPostInput := &PostInput{
@@ -0,0 +1,128 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+const (
+	// Titles and names are clipped to this many characters when an alert is truncated
+	maxTruncatedTitleLength = 256
+
+	// The HTTP status reported when a payload is rejected before it is sent
+	statusPayloadTooLarge = 413
+)
+
+var (
+	// The largest JSON payload (in bytes) each output type accepts, larger requests are rejected downstream
+	maxPayloadBytes = map[string]int{
+		"slack":         40000,
+		"customwebhook": 1024 * 1024,
+		"webhook":       1024 * 1024,
+	}
+
+	// Oversized payloads are truncated unless ALERT_OVERSIZED_PAYLOADS is "fail", then they are rejected
+	truncateOversizedPayloads = os.Getenv("ALERT_OVERSIZED_PAYLOADS") != "fail"
+)
+
+// fitPayload checks the size of a payload against the limit of the output type before it is sent.
+//
+// The payload of an oversized alert is rendered again from the truncated alert (see truncateAlert),
+// unless truncation is disabled. If it still does not fit, a permanent error is returned,
+// since sending the same payload again would fail the same way.
+func fitPayload(
+	outputType string,
+	alert *alertmodels.Alert,
+	render func(*alertmodels.Alert) interface{},
+) (interface{}, *AlertDeliveryError) {
+
+	body := render(alert)
+	limit, ok := maxPayloadBytes[outputType]
+	if !ok {
+		return body, nil
+	}
+
+	size, err := payloadSize(body)
+	if err != nil {
+		return nil, err
+	}
+	if size <= limit {
+		return body, nil
+	}
+
+	if truncateOversizedPayloads {
+		body = render(truncateAlert(alert))
+		if size, err = payloadSize(body); err != nil {
+			return nil, err
+		}
+		if size <= limit {
+			return body, nil
+		}
+	}
+
+	return nil, &AlertDeliveryError{
+		Message: "payload too large for " + outputType + ": " +
+			strconv.Itoa(size) + " bytes exceeds the limit of " + strconv.Itoa(limit),
+		Permanent:  true,
+		StatusCode: statusPayloadTooLarge,
+	}
+}
+
+// generateNotificationPayload renders the default payload, see Notification
+func generateNotificationPayload(alert *alertmodels.Alert) interface{} {
+	return generateNotificationFromAlert(alert)
+}
+
+func payloadSize(body interface{}) (int, *AlertDeliveryError) {
+	payload, err := jsoniter.Marshal(body)
+	if err != nil {
+		return 0, &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+	}
+	return len(payload), nil
+}
+
+// truncateAlert returns a copy of the alert without its low-priority fields (runbook, description and tags)
+// and with its title and name clipped, which are all the user-provided fields of arbitrary length.
+func truncateAlert(alert *alertmodels.Alert) *alertmodels.Alert {
+	truncated := *alert
+	truncated.Runbook = nil
+	truncated.AnalysisDescription = nil
+	truncated.Tags = nil
+	truncated.Title = clipString(alert.Title, maxTruncatedTitleLength)
+	truncated.AnalysisName = clipString(alert.AnalysisName, maxTruncatedTitleLength)
+	return &truncated
+}
+
+func clipString(s *string, maxLength int) *string {
+	if s == nil {
+		return nil
+	}
+	runes := []rune(*s)
+	if len(runes) <= maxLength {
+		return s
+	}
+	return aws.String(string(runes[:maxLength-3]) + "...")
+}
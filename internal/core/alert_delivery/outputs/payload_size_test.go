@@ -0,0 +1,150 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// An alert with a runbook too large for any output
+func oversizedAlert() *alertmodels.Alert {
+	return &alertmodels.Alert{
+		AlertID:             aws.String("alertId"),
+		AnalysisID:          "ruleId",
+		Type:                alertmodels.RuleType,
+		CreatedAt:           time.Now(),
+		Severity:            "HIGH",
+		AnalysisName:        aws.String("ruleName"),
+		AnalysisDescription: aws.String("description"),
+		Runbook:             aws.String(strings.Repeat("x", 2*1024*1024)),
+		Tags:                []string{"tag"},
+	}
+}
+
+func setTruncateOversizedPayloads(t *testing.T, truncate bool) {
+	previous := truncateOversizedPayloads
+	truncateOversizedPayloads = truncate
+	t.Cleanup(func() { truncateOversizedPayloads = previous })
+}
+
+func TestSlackOversizedTruncated(t *testing.T) {
+	setTruncateOversizedPayloads(t, true)
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := oversizedAlert()
+
+	expectedPostInput := &PostInput{
+		url:  slackConfig.WebhookURL,
+		body: generateSlackPayload(truncateAlert(alert)),
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Slack(alert, slackConfig))
+	httpWrapper.AssertExpectations(t)
+	// The runbook field is dropped from the message, but the alert is left as it is
+	attachment := expectedPostInput.body.(map[string]interface{})["attachments"].([]map[string]interface{})[0]
+	assert.Len(t, attachment["fields"], 2)
+	assert.NotNil(t, alert.Runbook)
+}
+
+func TestSlackOversizedFails(t *testing.T) {
+	setTruncateOversizedPayloads(t, false)
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	result := client.Slack(oversizedAlert(), slackConfig)
+	require.NotNil(t, result)
+	assert.True(t, strings.HasPrefix(result.Message, "payload too large for slack: "), result.Message)
+	assert.True(t, result.Permanent)
+	assert.Equal(t, statusPayloadTooLarge, result.StatusCode)
+	httpWrapper.AssertNotCalled(t, "post", mock.Anything)
+}
+
+func TestWebhookOversizedTruncated(t *testing.T) {
+	setTruncateOversizedPayloads(t, true)
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := oversizedAlert()
+	config := &outputmodels.WebhookConfig{WebhookURL: "https://example.com/panther"}
+
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+	require.Nil(t, client.Webhook(alert, config))
+	httpWrapper.AssertExpectations(t)
+
+	notification := httpWrapper.Calls[0].Arguments.Get(0).(*PostInput).body.(Notification)
+	assert.Equal(t, "ruleId", notification.ID)
+	assert.Equal(t, "ruleName", *notification.Name)
+	assert.Nil(t, notification.Runbook)
+	assert.Nil(t, notification.Description)
+	assert.Equal(t, []string{}, notification.Tags)
+}
+
+func TestWebhookOversizedFails(t *testing.T) {
+	setTruncateOversizedPayloads(t, false)
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	config := &outputmodels.WebhookConfig{WebhookURL: "https://example.com/panther"}
+
+	result := client.Webhook(oversizedAlert(), config)
+	require.NotNil(t, result)
+	assert.True(t, strings.HasPrefix(result.Message, "payload too large for webhook: "), result.Message)
+	assert.True(t, result.Permanent)
+	httpWrapper.AssertNotCalled(t, "post", mock.Anything)
+}
+
+func TestFitPayloadTruncatedStillTooLarge(t *testing.T) {
+	setTruncateOversizedPayloads(t, true)
+	previous := maxPayloadBytes["customwebhook"]
+	maxPayloadBytes["customwebhook"] = 10
+	t.Cleanup(func() { maxPayloadBytes["customwebhook"] = previous })
+
+	body, err := fitPayload("customwebhook", oversizedAlert(), generateNotificationPayload)
+	assert.Nil(t, body)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "exceeds the limit of 10")
+}
+
+func TestFitPayloadUnknownOutputType(t *testing.T) {
+	alert := oversizedAlert()
+	body, err := fitPayload("sqs", alert, generateNotificationPayload)
+	require.Nil(t, err)
+	assert.Equal(t, generateNotificationFromAlert(alert), body)
+}
+
+func TestTruncateAlertClipsTitle(t *testing.T) {
+	alert := &alertmodels.Alert{
+		Title:        aws.String(strings.Repeat("é", 1000)),
+		AnalysisName: aws.String("short"),
+	}
+	truncated := truncateAlert(alert)
+	assert.Equal(t, maxTruncatedTitleLength, len([]rune(*truncated.Title)))
+	assert.True(t, strings.HasSuffix(*truncated.Title, "..."))
+	assert.Equal(t, "short", *truncated.AnalysisName)
+	assert.Nil(t, clipString(nil, 10))
+}
@@ -20,14 +20,20 @@ package outputs
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
 const (
 	AuthorizationHTTPHeader = "Authorization"
+	SignatureHTTPHeader     = "X-Panther-Signature"
 )
 
 // post sends a JSON body to an endpoint.
@@ -50,8 +56,23 @@ func (client *HTTPWrapper) post(input *PostInput) *AlertDeliveryError {
 		request.Header.Set(key, value)
 	}
 
+	if input.signingSecret != "" {
+		request.Header.Set(SignatureHTTPHeader, signPayload(input.signingSecret, payload))
+	}
+
+	timeout := input.timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+	request = request.WithContext(ctx)
+
 	response, err := client.httpClient.Do(request)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return &AlertDeliveryError{Message: "request timed out after " + timeout.String(), TimedOut: true}
+		}
 		return &AlertDeliveryError{Message: "network error: " + err.Error()}
 	}
 	defer response.Body.Close()
@@ -59,8 +80,17 @@ func (client *HTTPWrapper) post(input *PostInput) *AlertDeliveryError {
 	if response.StatusCode < 200 || response.StatusCode > 299 {
 		body, _ := ioutil.ReadAll(response.Body)
 		return &AlertDeliveryError{
-			Message: "request failed: " + response.Status + ": " + string(body)}
+			Message:    "request failed with status code " + strconv.Itoa(response.StatusCode) + ": " + string(body),
+			StatusCode: response.StatusCode,
+		}
 	}
 
 	return nil
 }
+
+// signPayload returns the hex encoded HMAC-SHA256 of the payload, keyed with the shared secret.
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) // never returns an error
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -20,12 +20,18 @@ package outputs
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockHTTPClient struct {
@@ -33,6 +39,7 @@ type mockHTTPClient struct {
 	statusCode   int
 	requestError bool
 	requestBody  string // Request body is saved here for tests to verify
+	request      *http.Request
 }
 
 var requestEndpoint = "https://runpanther.io"
@@ -46,6 +53,7 @@ func (m *mockHTTPClient) Do(request *http.Request) (*http.Response, error) {
 		panic(err)
 	}
 	m.requestBody = string(requestBytes)
+	m.request = request
 
 	responseBody := ioutil.NopCloser(bytes.NewReader([]byte("response")))
 	return &http.Response{Body: responseBody, StatusCode: m.statusCode}, nil
@@ -76,7 +84,45 @@ func TestPostNotOk(t *testing.T) {
 		url:  requestEndpoint,
 		body: map[string]interface{}{"abc": 123},
 	}
-	assert.NotNil(t, c.post(postInput))
+	result := c.post(postInput)
+	require.NotNil(t, result)
+	assert.Equal(t, "request failed with status code 400: response", result.Message)
+	assert.Equal(t, http.StatusBadRequest, result.StatusCode)
+	assert.False(t, result.Permanent)
+}
+
+func TestPostSigned(t *testing.T) {
+	httpClient := &mockHTTPClient{statusCode: http.StatusOK}
+	c := &HTTPWrapper{httpClient: httpClient}
+	postInput := &PostInput{
+		url:           requestEndpoint,
+		body:          map[string]interface{}{"abc": 123},
+		headers:       map[string]string{"X-Custom": "value"},
+		signingSecret: "secret",
+		timeout:       time.Second,
+	}
+	require.Nil(t, c.post(postInput))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(httpClient.requestBody))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), httpClient.request.Header.Get(SignatureHTTPHeader))
+	assert.Equal(t, "value", httpClient.request.Header.Get("X-Custom"))
+	_, hasDeadline := httpClient.request.Context().Deadline()
+	assert.True(t, hasDeadline)
+}
+
+func TestPostUnsigned(t *testing.T) {
+	httpClient := &mockHTTPClient{statusCode: http.StatusOK}
+	c := &HTTPWrapper{httpClient: httpClient}
+	postInput := &PostInput{
+		url:  requestEndpoint,
+		body: map[string]interface{}{"abc": 123},
+	}
+	require.Nil(t, c.post(postInput))
+	assert.Empty(t, httpClient.request.Header.Get(SignatureHTTPHeader))
+	// The default timeout still applies
+	_, hasDeadline := httpClient.request.Context().Deadline()
+	assert.True(t, hasDeadline)
 }
 
 func TestPostOk(t *testing.T) {
@@ -96,3 +142,39 @@ func TestPostCreated(t *testing.T) {
 	}
 	assert.Nil(t, c.post(postInput))
 }
+
+func TestPostTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done) // unblock the handler so the server can shut down
+
+	c := &HTTPWrapper{httpClient: server.Client()}
+	postInput := &PostInput{
+		url:     server.URL,
+		body:    map[string]interface{}{"abc": 123},
+		timeout: 50 * time.Millisecond,
+	}
+	result := c.post(postInput)
+	require.NotNil(t, result)
+	assert.True(t, result.TimedOut)
+	assert.False(t, result.Permanent)
+	assert.Equal(t, "request timed out after 50ms", result.Message)
+}
+
+func TestPostNetworkErrorIsNotTimeout(t *testing.T) {
+	c := &HTTPWrapper{httpClient: &mockHTTPClient{requestError: true}}
+	postInput := &PostInput{
+		url:  requestEndpoint,
+		body: map[string]interface{}{"abc": 123},
+	}
+	result := c.post(postInput)
+	require.NotNil(t, result)
+	assert.False(t, result.TimedOut)
+	assert.False(t, result.Permanent)
+}
@@ -27,51 +27,70 @@ import (
 	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
-// Severity colors match those in the Panther UI
-var severityColors = map[string]string{
-	"CRITICAL": "#425a70",
-	"HIGH":     "#cb2e2e",
-	"MEDIUM":   "#d9822b",
-	"LOW":      "#f7d154",
-	"INFO":     "#47b881",
-}
-
 // Slack sends an alert to a slack channel.
+//
+// The alert is sent as a plain message rendered from the custom template of the output, if it has one.
 func (client *OutputClient) Slack(alert *alertmodels.Alert, config *outputmodels.SlackConfig) *AlertDeliveryError {
-	messageField := fmt.Sprintf("<%s|%s>",
-		generateURL(alert),
-		"Click here to view in the Panther UI")
+	render := generateSlackPayload
+	var renderErr *AlertDeliveryError
+	if config.MessageTemplate != "" {
+		tmpl, err := parseTextMessageTemplate(config.MessageTemplate)
+		if err != nil {
+			return err
+		}
+		render = func(alert *alertmodels.Alert) interface{} {
+			var text string
+			text, renderErr = renderMessageTemplate(tmpl, alert)
+			return map[string]interface{}{"text": text}
+		}
+	}
+
+	payload, err := fitPayload("slack", alert, render)
+	if renderErr != nil {
+		return renderErr
+	}
+	if err != nil {
+		return err
+	}
+	postInput := &PostInput{
+		url:     config.WebhookURL,
+		body:    payload,
+		timeout: config.Timeout(),
+	}
+
+	return client.httpWrapper.post(postInput)
+}
+
+func generateSlackPayload(alert *alertmodels.Alert) interface{} {
+	messageField := fmt.Sprintf("<%s|%s>", generateURL(alert), viewInPantherText)
 	fields := []map[string]interface{}{
 		{
 			"value": messageField,
 			"short": false,
 		},
-		{
+	}
+	// Omit the runbook section entirely rather than rendering an empty field
+	if runbook := aws.StringValue(alert.Runbook); runbook != "" {
+		fields = append(fields, map[string]interface{}{
 			"title": "Runbook",
-			"value": aws.StringValue(alert.Runbook),
+			"value": runbook,
 			"short": false,
-		},
-		{
-			"title": "Severity",
-			"value": alert.Severity,
-			"short": true,
-		},
+		})
 	}
+	fields = append(fields, map[string]interface{}{
+		"title": "Severity",
+		"value": alert.Severity,
+		"short": true,
+	})
 
-	payload := map[string]interface{}{
+	return map[string]interface{}{
 		"attachments": []map[string]interface{}{
 			{
 				"fallback": generateAlertTitle(alert),
-				"color":    severityColors[alert.Severity],
+				"color":    alertmodels.SeverityLevels[alert.Severity].Color,
 				"title":    generateAlertTitle(alert),
 				"fields":   fields,
 			},
 		},
 	}
-	postInput := &PostInput{
-		url:  config.WebhookURL,
-		body: payload,
-	}
-
-	return client.httpWrapper.post(postInput)
 }
@@ -19,10 +19,13 @@ package outputs
  */
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -51,12 +54,7 @@ func TestSlackAlert(t *testing.T) {
 				"fields": []map[string]interface{}{
 					{
 						"short": false,
-						"value": "<https://panther.io/policies/policyId|Click here to view in the Panther UI>",
-					},
-					{
-						"short": false,
-						"title": "Runbook",
-						"value": "",
+						"value": "<https://panther.io/policies/policyId|View in Panther>",
 					},
 					{
 						"short": true,
@@ -79,3 +77,83 @@ func TestSlackAlert(t *testing.T) {
 	require.Nil(t, client.Slack(alert, slackConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestSlackAlertWithRunbook(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		AnalysisID:   "ruleId",
+		AlertID:      aws.String("alertId"),
+		Type:         alertmodels.RuleType,
+		CreatedAt:    time.Now(),
+		AnalysisName: aws.String("ruleName"),
+		Runbook:      aws.String("check the logs"),
+		Severity:     "HIGH",
+	}
+
+	expectedPostPayload := map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{"color": "#cb2e2e",
+				"fallback": "New Alert: ruleName",
+				"fields": []map[string]interface{}{
+					{
+						"short": false,
+						"value": "<https://panther.io/alerts/alertId|View in Panther>",
+					},
+					{
+						"short": false,
+						"title": "Runbook",
+						"value": "check the logs",
+					},
+					{
+						"short": true,
+						"title": "Severity",
+						"value": "HIGH",
+					},
+				},
+				"title": "New Alert: ruleName",
+			},
+		},
+	}
+	expectedPostInput := &PostInput{
+		url:  slackConfig.WebhookURL,
+		body: expectedPostPayload,
+	}
+
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Slack(alert, slackConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSlackAlertTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done) // unblock the handler so the server can shut down
+
+	client := &OutputClient{httpWrapper: &HTTPWrapper{httpClient: server.Client()}}
+	config := &outputmodels.SlackConfig{
+		WebhookURL:        server.URL,
+		HTTPTimeoutConfig: outputmodels.HTTPTimeoutConfig{TimeoutSeconds: 1},
+	}
+	alert := &alertmodels.Alert{
+		AnalysisID: "policyId",
+		CreatedAt:  time.Now(),
+		Severity:   "INFO",
+	}
+
+	start := time.Now()
+	result := client.Slack(alert, config)
+	require.NotNil(t, result)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, "request timed out after 1s", result.Message)
+	// The output's timeout applies, not the default
+	assert.Less(t, int64(time.Since(start)), int64(defaultHTTPTimeout))
+}
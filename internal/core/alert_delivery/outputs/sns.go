@@ -21,6 +21,7 @@ package outputs
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	jsoniter "github.com/json-iterator/go"
@@ -37,27 +38,37 @@ type snsMessage struct {
 	EmailMessage string `json:"email"`
 }
 
-// Sns sends an alert to an SNS Topic.
+// Sns sends an alert to an SNS Topic, returning the ID of the published message.
 // nolint: dupl
-func (client *OutputClient) Sns(alert *alertmodels.Alert, config *outputmodels.SnsConfig) *AlertDeliveryError {
+func (client *OutputClient) Sns(alert *alertmodels.Alert, config *outputmodels.SnsConfig) (string, *AlertDeliveryError) {
 	notification := generateNotificationFromAlert(alert)
 	serializedDefaultMessage, err := jsoniter.MarshalToString(notification)
 	if err != nil {
 		errorMsg := "Failed to serialize default message"
 		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
-		return &AlertDeliveryError{Message: errorMsg, Permanent: true}
+		return "", &AlertDeliveryError{Message: errorMsg, Permanent: true}
 	}
 
 	outputMessage := &snsMessage{
 		DefaultMessage: serializedDefaultMessage,
 		EmailMessage:   generateDetailedAlertMessage(alert),
 	}
+	// A custom template replaces the message of email subscribers, other subscribers still get the JSON notification
+	if config.MessageTemplate != "" {
+		tmpl, deliveryErr := parseTextMessageTemplate(config.MessageTemplate)
+		if deliveryErr != nil {
+			return "", deliveryErr
+		}
+		if outputMessage.EmailMessage, deliveryErr = renderMessageTemplate(tmpl, alert); deliveryErr != nil {
+			return "", deliveryErr
+		}
+	}
 
 	serializedMessage, err := jsoniter.MarshalToString(outputMessage)
 	if err != nil {
 		errorMsg := "Failed to serialize message"
 		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
-		return &AlertDeliveryError{Message: errorMsg, Permanent: true}
+		return "", &AlertDeliveryError{Message: errorMsg, Permanent: true}
 	}
 
 	snsMessageInput := &sns.PublishInput{
@@ -66,34 +77,87 @@ func (client *OutputClient) Sns(alert *alertmodels.Alert, config *outputmodels.S
 		// Subject is optional in case the topic is subscribed to Email
 		Subject:          aws.String(generateAlertTitle(alert)),
 		MessageStructure: aws.String("json"),
+		// Attributes let subscribers filter alerts without parsing the message body
+		MessageAttributes: generateSnsMessageAttributes(alert),
 	}
 
-	snsClient, err := client.getSnsClient(config.TopicArn)
+	snsClient, err := client.getSnsClient(config.TopicArn, config.RoleArn)
 	if err != nil {
 		errorMsg := "Failed to create SNS client for topic"
 		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
-		return &AlertDeliveryError{Message: errorMsg, Permanent: true}
+		return "", &AlertDeliveryError{Message: errorMsg, Permanent: true}
 	}
 
-	_, err = snsClient.Publish(snsMessageInput)
+	response, err := snsClient.Publish(snsMessageInput)
 	if err != nil {
+		// Publish failures (throttling, permissions being fixed, etc) are retried
 		errorMsg := "Failed to send message to SNS topic"
 		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
-		return &AlertDeliveryError{Message: errorMsg}
+		return "", &AlertDeliveryError{Message: errorMsg}
+	}
+	zap.L().Debug("published alert to SNS topic",
+		zap.String("topicArn", config.TopicArn),
+		zap.String("messageId", aws.StringValue(response.MessageId)))
+	return aws.StringValue(response.MessageId), nil
+}
+
+// generateSnsMessageAttributes returns the string attributes subscription filter policies can match on
+func generateSnsMessageAttributes(alert *alertmodels.Alert) map[string]*sns.MessageAttributeValue {
+	attributes := map[string]*sns.MessageAttributeValue{
+		"severity":   snsStringAttribute(alert.Severity),
+		"type":       snsStringAttribute(alert.Type),
+		"analysisId": snsStringAttribute(alert.AnalysisID),
+	}
+	if alert.AlertID != nil {
+		attributes["alertId"] = snsStringAttribute(*alert.AlertID)
+	}
+	if len(alert.LogTypes) > 0 {
+		// Filter policies match String.Array attributes if any of the values matches
+		if logTypes, err := jsoniter.MarshalToString(alert.LogTypes); err == nil {
+			attributes["logTypes"] = &sns.MessageAttributeValue{
+				DataType:    aws.String("String.Array"),
+				StringValue: aws.String(logTypes),
+			}
+		}
+	}
+	// SNS rejects attributes with empty values
+	for key, value := range attributes {
+		if aws.StringValue(value.StringValue) == "" {
+			delete(attributes, key)
+		}
+	}
+	return attributes
+}
+
+func snsStringAttribute(value string) *sns.MessageAttributeValue {
+	return &sns.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
 	}
-	return nil
 }
 
-func (client *OutputClient) getSnsClient(topicArn string) (snsiface.SNSAPI, error) {
+// getSnsClient returns a client for the topic region, assuming roleArn if one is configured.
+func (client *OutputClient) getSnsClient(topicArn, roleArn string) (snsiface.SNSAPI, error) {
 	parsedArn, err := arn.Parse(topicArn)
 	if err != nil {
 		zap.L().Error("failed to parse topic ARN", zap.Error(err))
 		return nil, err
 	}
-	snsClient, ok := client.snsClients[parsedArn.Region]
+
+	cacheKey := parsedArn.Region
+	if roleArn != "" {
+		cacheKey += "/" + roleArn
+	}
+	client.snsClientsLock.Lock()
+	defer client.snsClientsLock.Unlock()
+	snsClient, ok := client.snsClients[cacheKey]
 	if !ok {
-		snsClient = sns.New(client.session, aws.NewConfig().WithRegion(parsedArn.Region))
-		client.snsClients[parsedArn.Region] = snsClient
+		config := aws.NewConfig().WithRegion(parsedArn.Region)
+		if roleArn != "" {
+			config = config.WithCredentials(stscreds.NewCredentials(client.session, roleArn))
+		}
+		snsClient = sns.New(client.session, config)
+		client.snsClients[cacheKey] = snsClient
 	}
 	return snsClient, nil
 }
@@ -19,14 +19,19 @@ package outputs
  */
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -62,6 +67,7 @@ func TestSendSns(t *testing.T) {
 		Link:        "https://panther.io/policies/policyId",
 		Title:       "Policy Failure: policyName",
 		Tags:        []string{},
+		Context:     map[string]string{},
 	}
 
 	defaultSerializedMessage, err := jsoniter.MarshalToString(defaultMessage)
@@ -78,10 +84,88 @@ func TestSendSns(t *testing.T) {
 		Message:          &expectedSerializedSnsMessage,
 		MessageStructure: aws.String("json"),
 		Subject:          aws.String("Policy Failure: policyName"),
+		MessageAttributes: map[string]*sns.MessageAttributeValue{
+			"severity":   {DataType: aws.String("String"), StringValue: aws.String("severity")},
+			"analysisId": {DataType: aws.String("String"), StringValue: aws.String("policyId")},
+		},
 	}
 
-	client.On("Publish", expectedSnsPublishInput).Return(&sns.PublishOutput{}, nil)
-	result := outputClient.Sns(alert, snsOutputConfig)
+	client.On("Publish", expectedSnsPublishInput).Return(&sns.PublishOutput{MessageId: aws.String("messageId")}, nil)
+	messageID, result := outputClient.Sns(alert, snsOutputConfig)
 	assert.Nil(t, result)
+	assert.Equal(t, "messageId", messageID)
 	client.AssertExpectations(t)
 }
+
+func TestSendSnsPublishErrorIsRetryable(t *testing.T) {
+	client := &testutils.SnsMock{}
+	outputClient := &OutputClient{snsClients: map[string]snsiface.SNSAPI{"us-west-2": client}}
+	alert := &alertmodels.Alert{AnalysisID: "ruleId", Type: alertmodels.RuleType, Severity: "HIGH", AlertID: aws.String("alertId")}
+
+	client.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, errors.New("throttled"))
+	_, result := outputClient.Sns(alert, &outputmodels.SnsConfig{TopicArn: "arn:aws:sns:us-west-2:123456789012:test"})
+	require.NotNil(t, result)
+	assert.False(t, result.Permanent)
+	client.AssertExpectations(t)
+}
+
+func TestGenerateSnsMessageAttributes(t *testing.T) {
+	alert := &alertmodels.Alert{
+		AnalysisID: "ruleId",
+		Type:       alertmodels.RuleType,
+		Severity:   "HIGH",
+		AlertID:    aws.String("alertId"),
+	}
+	attributes := generateSnsMessageAttributes(alert)
+	require.Len(t, attributes, 4)
+	assert.Equal(t, "HIGH", *attributes["severity"].StringValue)
+	assert.Equal(t, "RULE", *attributes["type"].StringValue)
+	assert.Equal(t, "ruleId", *attributes["analysisId"].StringValue)
+	assert.Equal(t, "alertId", *attributes["alertId"].StringValue)
+	assert.Equal(t, "String", *attributes["alertId"].DataType)
+}
+
+func TestGenerateSnsMessageAttributesLogTypes(t *testing.T) {
+	alert := &alertmodels.Alert{
+		AnalysisID: "ruleId",
+		Type:       alertmodels.RuleType,
+		Severity:   "HIGH",
+		LogTypes:   []string{"AWS.CloudTrail", "AWS.VPCFlow"},
+	}
+	attributes := generateSnsMessageAttributes(alert)
+	require.Len(t, attributes, 4)
+	assert.Equal(t, "String.Array", *attributes["logTypes"].DataType)
+	assert.Equal(t, `["AWS.CloudTrail","AWS.VPCFlow"]`, *attributes["logTypes"].StringValue)
+}
+
+func TestGetSnsClientCachedPerRole(t *testing.T) {
+	outputClient := New(session.Must(session.NewSession()))
+	topicArn := "arn:aws:sns:us-west-2:123456789012:test"
+
+	defaultClient, err := outputClient.getSnsClient(topicArn, "")
+	require.NoError(t, err)
+	roleClient, err := outputClient.getSnsClient(topicArn, "arn:aws:iam::123456789012:role/PantherAlertDeliveryRole-sns")
+	require.NoError(t, err)
+	assert.NotSame(t, defaultClient, roleClient)
+
+	cached, err := outputClient.getSnsClient(topicArn, "arn:aws:iam::123456789012:role/PantherAlertDeliveryRole-sns")
+	require.NoError(t, err)
+	assert.Same(t, roleClient, cached)
+	assert.Len(t, outputClient.snsClients, 2)
+}
+
+func TestGetSnsClientConcurrent(t *testing.T) {
+	outputClient := New(session.Must(session.NewSession()))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topicArn := fmt.Sprintf("arn:aws:sns:us-west-%d:123456789012:test", i%2+1)
+			_, err := outputClient.getSnsClient(topicArn, "")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, outputClient.snsClients, 2)
+}
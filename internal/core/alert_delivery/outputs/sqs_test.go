@@ -57,6 +57,7 @@ func TestSendSqs(t *testing.T) {
 		Link:        "https://panther.io/policies/policyId",
 		Title:       "Policy Failure: policyName",
 		Tags:        []string{},
+		Context:     map[string]string{},
 	}
 	expectedSerializedSqsMessage, err := jsoniter.MarshalToString(expectedSqsMessage)
 	require.NoError(t, err)
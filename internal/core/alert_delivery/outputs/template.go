@@ -0,0 +1,104 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// messageTemplateData is what custom message templates are rendered with, for example:
+//
+//	[{{.Severity}}] {{.Title}}
+//	{{range .Fields}}{{.Name}}: {{.Value}}
+//	{{end}}{{if .Runbook}}Runbook: {{.Runbook}}
+//	{{end}}{{.Link}}
+//
+// Missing context keys (e.g. {{.Context.team}}) render as empty strings.
+type messageTemplateData struct {
+	// Severity is one of INFO LOW MEDIUM HIGH CRITICAL
+	Severity string
+	// Emoji of the severity, e.g. "🔴" for HIGH
+	Emoji string
+	// Title is the title of the built-in messages, e.g. "New Alert: AWS Root Login"
+	Title string
+	// Description of the rule or policy, empty if not set
+	Description string
+	// Runbook is the triage information of the rule or policy, empty if not set
+	Runbook string
+	// Link to the alert (or policy) in the Panther UI
+	Link string
+	// Fields are the named details of the alert, in the order they are shown in emails
+	Fields []alertField
+	// Tags of the rule or policy
+	Tags []string
+	// Context is the organization-specific fields of the alert's account
+	Context map[string]string
+}
+
+// messageTemplate is satisfied by both text and HTML templates.
+type messageTemplate interface {
+	Execute(io.Writer, interface{}) error
+}
+
+// parseTextMessageTemplate parses a custom template for plain text messages (e.g. Slack, SNS).
+func parseTextMessageTemplate(text string) (messageTemplate, *AlertDeliveryError) {
+	tmpl, err := template.New("message").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, &AlertDeliveryError{Message: "invalid message template: " + err.Error(), Permanent: true}
+	}
+	return tmpl, nil
+}
+
+// parseHTMLMessageTemplate parses a custom template for HTML messages (emails), the alert fields are escaped.
+func parseHTMLMessageTemplate(text string) (messageTemplate, *AlertDeliveryError) {
+	tmpl, err := htmltemplate.New("message").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, &AlertDeliveryError{Message: "invalid message template: " + err.Error(), Permanent: true}
+	}
+	return tmpl, nil
+}
+
+// renderMessageTemplate renders the alert through a custom template.
+//
+// Errors are permanent: the same template would fail the same way for this alert if it was sent again.
+func renderMessageTemplate(tmpl messageTemplate, alert *alertmodels.Alert) (string, *AlertDeliveryError) {
+	data := messageTemplateData{
+		Severity:    alert.Severity,
+		Emoji:       alertmodels.SeverityLevels[alert.Severity].Emoji,
+		Title:       generateAlertTitle(alert),
+		Description: aws.StringValue(alert.AnalysisDescription),
+		Runbook:     aws.StringValue(alert.Runbook),
+		Link:        generateURL(alert),
+		Fields:      generateAlertFields(alert),
+		Tags:        alert.Tags,
+		Context:     alert.Context,
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", &AlertDeliveryError{Message: "failed to render message template: " + err.Error(), Permanent: true}
+	}
+	return message.String(), nil
+}
@@ -0,0 +1,128 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const testMessageTemplate = `[{{.Severity}}] {{.Title}}
+{{range .Fields}}{{.Name}}: {{.Value}}
+{{end}}{{if .Runbook}}Runbook: {{.Runbook}}
+{{end}}{{.Link}}{{.Context.team}}`
+
+func TestRenderMessageTemplate(t *testing.T) {
+	tmpl, err := parseTextMessageTemplate(testMessageTemplate)
+	require.Nil(t, err)
+	message, err := renderMessageTemplate(tmpl, emailAlert())
+	require.Nil(t, err)
+	assert.Equal(t, `[HIGH] New Alert: Suspicious Login
+Severity: HIGH
+Rule: Suspicious Login
+Alert ID: alert-id
+Created: 2020-01-02T03:04:05Z
+Tags: iam, login
+costCenter: 1234
+environment: prod
+Runbook: Check <b>the</b> source IP & reset the password
+https://panther.io/alerts/alert-id`, message)
+}
+
+func TestRenderHTMLMessageTemplate(t *testing.T) {
+	tmpl, err := parseHTMLMessageTemplate(`<p>{{.Runbook}}</p><a href="{{.Link}}">{{.Title}}</a>`)
+	require.Nil(t, err)
+	message, err := renderMessageTemplate(tmpl, emailAlert())
+	require.Nil(t, err)
+	assert.Equal(t, `<p>Check &lt;b&gt;the&lt;/b&gt; source IP &amp; reset the password</p>`+
+		`<a href="https://panther.io/alerts/alert-id">New Alert: Suspicious Login</a>`, message)
+}
+
+func TestParseMessageTemplateInvalid(t *testing.T) {
+	_, err := parseTextMessageTemplate("{{.Title")
+	require.NotNil(t, err)
+	assert.True(t, err.Permanent)
+	assert.Contains(t, err.Message, "invalid message template: ")
+
+	_, err = parseHTMLMessageTemplate("{{range .Fields}}")
+	require.NotNil(t, err)
+	assert.True(t, err.Permanent)
+}
+
+func TestRenderMessageTemplateError(t *testing.T) {
+	tmpl, err := parseTextMessageTemplate("{{.Severity}} {{.AlertName}}")
+	require.Nil(t, err)
+	message, err := renderMessageTemplate(tmpl, emailAlert())
+	assert.Empty(t, message)
+	require.NotNil(t, err)
+	assert.True(t, err.Permanent)
+	assert.Contains(t, err.Message, "failed to render message template: ")
+	assert.Contains(t, err.Message, "AlertName")
+}
+
+func TestSlackMessageTemplate(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	config := &outputmodels.SlackConfig{
+		WebhookURL:      "slack-channel-url",
+		MessageTemplate: "{{.Emoji}} {{.Severity}}: <{{.Link}}|{{.Title}}>",
+	}
+
+	httpWrapper.On("post", &PostInput{
+		url:  "slack-channel-url",
+		body: map[string]interface{}{"text": "🔴 HIGH: <https://panther.io/alerts/alert-id|New Alert: Suspicious Login>"},
+	}).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Slack(emailAlert(), config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestSlackMessageTemplateBroken(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	for _, messageTemplate := range []string{"{{if .Runbook}}", "{{.Context.team.name}}"} {
+		err := client.Slack(emailAlert(), &outputmodels.SlackConfig{WebhookURL: "slack-channel-url", MessageTemplate: messageTemplate})
+		require.NotNil(t, err, messageTemplate)
+		assert.True(t, err.Permanent)
+	}
+	httpWrapper.AssertNotCalled(t, "post", mock.Anything)
+}
+
+func TestSendEmailMessageTemplate(t *testing.T) {
+	client := &mockSes{}
+	outputClient := &OutputClient{sesClients: map[string]sesiface.SESAPI{"": client}}
+	config := &outputmodels.EmailConfig{MessageTemplate: `<h1>{{.Title}}</h1>`}
+
+	client.On("SendEmail", mock.Anything).Return(&ses.SendEmailOutput{MessageId: aws.String("ses-message-id")}, nil)
+	_, err := outputClient.Email(emailAlert(), config)
+	require.Nil(t, err)
+	input := client.Calls[0].Arguments.Get(0).(*ses.SendEmailInput)
+	assert.Equal(t, "<h1>New Alert: Suspicious Login</h1>", aws.StringValue(input.Message.Body.Html.Data))
+	// The plain text part is unchanged
+	assert.Equal(t, generateDetailedAlertMessage(emailAlert()), aws.StringValue(input.Message.Body.Text.Data))
+}
@@ -0,0 +1,61 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+func TestWebhookAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, err := time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	require.NoError(t, err)
+	alert := &alertmodels.Alert{
+		AnalysisID: "policyId",
+		CreatedAt:  createdAtTime,
+		Severity:   "INFO",
+	}
+	config := &outputmodels.WebhookConfig{
+		WebhookURL:        "webhook-url",
+		SigningSecret:     "secret",
+		Headers:           map[string]string{"X-Custom": "value"},
+		HTTPTimeoutConfig: outputmodels.HTTPTimeoutConfig{TimeoutSeconds: 5},
+	}
+
+	expectedPostInput := &PostInput{
+		url:           "webhook-url",
+		body:          generateNotificationFromAlert(alert),
+		headers:       map[string]string{"X-Custom": "value"},
+		signingSecret: "secret",
+		timeout:       5 * time.Second,
+	}
+
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.Webhook(alert, config))
+	httpWrapper.AssertExpectations(t)
+}
@@ -103,6 +103,14 @@ func redactOutput(outputConfig *models.OutputConfig) {
 	if outputConfig.CustomWebhook != nil {
 		outputConfig.CustomWebhook.WebhookURL = redacted
 	}
+	if outputConfig.Webhook != nil {
+		outputConfig.Webhook.WebhookURL = redacted
+		outputConfig.Webhook.SigningSecret = redacted
+		// Custom headers usually carry credentials, e.g. an Authorization header
+		for name := range outputConfig.Webhook.Headers {
+			outputConfig.Webhook.Headers[name] = redacted
+		}
+	}
 }
 
 func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
@@ -136,6 +144,12 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 	if outputConfig.CustomWebhook != nil {
 		return aws.String("customwebhook"), nil
 	}
+	if outputConfig.Webhook != nil {
+		return aws.String("webhook"), nil
+	}
+	if outputConfig.Email != nil {
+		return aws.String("email"), nil
+	}
 
 	return nil, errors.New("no valid output configuration specified for alert output")
 }
@@ -143,6 +157,7 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 // mergeConfigs combines an old config with a new config based on the following rules:
 // 1. For every value in the new config, use it
 // 2. For every value in the old config, keep it if it is not overwritten by the new config
+// 3. Nested maps (e.g. webhook headers) are replaced by the new map, keeping the old values of its redacted entries
 func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConfig, error) {
 	// Convert the old config into bytes so we can merge it with the new config
 	oldBytes, err := jsoniter.Marshal(oldConfig)
@@ -152,7 +167,7 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 		}
 	}
 	// Turn the bytes into a map so we can work with it more easily
-	var oldMap map[string]map[string]string
+	var oldMap map[string]map[string]interface{}
 	err = jsoniter.Unmarshal(oldBytes, &oldMap)
 	if err != nil {
 		return nil, &genericapi.InternalError{
@@ -167,7 +182,7 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 			Message: "Unable to extract the new configuration",
 		}
 	}
-	var newMap map[string]map[string]string
+	var newMap map[string]map[string]interface{}
 	err = jsoniter.Unmarshal(newBytes, &newMap)
 	if err != nil {
 		return nil, &genericapi.InternalError{
@@ -181,6 +196,14 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 			if configValue == "" {
 				continue
 			}
+			if newEntries, ok := configValue.(map[string]interface{}); ok {
+				oldEntries, _ := oldMap[configType][configKey].(map[string]interface{})
+				for name, value := range newEntries {
+					if value == "" {
+						newEntries[name] = oldEntries[name]
+					}
+				}
+			}
 			oldMap[configType][configKey] = configValue
 		}
 	}
@@ -248,6 +271,14 @@ func validateConfigByType(config *models.OutputConfig, outputType *string) error
 		if config.CustomWebhook.WebhookURL != "" {
 			return nil
 		}
+	case "webhook":
+		if config.Webhook.WebhookURL != "" && config.Webhook.SigningSecret != "" {
+			return nil
+		}
+	case "email":
+		if config.Email.FromAddress != "" && len(config.Email.Recipients) != 0 {
+			return nil
+		}
 	}
 
 	return errors.New("invalid output configuration specified for alert output, missing required fields")
@@ -0,0 +1,112 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestMergeConfigsWebhook(t *testing.T) {
+	oldConfig := &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			WebhookURL:        "https://example.com/hook",
+			SigningSecret:     "secret",
+			Headers:           map[string]string{"X-Custom": "value"},
+			HTTPTimeoutConfig: models.HTTPTimeoutConfig{TimeoutSeconds: 5},
+		},
+	}
+	// The secret is redacted when the output is read, so it is left blank on update
+	newConfig := &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			WebhookURL:        "https://example.com/new-hook",
+			HTTPTimeoutConfig: models.HTTPTimeoutConfig{TimeoutSeconds: 10},
+		},
+	}
+
+	result, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			WebhookURL:        "https://example.com/new-hook",
+			SigningSecret:     "secret",
+			Headers:           map[string]string{"X-Custom": "value"},
+			HTTPTimeoutConfig: models.HTTPTimeoutConfig{TimeoutSeconds: 10},
+		},
+	}, result)
+}
+
+func TestMergeConfigsWebhookHeaders(t *testing.T) {
+	oldConfig := &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			WebhookURL:    "https://example.com/hook",
+			SigningSecret: "secret",
+			Headers:       map[string]string{"Authorization": "Bearer token", "X-Removed": "value"},
+		},
+	}
+	// Header values are redacted when the output is read, the headers listed on update are kept
+	newConfig := &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			Headers: map[string]string{"Authorization": "", "X-New": "new"},
+		},
+	}
+
+	result, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "X-New": "new"}, result.Webhook.Headers)
+	assert.Equal(t, "secret", result.Webhook.SigningSecret)
+}
+
+func TestRedactWebhookOutput(t *testing.T) {
+	config := &models.OutputConfig{
+		Webhook: &models.WebhookConfig{
+			WebhookURL:    "https://example.com/hook",
+			SigningSecret: "secret",
+			Headers:       map[string]string{"Authorization": "Bearer token"},
+		},
+	}
+	redactOutput(config)
+	assert.Equal(t, &models.WebhookConfig{Headers: map[string]string{"Authorization": ""}}, config.Webhook)
+}
+
+func TestValidateWebhookConfig(t *testing.T) {
+	config := &models.OutputConfig{Webhook: &models.WebhookConfig{WebhookURL: "https://example.com/hook"}}
+	outputType, err := getOutputType(config)
+	require.NoError(t, err)
+	assert.Equal(t, "webhook", *outputType)
+	assert.Error(t, validateConfigByType(config, outputType))
+
+	config.Webhook.SigningSecret = "secret"
+	assert.NoError(t, validateConfigByType(config, outputType))
+}
+
+func TestValidateEmailConfig(t *testing.T) {
+	config := &models.OutputConfig{Email: &models.EmailConfig{FromAddress: "alerts@example.com"}}
+	outputType, err := getOutputType(config)
+	require.NoError(t, err)
+	assert.Equal(t, "email", *outputType)
+	assert.Error(t, validateConfigByType(config, outputType))
+
+	config.Email.Recipients = []string{"security@example.com"}
+	assert.NoError(t, validateConfigByType(config, outputType))
+}
@@ -257,6 +257,7 @@ func generateNewIntegration(input *models.PutIntegrationInput) *models.SourceInt
 		metadata.LogTypes = input.LogTypes
 		metadata.StackName = getStackName(input.IntegrationType, input.IntegrationLabel)
 		metadata.LogProcessingRole = generateLogProcessingRoleArn(input.AWSAccountID, input.IntegrationLabel)
+		metadata.LogProcessingRoleSessionTags = input.LogProcessingRoleSessionTags
 	case models.IntegrationTypeSqs:
 		metadata.SqsConfig = &models.SqsConfig{
 			S3Bucket:             env.InputDataBucketName,
@@ -101,6 +101,7 @@ func normalizeIntegration(item *ddb.Integration, input *models.UpdateIntegration
 		item.S3Prefix = input.S3Prefix
 		item.KmsKey = input.KmsKey
 		item.LogTypes = input.LogTypes
+		item.LogProcessingRoleSessionTags = input.LogProcessingRoleSessionTags
 	case models.IntegrationTypeSqs:
 		item.IntegrationLabel = input.IntegrationLabel
 		item.SqsConfig.LogTypes = input.SqsConfig.LogTypes
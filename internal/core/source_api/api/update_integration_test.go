@@ -100,24 +100,29 @@ func TestUpdateIntegrationSettingsAwsS3Type(t *testing.T) {
 	mockAthena.On("GetQueryResults", mock.Anything).Return(&athena.GetQueryResultsOutput{}, nil).Twice()
 
 	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
-		S3Bucket: "test-bucket-1",
-		S3Prefix: "prefix/",
-		KmsKey:   "arn:aws:kms:us-west-2:111111111111:key/27803c7e-9fa5-4fcb-9525-ee11c953d329",
-		LogTypes: []string{"AWS.VPCFlow"},
+		S3Bucket:                     "test-bucket-1",
+		S3Prefix:                     "prefix/",
+		KmsKey:                       "arn:aws:kms:us-west-2:111111111111:key/27803c7e-9fa5-4fcb-9525-ee11c953d329",
+		LogTypes:                     []string{"AWS.VPCFlow"},
+		LogProcessingRoleSessionTags: map[string]string{"panther:integration": "vpc"},
 	})
 
 	expected := &models.SourceIntegration{
 		SourceIntegrationMetadata: models.SourceIntegrationMetadata{
-			IntegrationID:   testIntegrationID,
-			IntegrationType: models.IntegrationTypeAWS3,
-			S3Bucket:        "test-bucket-1",
-			S3Prefix:        "prefix/",
-			KmsKey:          "arn:aws:kms:us-west-2:111111111111:key/27803c7e-9fa5-4fcb-9525-ee11c953d329",
-			LogTypes:        []string{"AWS.VPCFlow"},
+			IntegrationID:                testIntegrationID,
+			IntegrationType:              models.IntegrationTypeAWS3,
+			S3Bucket:                     "test-bucket-1",
+			S3Prefix:                     "prefix/",
+			KmsKey:                       "arn:aws:kms:us-west-2:111111111111:key/27803c7e-9fa5-4fcb-9525-ee11c953d329",
+			LogTypes:                     []string{"AWS.VPCFlow"},
+			LogProcessingRoleSessionTags: map[string]string{"panther:integration": "vpc"},
 		},
 	}
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
+	// The session tags are stored with the source
+	putItem := mockClient.Calls[1].Arguments[0].(*dynamodb.PutItemInput)
+	assert.Equal(t, "vpc", *putItem.Item["logProcessingRoleSessionTags"].M["panther:integration"].S)
 	mockClient.AssertExpectations(t)
 }
 
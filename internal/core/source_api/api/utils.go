@@ -43,6 +43,7 @@ func integrationToItem(input *models.SourceIntegration) *ddb.Integration {
 		item.LogTypes = input.LogTypes
 		item.StackName = input.StackName
 		item.LogProcessingRole = generateLogProcessingRoleArn(input.AWSAccountID, input.IntegrationLabel)
+		item.LogProcessingRoleSessionTags = input.LogProcessingRoleSessionTags
 	case models.IntegrationTypeAWSScan:
 		item.AWSAccountID = input.AWSAccountID
 		item.CWEEnabled = input.CWEEnabled
@@ -87,6 +88,7 @@ func itemToIntegration(item *ddb.Integration) *models.SourceIntegration {
 		integration.LogTypes = item.LogTypes
 		integration.StackName = item.StackName
 		integration.LogProcessingRole = item.LogProcessingRole
+		integration.LogProcessingRoleSessionTags = item.LogProcessingRoleSessionTags
 	case models.IntegrationTypeAWSScan:
 		integration.AWSAccountID = item.AWSAccountID
 		integration.CWEEnabled = item.CWEEnabled
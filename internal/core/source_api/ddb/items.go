@@ -44,6 +44,8 @@ type Integration struct {
 	LogTypes          []string `json:"logTypes,omitempty" dynamodbav:",stringset"`
 	StackName         string   `json:"stackName,omitempty"`
 	LogProcessingRole string   `json:"logProcessingRole,omitempty"`
+	// Session tags passed when assuming the log processing role
+	LogProcessingRoleSessionTags map[string]string `json:"logProcessingRoleSessionTags,omitempty"`
 
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 }
@@ -214,6 +214,8 @@ func (h *Handler) sendAlertNotification(rule *ruleModel.Rule, alertDedup *AlertD
 		Type:         alertModel.RuleType,
 		Title:        aws.String(getAlertTitle(rule, alertDedup)),
 		Version:      &alertDedup.RuleVersion,
+		LogTypes:     alertDedup.LogTypes,
+		AccountID:    getAlertAccountID(alertDedup),
 	}
 
 	msgBody, err := jsoniter.MarshalToString(alertNotification)
@@ -243,6 +245,14 @@ func getAlertTitle(rule *ruleModel.Rule, alertDedup *AlertDedupEvent) string {
 	return string(rule.ID)
 }
 
+// getAlertAccountID returns the AWS account of the alert if all of its events are from the same account
+func getAlertAccountID(alertDedup *AlertDedupEvent) *string {
+	if len(alertDedup.AWSAccountIDs) != 1 {
+		return nil
+	}
+	return aws.String(alertDedup.AWSAccountIDs[0])
+}
+
 func getRuleDisplayName(rule *ruleModel.Rule) *string {
 	if len(rule.DisplayName) > 0 {
 		return aws.String(string(rule.DisplayName))
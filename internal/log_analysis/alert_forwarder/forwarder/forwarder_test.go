@@ -117,6 +117,7 @@ func TestHandleStoreAndSendNotification(t *testing.T) {
 		Type:                alertModel.RuleType,
 		AlertID:             aws.String("b25dc23fb2a0b362da8428dbec1381a8"),
 		Title:               newAlertDedupEvent.GeneratedTitle,
+		LogTypes:            newAlertDedupEvent.LogTypes,
 	}
 	expectedMarshaledAlertNotification, err := jsoniter.MarshalToString(expectedAlertNotification)
 	require.NoError(t, err)
@@ -203,6 +204,7 @@ func TestHandleStoreAndSendNotificationNoRuleDisplayNameNoTitle(t *testing.T) {
 		Type:                alertModel.RuleType,
 		AlertID:             aws.String("b25dc23fb2a0b362da8428dbec1381a8"),
 		Title:               aws.String(newAlertDedupEventWithoutTitle.RuleID),
+		LogTypes:            newAlertDedupEventWithoutTitle.LogTypes,
 	}
 	expectedMarshaledAlertNotification, err := jsoniter.MarshalToString(expectedAlertNotification)
 	require.NoError(t, err)
@@ -286,6 +288,7 @@ func TestHandleStoreAndSendNotificationNoGeneratedTitle(t *testing.T) {
 		Type:                alertModel.RuleType,
 		AlertID:             aws.String("b25dc23fb2a0b362da8428dbec1381a8"),
 		Title:               aws.String("DisplayName"),
+		LogTypes:            newAlertDedupEvent.LogTypes,
 	}
 	expectedMarshaledAlertNotification, err := jsoniter.MarshalToString(expectedAlertNotification)
 	require.NoError(t, err)
@@ -373,6 +376,7 @@ func TestHandleStoreAndSendNotificationNilOldDedup(t *testing.T) {
 		Type:                alertModel.RuleType,
 		AlertID:             aws.String("b25dc23fb2a0b362da8428dbec1381a8"),
 		Title:               newAlertDedupEvent.GeneratedTitle,
+		LogTypes:            newAlertDedupEvent.LogTypes,
 	}
 	expectedMarshaledAlertNotification, err := jsoniter.MarshalToString(expectedAlertNotification)
 	require.NoError(t, err)
@@ -599,3 +603,46 @@ func generateResponse(body interface{}, httpCode int) *http.Response {
 	serializedBody, _ := jsoniter.MarshalToString(body)
 	return &http.Response{StatusCode: httpCode, Body: ioutil.NopCloser(strings.NewReader(serializedBody))}
 }
+
+func TestHandleSendNotificationAccountID(t *testing.T) {
+	t.Parallel()
+	ddbMock := &testutils.DynamoDBMock{}
+	sqsMock := &testutils.SqsMock{}
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient := &http.Client{Transport: mockRoundTripper}
+	policyConfig := policiesclient.DefaultTransportConfig().
+		WithHost("host").
+		WithBasePath("path")
+	policyClient := policiesclient.NewHTTPClientWithConfig(nil, policyConfig)
+	handler := &Handler{
+		AlertTable:       "alertsTable",
+		AlertingQueueURL: "queueUrl",
+		Cache:            NewCache(httpClient, policyClient),
+		DdbClient:        ddbMock,
+		SqsClient:        sqsMock,
+	}
+
+	singleAccountDedupEvent := *newAlertDedupEvent
+	singleAccountDedupEvent.AWSAccountIDs = []string{"123456789012"}
+	multipleAccountsDedupEvent := *newAlertDedupEvent
+	multipleAccountsDedupEvent.AWSAccountIDs = []string{"123456789012", "210987654321"}
+
+	var notifications []*alertModel.Alert
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(testRuleResponse, http.StatusOK), nil).Once()
+	ddbMock.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Twice()
+	sqsMock.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Twice().Run(func(args mock.Arguments) {
+		notification := &alertModel.Alert{}
+		require.NoError(t, jsoniter.UnmarshalFromString(*args.Get(0).(*sqs.SendMessageInput).MessageBody, notification))
+		notifications = append(notifications, notification)
+	})
+
+	require.NoError(t, handler.Do(oldAlertDedupEvent, &singleAccountDedupEvent))
+	require.NoError(t, handler.Do(oldAlertDedupEvent, &multipleAccountsDedupEvent))
+
+	require.Len(t, notifications, 2)
+	assert.Equal(t, aws.String("123456789012"), notifications[0].AccountID)
+	assert.Nil(t, notifications[1].AccountID) // the alert is not specific to one account
+	ddbMock.AssertExpectations(t)
+	sqsMock.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+}
@@ -42,6 +42,7 @@ type AlertDedupEvent struct {
 	UpdateTime          time.Time `dynamodbav:"updateTime,string"`
 	EventCount          int64     `dynamodbav:"eventCount,number"`
 	LogTypes            []string  `dynamodbav:"logTypes,stringset"`
+	AWSAccountIDs       []string  `dynamodbav:"-"` // The AWS accounts of the matched events, if any were found in them.
 	GeneratedTitle      *string   `dynamodbav:"-"` // The title that was generated dynamically using Python. Might be null.
 	AlertCount          int64     `dynamodbav:"-"` // There is no need to store this item in DDB
 }
@@ -127,6 +128,11 @@ func FromDynamodDBAttribute(input map[string]events.DynamoDBAttributeValue) (eve
 	if generatedTitle != nil {
 		result.GeneratedTitle = aws.String(generatedTitle.String())
 	}
+
+	awsAccountIDs := getOptionalAttribute("awsAccountIds", input)
+	if awsAccountIDs != nil {
+		result.AWSAccountIDs = awsAccountIDs.StringSet()
+	}
 	return result, nil
 }
 
@@ -37,6 +37,7 @@ func TestConvertAttribute(t *testing.T) {
 		UpdateTime:          time.Unix(1582285280, 0).UTC(),
 		EventCount:          100,
 		LogTypes:            []string{"Log.Type.1", "Log.Type.2"},
+		AWSAccountIDs:       []string{"123456789012"},
 		GeneratedTitle:      aws.String("test title"),
 	}
 
@@ -65,6 +66,7 @@ func TestConvertAttributeWithoutOptionalFields(t *testing.T) {
 
 	ddbItem := getNewTestCase()
 	delete(ddbItem, "title")
+	delete(ddbItem, "awsAccountIds")
 	alertDedupEvent, err := FromDynamodDBAttribute(ddbItem)
 	require.NoError(t, err)
 	require.Equal(t, expectedAlertDedup, alertDedupEvent)
@@ -153,6 +155,7 @@ func getNewTestCase() map[string]events.DynamoDBAttributeValue {
 		"eventCount":        events.NewNumberAttribute("100"),
 		"logTypes":          events.NewStringSetAttribute([]string{"Log.Type.1", "Log.Type.2"}),
 		"title":             events.NewStringAttribute("test title"),
+		"awsAccountIds":     events.NewStringSetAttribute([]string{"123456789012"}),
 		"status":            events.NewStringAttribute("OPEN"),
 	}
 }
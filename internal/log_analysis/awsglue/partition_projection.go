@@ -0,0 +1,115 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// PartitionProjection describes how Athena computes the values of a partition key instead of listing partitions.
+// See https://docs.aws.amazon.com/athena/latest/ug/partition-projection.html
+type PartitionProjection struct {
+	Type  string // "date" or "integer"
+	Range string // the lowest and highest values, e.g. "2020,NOW" or "1,12"
+	// Date projections only
+	Format       string // the Java date format of the values, e.g. "yyyy"
+	Interval     int
+	IntervalUnit string // e.g. "YEARS"
+	// Integer projections only, values are zero padded to this many digits (0 means no padding)
+	Digits int
+}
+
+// WithPartitionProjection returns a copy of the table metadata which configures partition projection,
+// for data written since the beginning of fromYear.
+//
+// The projected partitions match the S3 layout of the table, see GlueTableTimebin.PartitionS3PathFromTime
+func (gm *GlueTableMetadata) WithPartitionProjection(fromYear int) *GlueTableMetadata {
+	projected := *gm
+	projected.projectionFromYear = fromYear
+	return &projected
+}
+
+// HasPartitionProjection returns true if the table configures partition projection
+func (gm *GlueTableMetadata) HasPartitionProjection() bool {
+	return gm.projectionFromYear > 0
+}
+
+// The projection of a partition key, nil if the table does not configure partition projection
+func (gm *GlueTableMetadata) partitionProjection(key string) *PartitionProjection {
+	if !gm.HasPartitionProjection() {
+		return nil
+	}
+	switch key {
+	case "year":
+		return &PartitionProjection{
+			Type:         "date",
+			Range:        strconv.Itoa(gm.projectionFromYear) + ",NOW",
+			Format:       "yyyy",
+			Interval:     1,
+			IntervalUnit: "YEARS",
+		}
+	case "month":
+		return &PartitionProjection{Type: "integer", Range: "1,12", Digits: 2}
+	case "day":
+		return &PartitionProjection{Type: "integer", Range: "1,31", Digits: 2}
+	case "hour":
+		return &PartitionProjection{Type: "integer", Range: "0,23", Digits: 2}
+	default:
+		return nil
+	}
+}
+
+// The table properties which configure partition projection, nil if the table does not configure it
+func (gm *GlueTableMetadata) partitionProjectionParameters(bucketName string) map[string]*string {
+	if !gm.HasPartitionProjection() {
+		return nil
+	}
+
+	params := map[string]*string{
+		"projection.enabled": aws.String("true"),
+	}
+	var template strings.Builder
+	template.WriteString("s3://" + bucketName + "/" + gm.prefix)
+	for _, key := range gm.PartitionKeys() {
+		template.WriteString(key.Name + "=${" + key.Name + "}/")
+
+		projection := key.Projection
+		if projection == nil {
+			continue
+		}
+		prefix := "projection." + key.Name + "."
+		params[prefix+"type"] = aws.String(projection.Type)
+		params[prefix+"range"] = aws.String(projection.Range)
+		if projection.Format != "" {
+			params[prefix+"format"] = aws.String(projection.Format)
+		}
+		if projection.Interval > 0 {
+			params[prefix+"interval"] = aws.String(strconv.Itoa(projection.Interval))
+			params[prefix+"interval.unit"] = aws.String(projection.IntervalUnit)
+		}
+		if projection.Digits > 0 {
+			params[prefix+"digits"] = aws.String(strconv.Itoa(projection.Digits))
+		}
+	}
+	params["storage.location.template"] = aws.String(template.String())
+	return params
+}
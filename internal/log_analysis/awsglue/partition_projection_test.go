@@ -0,0 +1,92 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+)
+
+func TestPartitionProjectionHourly(t *testing.T) {
+	gm := NewGlueTableMetadata(models.LogData, "My.Logs.Type", "description", GlueTableHourly, partitionTestEvent{})
+	projected := gm.WithPartitionProjection(2020)
+	assert.False(t, gm.HasPartitionProjection())
+	assert.True(t, projected.HasPartitionProjection())
+
+	assert.Equal(t, []PartitionKey{
+		{Name: "year", Type: "int", Projection: &PartitionProjection{
+			Type: "date", Range: "2020,NOW", Format: "yyyy", Interval: 1, IntervalUnit: "YEARS",
+		}},
+		{Name: "month", Type: "int", Projection: &PartitionProjection{Type: "integer", Range: "1,12", Digits: 2}},
+		{Name: "day", Type: "int", Projection: &PartitionProjection{Type: "integer", Range: "1,31", Digits: 2}},
+		{Name: "hour", Type: "int", Projection: &PartitionProjection{Type: "integer", Range: "0,23", Digits: 2}},
+	}, projected.PartitionKeys())
+
+	assert.Equal(t, map[string]*string{
+		"projection.enabled":            aws.String("true"),
+		"projection.year.type":          aws.String("date"),
+		"projection.year.range":         aws.String("2020,NOW"),
+		"projection.year.format":        aws.String("yyyy"),
+		"projection.year.interval":      aws.String("1"),
+		"projection.year.interval.unit": aws.String("YEARS"),
+		"projection.month.type":         aws.String("integer"),
+		"projection.month.range":        aws.String("1,12"),
+		"projection.month.digits":       aws.String("2"),
+		"projection.day.type":           aws.String("integer"),
+		"projection.day.range":          aws.String("1,31"),
+		"projection.day.digits":         aws.String("2"),
+		"projection.hour.type":          aws.String("integer"),
+		"projection.hour.range":         aws.String("0,23"),
+		"projection.hour.digits":        aws.String("2"),
+		"storage.location.template": aws.String(
+			"s3://testbucket/logs/my_logs_type/year=${year}/month=${month}/day=${day}/hour=${hour}/"),
+	}, projected.glueTableInput("testbucket").Parameters)
+
+	// The rule table is projected as well
+	assert.True(t, projected.RuleTable().HasPartitionProjection())
+}
+
+func TestPartitionProjectionDaily(t *testing.T) {
+	gm := NewGlueTableMetadata(models.LogData, "My.Logs.Type", "description", GlueTableDaily, partitionTestEvent{}).
+		WithPartitionProjection(2019)
+	params := gm.glueTableInput("testbucket").Parameters
+	assert.NotContains(t, params, "projection.hour.type")
+	assert.Equal(t, "s3://testbucket/logs/my_logs_type/year=${year}/month=${month}/day=${day}/",
+		aws.StringValue(params["storage.location.template"]))
+}
+
+func TestPartitionProjectionDisabled(t *testing.T) {
+	gm := NewGlueTableMetadata(models.LogData, "My.Logs.Type", "description", GlueTableHourly, partitionTestEvent{})
+	for _, key := range gm.PartitionKeys() {
+		assert.Nil(t, key.Projection)
+	}
+	assert.Nil(t, gm.glueTableInput("testbucket").Parameters)
+
+	// Enabling projection changes the signature, so existing tables are updated
+	sig, err := gm.Signature()
+	require.NoError(t, err)
+	projectedSig, err := gm.WithPartitionProjection(2020).Signature()
+	require.NoError(t, err)
+	assert.NotEqual(t, sig, projectedSig)
+}
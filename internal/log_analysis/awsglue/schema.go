@@ -45,6 +45,10 @@ var (
 	// MaxCommentLength is the maximum size for a column comment (clip if larger), public var so it can be set to control output
 	MaxCommentLength = DefaultMaxCommentLength
 
+	// The types holding unparsed JSON, the JSON is stored as is in string columns
+	rawJSONType      = reflect.TypeOf(jsoniter.RawMessage{})
+	rawJSONArrayType = reflect.TypeOf([]jsoniter.RawMessage{})
+
 	// GlueMappings for custom Panther types.
 	GlueMappings = []CustomMapping{
 		{
@@ -52,11 +56,11 @@ var (
 			To:   GlueTimestampType,
 		},
 		{
-			From: reflect.TypeOf(jsoniter.RawMessage{}),
+			From: rawJSONType,
 			To:   GlueStringType,
 		},
 		{
-			From: reflect.TypeOf([]jsoniter.RawMessage{}),
+			From: rawJSONArrayType,
 			To:   ArrayOf(GlueStringType),
 		},
 		{
@@ -184,10 +188,56 @@ func MapOf(key, typ string) string {
 }
 
 type Column struct {
-	Name     string
-	Type     string // this is the Glue type
-	Comment  string
-	Required bool
+	Name      string
+	Type      string // this is the Glue type
+	Comment   string
+	Required  bool
+	Sensitive bool   // the column holds data users may want to scrub (tokens, emails)
+	Stability string // the stability tier of the column when set with StabilityTagName, otherwise the tier of the log type applies
+	RawJSON   bool   // the column holds unparsed JSON as a string (or array of strings), see IsRawJSON
+}
+
+// IsRawJSON returns true if a field holds JSON which is passed through without being parsed,
+// e.g. a jsoniter.RawMessage for an object whose fields vary from event to event
+func IsRawJSON(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == rawJSONType || t == rawJSONArrayType
+}
+
+// SensitiveTagName is the struct tag marking a field as sensitive, e.g. `sensitive:"true"`
+const SensitiveTagName = "sensitive"
+
+// IsSensitive returns true if the field is marked as carrying sensitive data
+func IsSensitive(sf reflect.StructField) bool {
+	sensitive, _ := strconv.ParseBool(sf.Tag.Get(SensitiveTagName))
+	return sensitive
+}
+
+// StabilityTagName is the struct tag overriding the stability tier of a field, e.g. `stability:"experimental"`
+const StabilityTagName = "stability"
+
+const (
+	// StabilityStable is the default tier, stable schemas only change in backwards compatible ways
+	StabilityStable = "stable"
+	// StabilityExperimental marks log types and fields which may change or be removed without notice
+	StabilityExperimental = "experimental"
+)
+
+// IsValidStability returns true if tier is a known stability tier
+func IsValidStability(tier string) bool {
+	switch tier {
+	case StabilityStable, StabilityExperimental:
+		return true
+	default:
+		return false
+	}
+}
+
+// FieldStability returns the stability tier of a field, or "" if the field is not tagged
+func FieldStability(sf reflect.StructField) string {
+	return strings.TrimSpace(sf.Tag.Get(StabilityTagName))
 }
 
 // Functions to infer schema by reflection
@@ -246,10 +296,13 @@ func inferJSONColumns(t reflect.Type, customMappingsTable map[string]string) (co
 			}
 
 			cols = append(cols, Column{
-				Name:     fieldName,
-				Type:     glueType,
-				Comment:  clipComment(t, fieldName, comment), // avoid arbitrarily large comments that can break things
-				Required: required,
+				Name:      fieldName,
+				Type:      glueType,
+				Comment:   clipComment(t, fieldName, comment), // avoid arbitrarily large comments that can break things
+				Required:  required,
+				Sensitive: IsSensitive(field),
+				Stability: FieldStability(field),
+				RawJSON:   IsRawJSON(field.Type),
 			})
 			structFieldNames = append(structFieldNames, nestedFieldNames...)
 		}
@@ -272,6 +325,11 @@ func clipComment(t reflect.Type, fieldName, comment string) string {
 func inferStructFieldType(sf reflect.StructField, customMappingsTable map[string]string) (fieldName, glueType, comment string,
 	nestedFieldNames []string, required, skip bool) {
 
+	fieldName, comment, required, skip = FieldInfo(sf)
+	if skip {
+		return
+	}
+
 	t := sf.Type
 
 	// deference pointers
@@ -279,40 +337,6 @@ func inferStructFieldType(sf reflect.StructField, customMappingsTable map[string
 		t = t.Elem()
 	}
 
-	isUnexported := sf.PkgPath != ""
-	if sf.Anonymous {
-		if isUnexported && t.Kind() != reflect.Struct { // I can't seem to find a way to exercise this block in my tests
-			// Ignore embedded fields of unexported non-struct types.
-			skip = true
-			return
-		}
-		// Do not ignore embedded fields of unexported struct types
-		// since they may have exported fields.
-	} else if isUnexported {
-		// Ignore unexported non-embedded fields.
-		skip = true
-		return
-	}
-
-	// use json tag name if present
-	tag := sf.Tag.Get("json")
-	if tag == "-" {
-		skip = true
-		return
-	}
-
-	fieldName, _ = parseTag(tag)
-	if fieldName == "" {
-		fieldName = sf.Name
-	}
-
-	// Rewrite field the same way as the jsoniter extension to avoid invalid column names
-	fieldName = RewriteFieldName(fieldName)
-
-	comment = sf.Tag.Get("description")
-
-	required = strings.Contains(sf.Tag.Get("validate"), "required")
-
 	if to, found := customMappingsTable[t.String()]; found {
 		glueType = to
 		return
@@ -362,6 +386,52 @@ func inferStructFieldType(sf reflect.StructField, customMappingsTable map[string
 	}
 }
 
+// FieldInfo returns the column name, comment and required flag of a struct field, as used in the Glue schema.
+// It returns skip=true for fields that are not serialized to JSON.
+func FieldInfo(sf reflect.StructField) (fieldName, comment string, required, skip bool) {
+	t := sf.Type
+
+	// deference pointers
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	isUnexported := sf.PkgPath != ""
+	if sf.Anonymous {
+		if isUnexported && t.Kind() != reflect.Struct { // I can't seem to find a way to exercise this block in my tests
+			// Ignore embedded fields of unexported non-struct types.
+			skip = true
+			return
+		}
+		// Do not ignore embedded fields of unexported struct types
+		// since they may have exported fields.
+	} else if isUnexported {
+		// Ignore unexported non-embedded fields.
+		skip = true
+		return
+	}
+
+	// use json tag name if present
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		skip = true
+		return
+	}
+
+	fieldName, _ = parseTag(tag)
+	if fieldName == "" {
+		fieldName = sf.Name
+	}
+
+	// Rewrite field the same way as the jsoniter extension to avoid invalid column names
+	fieldName = RewriteFieldName(fieldName)
+
+	comment = sf.Tag.Get("description")
+
+	required = strings.Contains(sf.Tag.Get("validate"), "required")
+	return fieldName, comment, required, false
+}
+
 // Recursively expand a struct
 // It returns the struct Glue definition and a slice of all the struct's field names (including nested field names)
 func inferStruct(structType reflect.Type, customMappingsTable map[string]string) (glueType string, structFieldNames []string) {
@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"testing"
 
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,6 +82,50 @@ func TestInferJsonColumnsRemap(t *testing.T) {
 	require.Equal(t, expectedStructFieldNames, structFieldNames)
 }
 
+func TestInferJsonColumnsSensitive(t *testing.T) {
+	obj := struct { //nolint
+		Email  string     `json:"email" sensitive:"true" description:"test field"`
+		Token  TestStruct `json:"token" sensitive:"true" description:"test field"`
+		Name   string     `json:"name" sensitive:"false" description:"test field"`
+		Public string     `json:"public" description:"test field"`
+	}{}
+	cols, _ := InferJSONColumns(obj)
+	require.Len(t, cols, 4)
+	require.True(t, cols[0].Sensitive)
+	require.True(t, cols[1].Sensitive)
+	require.False(t, cols[2].Sensitive)
+	require.False(t, cols[3].Sensitive)
+}
+
+func TestInferJsonColumnsStability(t *testing.T) {
+	obj := struct { //nolint
+		New string `json:"new" stability:"experimental" description:"test field"`
+		Old string `json:"old" description:"test field"`
+	}{}
+	cols, _ := InferJSONColumns(obj)
+	require.Len(t, cols, 2)
+	require.Equal(t, StabilityExperimental, cols[0].Stability)
+	require.Empty(t, cols[1].Stability)
+	require.True(t, IsValidStability(StabilityStable))
+	require.True(t, IsValidStability(StabilityExperimental))
+	require.False(t, IsValidStability(""))
+}
+
+func TestInferJsonColumnsRawJSON(t *testing.T) {
+	obj := struct { //nolint
+		Detail  *jsoniter.RawMessage  `json:"detail" description:"test field"`
+		Details []jsoniter.RawMessage `json:"details" description:"test field"`
+		Name    string                `json:"name" description:"test field"`
+	}{}
+	cols, _ := InferJSONColumns(obj, GlueMappings...)
+	require.Len(t, cols, 3)
+	require.True(t, cols[0].RawJSON)
+	require.Equal(t, GlueStringType, cols[0].Type)
+	require.True(t, cols[1].RawJSON)
+	require.Equal(t, ArrayOf(GlueStringType), cols[1].Type)
+	require.False(t, cols[2].RawJSON)
+}
+
 func TestInferJsonColumns(t *testing.T) {
 	// used to test pointers and types
 	var s string = "S"
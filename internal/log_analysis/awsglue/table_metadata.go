@@ -41,6 +41,8 @@ import (
 type PartitionKey struct {
 	Name string
 	Type string
+	// Set if the table configures partition projection, see GlueTableMetadata.WithPartitionProjection
+	Projection *PartitionProjection
 }
 
 // Metadata about Glue table
@@ -53,6 +55,8 @@ type GlueTableMetadata struct {
 	prefix       string
 	timebin      GlueTableTimebin // at what time resolution is this table partitioned
 	eventStruct  interface{}
+	// Partition projection is configured for data since this year, if set (see WithPartitionProjection)
+	projectionFromYear int
 }
 
 // Creates a new GlueTableMetadata object for Panther log sources
@@ -110,6 +114,14 @@ func (gm *GlueTableMetadata) HasPartitions(glueClient glueiface.GlueAPI) (bool,
 	return TableHasPartitions(glueClient, gm.databaseName, gm.tableName)
 }
 
+// The column with the standardized event time, which the partitions of Panther tables are derived from
+const EventTimeColumnName = "p_event_time"
+
+// The column holding the event time, the partition keys of this table are the time bins of its value
+func (gm *GlueTableMetadata) EventTimeColumn() string {
+	return EventTimeColumnName
+}
+
 // The partition keys for this table
 func (gm *GlueTableMetadata) PartitionKeys() (partitions []PartitionKey) {
 	partitions = []PartitionKey{{Name: "year", Type: "int"}}
@@ -123,6 +135,9 @@ func (gm *GlueTableMetadata) PartitionKeys() (partitions []PartitionKey) {
 	if gm.Timebin() >= GlueTableHourly {
 		partitions = append(partitions, PartitionKey{Name: "hour", Type: "int"})
 	}
+	for i := range partitions {
+		partitions[i].Projection = gm.partitionProjection(partitions[i].Name)
+	}
 	return partitions
 }
 
@@ -131,7 +146,9 @@ func (gm *GlueTableMetadata) RuleTable() *GlueTableMetadata {
 		return gm
 	}
 	// the corresponding rule table shares the same structure as the log table + some columns
-	return NewGlueTableMetadata(models.RuleData, gm.LogType(), gm.Description(), GlueTableHourly, gm.EventStruct())
+	ruleTable := NewGlueTableMetadata(models.RuleData, gm.LogType(), gm.Description(), GlueTableHourly, gm.EventStruct())
+	ruleTable.projectionFromYear = gm.projectionFromYear
+	return ruleTable
 }
 
 func (gm *GlueTableMetadata) glueTableInput(bucketName string) *glue.TableInput {
@@ -188,7 +205,8 @@ func (gm *GlueTableMetadata) glueTableInput(bucketName string) *glue.TableInput
 				Parameters:           descriptorParameters,
 			},
 		},
-		TableType: aws.String("EXTERNAL_TABLE"),
+		TableType:  aws.String("EXTERNAL_TABLE"),
+		Parameters: gm.partitionProjectionParameters(bucketName),
 	}
 }
 
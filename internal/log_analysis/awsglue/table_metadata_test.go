@@ -125,6 +125,7 @@ func TestGlueTableMetadataLogData(t *testing.T) {
 	assert.Equal(t, "logs/my_logs_type/", gm.Prefix())
 	assert.Equal(t, partitionTestEvent{}, gm.eventStruct)
 	assert.Equal(t, "logs/my_logs_type/year=2020/month=01/day=03/hour=01/", gm.GetPartitionPrefix(refTime))
+	assert.Equal(t, "p_event_time", gm.EventTimeColumn())
 }
 
 func TestGlueTableMetadataRuleMatches(t *testing.T) {
@@ -0,0 +1,208 @@
+package jsonschema
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Infers draft-07 JSON Schemas from Go types, using the same reflection rules as the Glue schema inference
+
+import (
+	"reflect"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const (
+	// Draft07 is the meta schema URI of JSON Schema draft-07
+	Draft07 = "http://json-schema.org/draft-07/schema#"
+
+	TypeArray   = "array"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeObject  = "object"
+	TypeString  = "string"
+)
+
+// Schema is a JSON Schema (draft-07).
+// The YAML encoding is also a valid OpenAPI 3 schema object when the meta schema is omitted (see Reflect).
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	// Marks values carrying sensitive data (see awsglue.SensitiveTagName).
+	// OpenAPI 3 allows extensions prefixed with "x-" and JSON Schema ignores unknown keywords.
+	Sensitive bool `json:"x-sensitive,omitempty" yaml:"x-sensitive,omitempty"`
+	// The stability tier of a log type or field (see awsglue.StabilityTagName), fields without a tier inherit it
+	Stability string `json:"x-stability,omitempty" yaml:"x-stability,omitempty"`
+	// The tags of a log type, e.g. "audit" (see logtypes.Desc)
+	Tags []string `json:"x-tags,omitempty" yaml:"x-tags,omitempty"`
+}
+
+var (
+	// JSON values of any type, the empty schema allows everything
+	rawMessageTypes = map[reflect.Type]bool{
+		reflect.TypeOf(jsoniter.RawMessage{}):   true,
+		reflect.TypeOf([]jsoniter.RawMessage{}): true,
+	}
+
+	// Formats for string fields carrying indicators (see pantherlog.TagName)
+	indicatorSchemas = map[string]func() *Schema{
+		"ip": func() *Schema {
+			return &Schema{
+				Type:  TypeString,
+				AnyOf: []*Schema{{Format: "ipv4"}, {Format: "ipv6"}},
+			}
+		},
+		"hostname": func() *Schema { return &Schema{Type: TypeString, Format: "hostname"} },
+		"domain":   func() *Schema { return &Schema{Type: TypeString, Format: "hostname"} },
+		"url":      func() *Schema { return &Schema{Type: TypeString, Format: "uri"} },
+	}
+)
+
+// Infer walks the event struct of a log type and returns the JSON Schema of the events Panther stores.
+// Field names, required fields and custom Panther types match the Glue columns from awsglue.InferJSONColumns.
+func Infer(obj interface{}) *Schema {
+	schema := Reflect(obj)
+	schema.Schema = Draft07
+	return schema
+}
+
+// Reflect returns the schema of a Go type without a meta schema.
+//
+// It only uses the keywords shared by JSON Schema and OpenAPI 3 schema objects,
+// so the result can be used as is in the components of an OpenAPI document.
+func Reflect(obj interface{}) *Schema {
+	customMappings := make(map[reflect.Type]string)
+	for _, mapping := range awsglue.GlueMappings {
+		customMappings[mapping.From] = mapping.To
+	}
+	return inferType(reflect.TypeOf(obj), customMappings)
+}
+
+func inferType(t reflect.Type, customMappings map[reflect.Type]string) *Schema {
+	// dereference pointers
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if rawMessageTypes[t] {
+		return &Schema{}
+	}
+	if glueType, found := customMappings[t]; found {
+		return fromGlueType(glueType)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &Schema{
+			Type:       TypeObject,
+			Properties: make(map[string]*Schema),
+		}
+		inferStruct(schema, t, customMappings)
+		return schema
+	case reflect.Map:
+		return &Schema{
+			Type:                 TypeObject,
+			AdditionalProperties: inferType(t.Elem(), customMappings),
+		}
+	case reflect.Slice, reflect.Array:
+		return &Schema{
+			Type:  TypeArray,
+			Items: inferType(t.Elem(), customMappings),
+		}
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	default:
+		// interface{} and anything we cannot describe accepts any JSON value
+		return &Schema{}
+	}
+}
+
+// inferStruct adds the properties of a struct to an object schema, embedded structs are flattened into the object
+func inferStruct(schema *Schema, t reflect.Type, customMappings map[reflect.Type]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldName, comment, required, skip := awsglue.FieldInfo(field)
+		if skip {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && fieldType.Kind() == reflect.Struct {
+			if _, found := customMappings[fieldType]; !found {
+				inferStruct(schema, fieldType, customMappings)
+				continue
+			}
+		}
+
+		property := inferType(field.Type, customMappings)
+		if indicator, ok := field.Tag.Lookup(pantherlog.TagName); ok && property.Type == TypeString {
+			if newSchema, found := indicatorSchemas[strings.TrimSpace(indicator)]; found {
+				property = newSchema()
+			}
+		}
+		property.Description = strings.TrimSpace(comment)
+		property.Sensitive = awsglue.IsSensitive(field)
+		property.Stability = awsglue.FieldStability(field)
+
+		schema.Properties[fieldName] = property
+		if required {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+}
+
+// fromGlueType returns the schema of the JSON values a custom Glue type mapping reads
+func fromGlueType(glueType string) *Schema {
+	switch glueType {
+	case awsglue.GlueTimestampType:
+		// All timestamp types are written as strings, see awsglue.TimestampLayout
+		return &Schema{Type: TypeString, Format: "date-time"}
+	case awsglue.GlueStringType:
+		return &Schema{Type: TypeString}
+	case "boolean":
+		return &Schema{Type: TypeBoolean}
+	case "tinyint", "smallint", "int", "bigint":
+		return &Schema{Type: TypeInteger}
+	case "float", "double":
+		return &Schema{Type: TypeNumber}
+	default:
+		return &Schema{}
+	}
+}
@@ -0,0 +1,142 @@
+package jsonschema
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded" description:"embedded field"`
+}
+
+type testNested struct {
+	Value int32 `json:"value" validate:"required" description:"nested value"`
+}
+
+type testEvent struct {
+	testEmbedded
+
+	Name      *string                     `json:"name" validate:"required" description:"name field"`
+	Time      *time.Time                  `json:"time" description:"time field"`
+	Count     uint16                      `json:"count" description:"count field"`
+	Ratio     float64                     `json:"ratio" stability:"experimental" description:"ratio field"`
+	Enabled   bool                        `json:"enabled" description:"enabled field"`
+	IP        string                      `json:"ip" panther:"ip" description:"ip field"`
+	Email     string                      `json:"email" sensitive:"true" description:"email field"`
+	Raw       jsoniter.RawMessage         `json:"raw" description:"raw field"`
+	Nested    *testNested                 `json:"nested" description:"nested field"`
+	List      []testNested                `json:"list" description:"list field"`
+	Labels    map[string]string           `json:"labels" description:"labels field"`
+	Renamed   string                      `json:"@renamed" description:"renamed field"`
+	Skipped   string                      `json:"-" description:"skipped field"`
+	Sets      map[string]map[string]int64 `json:"sets" description:"sets field"`
+	unexposed string                      // nolint
+}
+
+func TestInfer(t *testing.T) {
+	schema := Infer(&testEvent{})
+
+	assert.Equal(t, Draft07, schema.Schema)
+	assert.Equal(t, TypeObject, schema.Type)
+	assert.Equal(t, []string{"name"}, schema.Required)
+
+	props := schema.Properties
+	require.Len(t, props, 14)
+	assert.Equal(t, &Schema{Type: TypeString, Description: "embedded field"}, props["embedded"])
+	assert.Equal(t, &Schema{Type: TypeString, Description: "name field"}, props["name"])
+	assert.Equal(t, &Schema{Type: TypeString, Format: "date-time", Description: "time field"}, props["time"])
+	assert.Equal(t, &Schema{Type: TypeInteger, Description: "count field"}, props["count"])
+	assert.Equal(t, &Schema{Type: TypeNumber, Description: "ratio field", Stability: "experimental"}, props["ratio"])
+	assert.Equal(t, &Schema{Type: TypeBoolean, Description: "enabled field"}, props["enabled"])
+	assert.Equal(t, &Schema{
+		Type:        TypeString,
+		AnyOf:       []*Schema{{Format: "ipv4"}, {Format: "ipv6"}},
+		Description: "ip field",
+	}, props["ip"])
+	assert.Equal(t, &Schema{Type: TypeString, Description: "email field", Sensitive: true}, props["email"])
+	assert.Equal(t, &Schema{Description: "raw field"}, props["raw"])
+	nested := &Schema{
+		Type:       TypeObject,
+		Properties: map[string]*Schema{"value": {Type: TypeInteger, Description: "nested value"}},
+		Required:   []string{"value"},
+	}
+	assert.Equal(t, &Schema{
+		Type:        TypeObject,
+		Properties:  nested.Properties,
+		Required:    nested.Required,
+		Description: "nested field",
+	}, props["nested"])
+	assert.Equal(t, &Schema{Type: TypeArray, Items: nested, Description: "list field"}, props["list"])
+	assert.Equal(t, &Schema{
+		Type:                 TypeObject,
+		AdditionalProperties: &Schema{Type: TypeString},
+		Description:          "labels field",
+	}, props["labels"])
+	assert.Contains(t, props, "at_sign_renamed")
+	assert.Equal(t, &Schema{
+		Type: TypeObject,
+		AdditionalProperties: &Schema{
+			Type:                 TypeObject,
+			AdditionalProperties: &Schema{Type: TypeInteger},
+		},
+		Description: "sets field",
+	}, props["sets"])
+}
+
+// The JSON Schema and the Glue schema must agree on field names and required fields
+func TestInferMatchesGlue(t *testing.T) {
+	event := &testEvent{}
+	schema := Infer(event)
+	columns, _ := awsglue.InferJSONColumns(event, awsglue.GlueMappings...)
+	require.Len(t, schema.Properties, len(columns))
+	var required []string
+	for _, column := range columns {
+		require.Contains(t, schema.Properties, column.Name)
+		assert.Equal(t, column.Sensitive, schema.Properties[column.Name].Sensitive, column.Name)
+		assert.Equal(t, column.Stability, schema.Properties[column.Name].Stability, column.Name)
+		if column.Required {
+			required = append(required, column.Name)
+		}
+	}
+	assert.Equal(t, required, schema.Required)
+}
+
+// Reflect is shared by the JSON Schema and the OpenAPI generators and must not set a meta schema
+func TestReflect(t *testing.T) {
+	schema := Reflect(&testNested{})
+	assert.Empty(t, schema.Schema)
+	assert.Equal(t, Infer(&testNested{}).Properties, schema.Properties)
+
+	body, err := yaml.Marshal(Reflect(&testEvent{}).Properties["labels"])
+	require.NoError(t, err)
+	assert.Equal(t, "description: labels field\ntype: object\nadditionalProperties:\n  type: string\n", string(body))
+
+	body, err = yaml.Marshal(Reflect(&testEvent{}).Properties["email"])
+	require.NoError(t, err)
+	assert.Equal(t, "description: email field\ntype: string\nx-sensitive: true\n", string(body))
+}
@@ -20,6 +20,7 @@ package logtypes
 
 import (
 	"net/url"
+	"strings"
 	"sync"
 
 	jsoniter "github.com/json-iterator/go"
@@ -107,6 +108,8 @@ func (r *Registry) RegisterJSON(desc Desc, eventFactory func() interface{}) (Ent
 		Name:         desc.Name,
 		Description:  desc.Description,
 		ReferenceURL: desc.ReferenceURL,
+		Stability:    desc.Stability,
+		Tags:         desc.Tags,
 		Schema:       schema,
 		NewParser: &parsers.JSONParserFactory{
 			LogType:  desc.Name,
@@ -159,15 +162,25 @@ type Config struct {
 	Name         string
 	Description  string
 	ReferenceURL string
-	Schema       interface{}
-	NewParser    parsers.Factory
+	// Stability is the stability tier of the log type, defaults to awsglue.StabilityStable
+	Stability string
+	// Tags are the use cases of the log type, e.g. "audit" or "network"
+	Tags      []string
+	Schema    interface{}
+	NewParser parsers.Factory
 }
 
 func (config *Config) Describe() Desc {
+	stability := config.Stability
+	if stability == "" {
+		stability = awsglue.StabilityStable
+	}
 	return Desc{
 		Name:         config.Name,
 		Description:  config.Description,
 		ReferenceURL: config.ReferenceURL,
+		Stability:    stability,
+		Tags:         config.Tags,
 	}
 }
 
@@ -194,6 +207,10 @@ type Desc struct {
 	Name         string
 	Description  string
 	ReferenceURL string
+	// Stability tells users whether they can build on the schema, e.g. "experimental" log types may change without notice
+	Stability string
+	// Tags help users find log types by use case, e.g. "audit", "network" or "auth"
+	Tags []string
 }
 
 func (desc *Desc) Validate() error {
@@ -206,6 +223,19 @@ func (desc *Desc) Validate() error {
 	if desc.ReferenceURL == "" {
 		return errors.Errorf("missing reference URL for log type %q", desc.Name)
 	}
+	if desc.Stability != "" && !awsglue.IsValidStability(desc.Stability) {
+		return errors.Errorf("invalid stability tier %q for log type %q", desc.Stability, desc.Name)
+	}
+	seenTags := make(map[string]bool, len(desc.Tags))
+	for _, tag := range desc.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.Errorf("empty tag for log type %q", desc.Name)
+		}
+		if seenTags[tag] {
+			return errors.Errorf("duplicate tag %q for log type %q", tag, desc.Name)
+		}
+		seenTags[tag] = true
+	}
 	if desc.ReferenceURL != "-" {
 		u, err := url.Parse(desc.ReferenceURL)
 		if err != nil {
@@ -294,5 +324,10 @@ func checkGlue(schema interface{}) (err error) {
 	if len(cols) == 0 {
 		err = errors.New("empty columns")
 	}
+	for _, col := range cols {
+		if col.Stability != "" && !awsglue.IsValidStability(col.Stability) {
+			return errors.Errorf("invalid stability tier %q for column %q", col.Stability, col.Name)
+		}
+	}
 	return
 }
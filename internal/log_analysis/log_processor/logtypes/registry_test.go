@@ -54,6 +54,7 @@ func TestRegistry(t *testing.T) {
 		Name:         "Foo.Bar",
 		Description:  "Foo.Bar logs",
 		ReferenceURL: "-",
+		Stability:    awsglue.StabilityStable,
 	}, api.Describe())
 	require.Equal(t, T{}, api.Schema())
 	require.Equal(
@@ -142,4 +143,67 @@ func TestDesc(t *testing.T) {
 		Description:  "Foo bar",
 		ReferenceURL: "https://example.org",
 	}).Validate())
+	require.NoError(t, (&Desc{
+		Name:         "Foo",
+		Description:  "Foo bar",
+		ReferenceURL: "-",
+		Stability:    awsglue.StabilityExperimental,
+	}).Validate())
+	require.Error(t, (&Desc{
+		Name:         "Foo",
+		Description:  "Foo bar",
+		ReferenceURL: "-",
+		Stability:    "beta",
+	}).Validate())
+}
+
+func TestRegistryStability(t *testing.T) {
+	r := Registry{}
+	type T struct {
+		Foo string `json:"foo" description:"foo field"`
+		Bar string `json:"bar" stability:"experimental" description:"bar field"`
+	}
+	entry, err := r.RegisterJSON(Desc{
+		Name:         "Foo.Bar",
+		Description:  "Foo.Bar logs",
+		ReferenceURL: "-",
+		Stability:    awsglue.StabilityExperimental,
+	}, func() interface{} { return &T{} })
+	require.NoError(t, err)
+	require.Equal(t, awsglue.StabilityExperimental, entry.Describe().Stability)
+
+	type Invalid struct {
+		Foo string `json:"foo" stability:"beta" description:"foo field"`
+	}
+	_, err = r.RegisterJSON(Desc{
+		Name:         "Foo.Baz",
+		Description:  "Foo.Baz logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &Invalid{} })
+	require.Error(t, err)
+}
+
+func TestRegistryTags(t *testing.T) {
+	r := Registry{}
+	type T struct {
+		Foo string `json:"foo" description:"foo field"`
+	}
+	entry, err := r.RegisterJSON(Desc{
+		Name:         "Foo.Bar",
+		Description:  "Foo.Bar logs",
+		ReferenceURL: "-",
+		Tags:         []string{"audit", "auth"},
+	}, func() interface{} { return &T{} })
+	require.NoError(t, err)
+	require.Equal(t, []string{"audit", "auth"}, entry.Describe().Tags)
+
+	for _, tags := range [][]string{{"audit", ""}, {"audit", "audit"}} {
+		_, err = r.RegisterJSON(Desc{
+			Name:         "Foo.Baz",
+			Description:  "Foo.Baz logs",
+			ReferenceURL: "-",
+			Tags:         tags,
+		}, func() interface{} { return &T{} })
+		require.Error(t, err, tags)
+	}
 }
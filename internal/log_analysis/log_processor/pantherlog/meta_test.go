@@ -36,16 +36,16 @@ func TestMetaEventStruct(t *testing.T) {
 
 	columns, names := awsglue.InferJSONColumns(eventStruct, awsglue.GlueMappings...)
 	require.Equal(t, []string{}, names)
-	// nolint: lll,govet
+	// nolint: lll
 	require.Equal(t, []awsglue.Column{
-		{"foo", "string", "foo", false},
-		{"ts", "timestamp", "ts", false},
-		{"addr", "string", "address", false},
-		{"p_event_time", "timestamp", "Panther added standardized event time (UTC)", true},
-		{"p_parse_time", "timestamp", "Panther added standardized log parse time (UTC)", true},
-		{"p_log_type", "string", "Panther added field with type of log", true},
-		{"p_row_id", "string", "Panther added field with unique id (within table)", true},
-		{"p_any_ip_addresses", "array<string>", "Panther added field with collection of ip addresses associated with the row", false},
+		{Name: "foo", Type: "string", Comment: "foo", Required: false},
+		{Name: "ts", Type: "timestamp", Comment: "ts", Required: false},
+		{Name: "addr", Type: "string", Comment: "address", Required: false},
+		{Name: "p_event_time", Type: "timestamp", Comment: "Panther added standardized event time (UTC)", Required: true},
+		{Name: "p_parse_time", Type: "timestamp", Comment: "Panther added standardized log parse time (UTC)", Required: true},
+		{Name: "p_log_type", Type: "string", Comment: "Panther added field with type of log", Required: true},
+		{Name: "p_row_id", Type: "string", Comment: "Panther added field with unique id (within table)", Required: true},
+		{Name: "p_any_ip_addresses", Type: "array<string>", Comment: "Panther added field with collection of ip addresses associated with the row", Required: false},
 	}, columns)
 }
 
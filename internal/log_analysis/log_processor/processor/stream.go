@@ -98,7 +98,8 @@ func streamEvents(sqsClient sqsiface.SQSAPI, deadlineTime time.Time, event event
 					zap.L().Warn("high memory usage",
 						zap.Float32("heapUsedDB", heapUsedMB),
 						zap.Float32("memAvailableDB", memAvailableMB),
-						zap.Int("sqsMessagesRead", len(accumulatedMessageReceipts)))
+						zap.Int("sqsMessagesRead", len(accumulatedMessageReceipts)),
+						zap.Int("releasedS3Clients", sources.ReleaseS3Clients()))
 				}
 				time.Sleep(time.Second)
 				highMemoryCounter++
@@ -0,0 +1,106 @@
+package registry
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+)
+
+// Inferred columns are cached per event struct and comment length, since comments are clipped to
+// awsglue.MaxCommentLength during inference and tools (e.g. the docs) change it.
+type columnsCacheKey struct {
+	logType          string
+	eventType        reflect.Type
+	maxCommentLength int
+}
+
+var (
+	columnsCacheLock sync.Mutex
+	columnsCache     = make(map[columnsCacheKey][]awsglue.Column)
+)
+
+// LogTypeColumns returns the Glue columns of a log type in the default registry.
+//
+// Inference uses reflection, so the columns of each log type are only inferred once per process.
+func LogTypeColumns(logType string) ([]awsglue.Column, error) {
+	entry := logtypes.DefaultRegistry().Get(logType)
+	if entry == nil {
+		return nil, errors.Errorf("log type %q is not registered", logType)
+	}
+	return InferColumns(logType, entry.GlueTableMeta().EventStruct())
+}
+
+// AllLogTypeColumns returns the Glue columns of every available log type, by log type name.
+func AllLogTypeColumns() (map[string][]awsglue.Column, error) {
+	entries := logtypes.DefaultRegistry().Entries()
+	result := make(map[string][]awsglue.Column, len(entries))
+	for _, entry := range entries {
+		logType := entry.Describe().Name
+		columns, err := InferColumns(logType, entry.GlueTableMeta().EventStruct())
+		if err != nil {
+			return nil, err
+		}
+		result[logType] = columns
+	}
+	return result, nil
+}
+
+// InferColumns returns the Glue columns of the event struct of a log type, see LogTypeColumns.
+//
+// A struct which infers no columns, or which panics during inference, returns an error.
+// The returned slice is a copy and can be modified by the caller.
+func InferColumns(logType string, eventStruct interface{}) ([]awsglue.Column, error) {
+	key := columnsCacheKey{
+		logType:          logType,
+		eventType:        reflect.TypeOf(eventStruct),
+		maxCommentLength: awsglue.MaxCommentLength,
+	}
+	columnsCacheLock.Lock()
+	defer columnsCacheLock.Unlock()
+
+	columns, ok := columnsCache[key]
+	if !ok {
+		var err error
+		if columns, err = inferColumns(logType, eventStruct); err != nil {
+			return nil, err
+		}
+		columnsCache[key] = columns
+	}
+	return append([]awsglue.Column(nil), columns...), nil
+}
+
+func inferColumns(logType string, eventStruct interface{}) (columns []awsglue.Column, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("failed to infer schema for %s: %v", logType, r)
+		}
+	}()
+
+	columns, _ = awsglue.InferJSONColumns(eventStruct, awsglue.GlueMappings...)
+	if len(columns) == 0 {
+		return nil, errors.Errorf("no columns inferred for %s", logType)
+	}
+	return columns, nil
+}
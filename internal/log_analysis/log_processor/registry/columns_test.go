@@ -0,0 +1,72 @@
+package registry
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTypeColumns(t *testing.T) {
+	columns, err := LogTypeColumns("Osquery.Status")
+	require.NoError(t, err)
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	require.True(t, len(names) > 11)
+	assert.Equal(t, []string{
+		"calendarTime",
+		"decorations",
+		"filename",
+		"hostIdentifier",
+		"line",
+		"logType",
+		"log_type",
+		"message",
+		"severity",
+		"unixTime",
+		"version",
+	}, names[:11])
+	assert.Contains(t, names[11:], "p_log_type")
+	assert.True(t, columns[0].Required)
+	assert.False(t, columns[1].Required)
+
+	// Cached columns are not affected by changes to a previous result
+	columns[0].Name = "changed"
+	cached, err := LogTypeColumns("Osquery.Status")
+	require.NoError(t, err)
+	assert.Equal(t, "calendarTime", cached[0].Name)
+
+	all, err := AllLogTypeColumns()
+	require.NoError(t, err)
+	assert.Len(t, all, len(AvailableLogTypes()))
+	assert.Equal(t, cached, all["Osquery.Status"])
+
+	_, err = LogTypeColumns("Osquery.DoesNotExist")
+	assert.Error(t, err)
+}
+
+func TestInferColumnsFail(t *testing.T) {
+	_, err := InferColumns("Foo.Empty", &struct{}{})
+	require.Error(t, err)
+	assert.Equal(t, "no columns inferred for Foo.Empty", err.Error())
+}
@@ -19,7 +19,11 @@ package sources
  */
 
 import (
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -28,6 +32,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -45,13 +50,18 @@ const (
 	// How frequently to query the sources_api for new integrations
 	sourceCacheDuration = 5 * time.Minute
 
-	s3BucketLocationCacheSize = 1000
-	s3ClientCacheSize         = 1000
+	// The default cache sizes, they can be changed with S3_BUCKET_LOCATION_CACHE_SIZE and S3_CLIENT_CACHE_SIZE
+	defaultS3BucketLocationCacheSize = 1000
+	defaultS3ClientCacheSize         = 1000
 )
 
 type s3ClientCacheKey struct {
-	roleArn   string
-	awsRegion string
+	// The kind of credentials provider, sources reading with different credentials never share a client
+	credentialsProvider string
+	roleArn             string
+	// The session tags of the role, see formatSessionTags
+	sessionTags string
+	awsRegion   string
 }
 
 type sourceCacheStruct struct {
@@ -61,12 +71,17 @@ type sourceCacheStruct struct {
 
 var (
 	// Bucket name -> region
+	// The region is a property of the bucket, not of the integration reading it, so integrations on the same
+	// bucket (e.g. with different prefixes or roles) share one GetBucketLocation lookup.
+	// The LRU caches are safe for concurrent use, they are created at init and never reassigned.
 	bucketCache *lru.ARCCache
 
 	// s3ClientCacheKey -> S3 client
 	s3ClientCache *lru.ARCCache
 
-	sourceCache = &sourceCacheStruct{
+	// sourceCacheLock guards sourceCache and lastEventReceived
+	sourceCacheLock sync.Mutex
+	sourceCache     = &sourceCacheStruct{
 		cacheUpdateTime: time.Unix(0, 0),
 	}
 
@@ -81,21 +96,43 @@ var (
 )
 
 func init() {
+	initCaches()
+}
+
+// initCaches creates the S3 client and bucket location caches with the sizes configured in the environment.
+// Larger caches trade memory for fewer STS and GetBucketLocation calls on deployments with many source buckets.
+func initCaches() {
 	var err error
-	s3ClientCache, err = lru.NewARC(s3ClientCacheSize)
+	s3ClientCache, err = lru.NewARC(cacheSizeFromEnv("S3_CLIENT_CACHE_SIZE", defaultS3ClientCacheSize))
 	if err != nil {
 		panic("Failed to create client cache")
 	}
 
-	bucketCache, err = lru.NewARC(s3BucketLocationCacheSize)
+	bucketCache, err = lru.NewARC(cacheSizeFromEnv("S3_BUCKET_LOCATION_CACHE_SIZE", defaultS3BucketLocationCacheSize))
 	if err != nil {
 		panic("Failed to create bucket cache")
 	}
 }
 
+// cacheSizeFromEnv reads a cache size from an environment variable, panicking if it is not a positive integer
+func cacheSizeFromEnv(key string, defaultSize int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultSize
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		panic("invalid " + key + ", expected a positive integer: " + value)
+	}
+	return size
+}
+
 // getS3Client Fetches
 // 1. S3 client with permissions to read data from the account that contains the event
 // 2. The type of the integration
+//
+// It is safe to call getS3Client from multiple goroutines. Concurrent calls for an uncached bucket or client
+// may each fetch it, the last one to finish is kept in the cache.
 func getS3Client(s3Object *S3ObjectInfo) (s3iface.S3API, string, error) {
 	sourceInfo, err := getSourceInfo(s3Object)
 	if err != nil {
@@ -105,44 +142,69 @@ func getS3Client(s3Object *S3ObjectInfo) (s3iface.S3API, string, error) {
 	if sourceInfo == nil {
 		return nil, "", errors.Errorf("there is no source configured for S3 object %#v", s3Object)
 	}
+
+	client, err := getSourceS3Client(sourceInfo, s3Object.S3Bucket)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to read %#v", s3Object)
+	}
+	return client, sourceInfo.IntegrationType, nil
+}
+
+// getSourceS3Client returns an S3 client with the credentials of the source for a bucket,
+// creating it if it is not cached.
+func getSourceS3Client(sourceInfo *models.SourceIntegration, s3Bucket string) (s3iface.S3API, error) {
+	credentialsKind, credentialsProvider, err := getS3CredentialsProvider(sourceInfo)
+	if err != nil {
+		return nil, err
+	}
 	var awsCreds *credentials.Credentials // lazy create below
 	roleArn := getSourceLogProcessingRole(sourceInfo)
+	sessionTags := sourceInfo.LogProcessingRoleSessionTags
 
-	bucketRegion, ok := bucketCache.Get(s3Object.S3Bucket)
+	bucketRegion, ok := bucketCache.Get(s3Bucket)
 	if !ok {
-		zap.L().Debug("bucket region was not cached, fetching it", zap.String("bucket", s3Object.S3Bucket))
-		awsCreds = getAwsCredentials(roleArn)
-		if awsCreds == nil {
-			return nil, "", errors.Errorf("failed to fetch credentials for assumed role %s to read %#v",
-				roleArn, s3Object)
+		zap.L().Debug("bucket region was not cached, fetching it", zap.String("bucket", s3Bucket))
+		if awsCreds, err = credentialsProvider.Credentials(roleArn, sessionTags); err != nil {
+			return nil, err
 		}
-		bucketRegion, err = getBucketRegion(s3Object.S3Bucket, awsCreds)
+		bucketRegion, err = getBucketRegion(s3Bucket, awsCreds)
 		if err != nil {
-			return nil, "", err
+			return nil, err
 		}
-		bucketCache.Add(s3Object.S3Bucket, bucketRegion)
+		bucketCache.Add(s3Bucket, bucketRegion)
 	}
 
 	zap.L().Debug("found bucket region", zap.Any("region", bucketRegion))
 
 	cacheKey := s3ClientCacheKey{
-		roleArn:   roleArn,
-		awsRegion: bucketRegion.(string),
+		credentialsProvider: credentialsKind,
+		roleArn:             roleArn,
+		sessionTags:         formatSessionTags(sessionTags),
+		awsRegion:           bucketRegion.(string),
 	}
 	client, ok := s3ClientCache.Get(cacheKey)
 	if !ok {
 		zap.L().Debug("s3 client was not cached, creating it")
 		if awsCreds == nil {
-			awsCreds = getAwsCredentials(roleArn)
-			if awsCreds == nil {
-				return nil, "", errors.Errorf("failed to fetch credentials for assumed role %s to read %#v",
-					roleArn, s3Object)
+			if awsCreds, err = credentialsProvider.Credentials(roleArn, sessionTags); err != nil {
+				return nil, err
 			}
 		}
 		client = newS3ClientFunc(box.String(cacheKey.awsRegion), awsCreds)
 		s3ClientCache.Add(cacheKey, client)
 	}
-	return client.(s3iface.S3API), sourceInfo.IntegrationType, nil
+	return client.(s3iface.S3API), nil
+}
+
+// ReleaseS3Clients drops all cached S3 clients (and the buffers they hold) to free memory under pressure,
+// returning how many were dropped. The much smaller bucket region cache is kept, so the clients are
+// recreated on demand without calling GetBucketLocation again.
+//
+// It is safe to call ReleaseS3Clients while S3 objects are being read.
+func ReleaseS3Clients() int {
+	released := s3ClientCache.Len()
+	s3ClientCache.Purge()
+	return released
 }
 
 func getBucketRegion(s3Bucket string, awsCreds *credentials.Credentials) (string, error) {
@@ -164,18 +226,58 @@ func getBucketRegion(s3Bucket string, awsCreds *credentials.Credentials) (string
 }
 
 // getAwsCredentials fetches the AWS Credentials from STS for by assuming a role in the given account
-func getAwsCredentials(roleArn string) *credentials.Credentials {
+//
+// The session tags, if any, are passed to STS sorted by key.
+func getAwsCredentials(roleArn string, sessionTags map[string]string) *credentials.Credentials {
 	zap.L().Debug("fetching new credentials from assumed role", zap.String("roleArn", roleArn))
 	return newCredentialsFunc(common.Session, roleArn, func(p *stscreds.AssumeRoleProvider) {
 		p.Duration = time.Duration(sessionDurationSeconds) * time.Second
 		p.ExpiryWindow = time.Minute // give plenty of time to refresh
+		if len(sessionTags) > 0 {
+			p.Tags = stsSessionTags(sessionTags)
+		}
 	})
 }
 
+func stsSessionTags(sessionTags map[string]string) []*sts.Tag {
+	keys := make([]string, 0, len(sessionTags))
+	for key := range sessionTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := make([]*sts.Tag, len(keys))
+	for i, key := range keys {
+		tags[i] = &sts.Tag{Key: aws.String(key), Value: aws.String(sessionTags[key])}
+	}
+	return tags
+}
+
+// formatSessionTags returns a canonical form of session tags for the S3 client cache key,
+// e.g. "19:panther:integration=4:1234,4:team=8:security,". Each key and value is prefixed with its length,
+// so tags containing "=" or "," can't collide with a different set of tags. It is empty if there are no tags.
+func formatSessionTags(sessionTags map[string]string) string {
+	var result strings.Builder
+	for _, tag := range stsSessionTags(sessionTags) {
+		for i, part := range []string{aws.StringValue(tag.Key), aws.StringValue(tag.Value)} {
+			result.WriteString(strconv.Itoa(len(part)))
+			result.WriteByte(':')
+			result.WriteString(part)
+			if i == 0 {
+				result.WriteByte('=')
+			}
+		}
+		result.WriteByte(',')
+	}
+	return result.String()
+}
+
 // Returns the source configuration for this S3 object.
 // It will return error if it encountered an issue retrieving the role.
 // It will return nil result if no source exists for this object.
 func getSourceInfo(s3Object *S3ObjectInfo) (result *models.SourceIntegration, err error) {
+	sourceCacheLock.Lock()
+	defer sourceCacheLock.Unlock()
+
 	now := time.Now() // No need to be UTC. We care about relative time
 	if sourceCache.cacheUpdateTime.Add(sourceCacheDuration).Before(now) {
 		// we need to update the cache
@@ -19,6 +19,8 @@ package sources
  */
 
 import (
+	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,8 +31,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
-	lru "github.com/hashicorp/golang-lru"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -109,6 +111,104 @@ func TestGetS3Client(t *testing.T) {
 	lambdaMock.AssertExpectations(t)
 }
 
+func TestGetS3ClientSharedBucketLocation(t *testing.T) {
+	resetCaches()
+	lambdaMock := &testutils.LambdaMock{}
+	common.LambdaClient = lambdaMock
+
+	s3Mock := &testutils.S3Mock{}
+	var clientRegions []*string
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		clientRegions = append(clientRegions, region)
+		return s3Mock
+	}
+
+	// A second integration, in another account, reading a different prefix of the same bucket
+	otherIntegration := &models.SourceIntegration{
+		SourceIntegrationMetadata: models.SourceIntegrationMetadata{
+			AWSAccountID:      "2345678901234",
+			S3Bucket:          "test-bucket",
+			S3Prefix:          "other-prefix",
+			IntegrationType:   models.IntegrationTypeAWS3,
+			LogProcessingRole: "arn:aws:iam::234567890123:role/PantherLogProcessingRole-suffix",
+			IntegrationID:     "7b5b8a2b-5d5c-4c43-a5c5-3d0e8ef4c9a1",
+		},
+	}
+	marshaledResult, err := jsoniter.Marshal([]*models.SourceIntegration{integration, otherIntegration})
+	require.NoError(t, err)
+
+	// Get the list of available sources, then update the status of each
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: marshaledResult}, nil).Once()
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil)
+	s3Mock.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("test-bucket")}).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil).Once()
+
+	var assumedRoles []string
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			assumedRoles = append(assumedRoles, roleARN)
+			return &credentials.Credentials{}
+		}
+
+	for _, key := range []string{"prefix/key", "other-prefix/key"} {
+		result, sourceType, err := getS3Client(&S3ObjectInfo{S3Bucket: "test-bucket", S3ObjectKey: key})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.Equal(t, models.IntegrationTypeAWS3, sourceType)
+	}
+
+	// Each integration still reads the bucket with its own role, in the shared bucket region
+	assert.Equal(t, []string{integration.LogProcessingRole, otherIntegration.LogProcessingRole}, assumedRoles)
+	assert.Equal(t, []*string{nil, aws.String("us-west-2"), aws.String("us-west-2")}, clientRegions)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestReleaseS3Clients(t *testing.T) {
+	resetCaches()
+	lambdaMock := &testutils.LambdaMock{}
+	common.LambdaClient = lambdaMock
+
+	s3Mock := &testutils.S3Mock{}
+	clientsCreated := 0
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		clientsCreated++
+		return s3Mock
+	}
+
+	marshaledResult, err := jsoniter.Marshal([]*models.SourceIntegration{integration})
+	require.NoError(t, err)
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: marshaledResult}, nil).Once()
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil)
+	// The bucket region is looked up only once, it survives releasing the clients
+	s3Mock.On("GetBucketLocation", mock.Anything).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil).Once()
+
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			return &credentials.Credentials{}
+		}
+
+	s3Object := &S3ObjectInfo{
+		S3Bucket:    "test-bucket",
+		S3ObjectKey: "prefix/key",
+	}
+	_, _, err = getS3Client(s3Object)
+	require.NoError(t, err)
+	assert.Equal(t, 2, clientsCreated) // location discovery client + client for the bucket region
+
+	assert.Equal(t, 1, ReleaseS3Clients())
+	assert.Equal(t, 0, s3ClientCache.Len())
+	assert.Equal(t, 1, bucketCache.Len())
+
+	// The client is recreated on the next read
+	result, _, err := getS3Client(s3Object)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, 3, clientsCreated)
+	assert.Equal(t, 1, s3ClientCache.Len())
+	s3Mock.AssertExpectations(t)
+}
+
 func TestGetS3ClientUnknownBucket(t *testing.T) {
 	resetCaches()
 	lambdaMock := &testutils.LambdaMock{}
@@ -200,9 +300,103 @@ func TestGetS3ClientSourceNoPrefix(t *testing.T) {
 	lambdaMock.AssertExpectations(t)
 }
 
+func TestGetS3ClientConcurrent(t *testing.T) {
+	resetCaches()
+	lambdaMock := &testutils.LambdaMock{}
+	common.LambdaClient = lambdaMock
+
+	s3Mock := &testutils.S3Mock{}
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		return s3Mock
+	}
+
+	marshaledResult, err := jsoniter.Marshal([]*models.SourceIntegration{integration})
+	require.NoError(t, err)
+
+	// The number of calls depends on how the goroutines interleave with the cache resets
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: marshaledResult}, nil)
+	s3Mock.On("GetBucketLocation", mock.Anything).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil)
+
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			return &credentials.Credentials{}
+		}
+
+	s3Object := &S3ObjectInfo{
+		S3Bucket:    "test-bucket",
+		S3ObjectKey: "prefix/key",
+	}
+
+	const numWorkers, numLookups = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numLookups; j++ {
+				result, sourceType, err := getS3Client(s3Object)
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Equal(t, models.IntegrationTypeAWS3, sourceType)
+			}
+		}()
+	}
+	// Reset the caches while lookups are in flight
+	for i := 0; i < numLookups; i++ {
+		resetCaches()
+	}
+	wg.Wait()
+}
+
+// resetCaches is safe to call while lookups are in flight, the caches keep their configured sizes
 func resetCaches() {
-	// resetting cache
+	sourceCacheLock.Lock()
 	sourceCache.cacheUpdateTime = time.Unix(0, 0)
-	bucketCache, _ = lru.NewARC(s3BucketLocationCacheSize)
-	s3ClientCache, _ = lru.NewARC(s3ClientCacheSize)
+	sourceCacheLock.Unlock()
+
+	bucketCache.Purge()
+	s3ClientCache.Purge()
+}
+
+func TestCacheSizesFromEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("S3_CLIENT_CACHE_SIZE", "2"))
+	require.NoError(t, os.Setenv("S3_BUCKET_LOCATION_CACHE_SIZE", "3"))
+	t.Cleanup(func() {
+		_ = os.Unsetenv("S3_CLIENT_CACHE_SIZE")
+		_ = os.Unsetenv("S3_BUCKET_LOCATION_CACHE_SIZE")
+		initCaches()
+	})
+	initCaches()
+
+	for i := 0; i < 10; i++ {
+		s3ClientCache.Add(i, i)
+		bucketCache.Add(i, i)
+	}
+	assert.Equal(t, 2, s3ClientCache.Len())
+	assert.Equal(t, 3, bucketCache.Len())
+
+	// Purging keeps the configured sizes
+	resetCaches()
+	for i := 0; i < 10; i++ {
+		s3ClientCache.Add(i, i)
+		bucketCache.Add(i, i)
+	}
+	assert.Equal(t, 2, s3ClientCache.Len())
+	assert.Equal(t, 3, bucketCache.Len())
+}
+
+func TestCacheSizeFromEnv(t *testing.T) {
+	const key = "TEST_CACHE_SIZE"
+	t.Cleanup(func() { _ = os.Unsetenv(key) })
+
+	assert.Equal(t, 42, cacheSizeFromEnv(key, 42))
+
+	require.NoError(t, os.Setenv(key, "100"))
+	assert.Equal(t, 100, cacheSizeFromEnv(key, 42))
+
+	for _, invalid := range []string{"0", "-1", "many"} {
+		require.NoError(t, os.Setenv(key, invalid))
+		assert.Panics(t, func() { cacheSizeFromEnv(key, 42) }, invalid)
+	}
 }
@@ -0,0 +1,94 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+// s3CredentialsProvider gets the credentials used to read the S3 objects of a source
+type s3CredentialsProvider interface {
+	// Credentials returns the credentials for a source with the given log processing role and session tags
+	Credentials(roleArn string, sessionTags map[string]string) (*credentials.Credentials, error)
+}
+
+// The provider for each kind of source credentials (see models.SourceIntegrationMetadata.S3CredentialsProvider)
+var s3CredentialsProviders = map[string]s3CredentialsProvider{
+	models.S3CredentialsAssumeRole: assumeRoleCredentialsProvider{},
+	models.S3CredentialsInstance:   instanceCredentialsProvider{},
+	models.S3CredentialsStatic:     newStaticCredentialsProvider(),
+}
+
+// getS3CredentialsProvider returns the kind and the provider of the credentials for a source
+func getS3CredentialsProvider(source *models.SourceIntegration) (string, s3CredentialsProvider, error) {
+	kind := source.S3CredentialsProvider
+	if kind == "" {
+		kind = models.S3CredentialsAssumeRole
+	}
+	provider, ok := s3CredentialsProviders[kind]
+	if !ok {
+		return "", nil, errors.Errorf("unknown S3 credentials provider %q for source %s", kind, source.IntegrationID)
+	}
+	return kind, provider, nil
+}
+
+// assumeRoleCredentialsProvider assumes the log processing role of the source with STS
+type assumeRoleCredentialsProvider struct{}
+
+func (assumeRoleCredentialsProvider) Credentials(roleArn string, sessionTags map[string]string) (*credentials.Credentials, error) {
+	creds := getAwsCredentials(roleArn, sessionTags)
+	if creds == nil {
+		return nil, errors.Errorf("failed to fetch credentials for assumed role %s", roleArn)
+	}
+	return creds, nil
+}
+
+// instanceCredentialsProvider uses the credentials of the log processor, e.g. when it runs in the account of the logs
+type instanceCredentialsProvider struct{}
+
+func (instanceCredentialsProvider) Credentials(_ string, _ map[string]string) (*credentials.Credentials, error) {
+	return common.Session.Config.Credentials, nil
+}
+
+// staticCredentialsProvider uses fixed credentials, configured with the SOURCE_S3_ACCESS_KEY_ID
+// and SOURCE_S3_SECRET_ACCESS_KEY environment variables
+type staticCredentialsProvider struct {
+	creds *credentials.Credentials
+}
+
+func newStaticCredentialsProvider() *staticCredentialsProvider {
+	accessKeyID, secretAccessKey := os.Getenv("SOURCE_S3_ACCESS_KEY_ID"), os.Getenv("SOURCE_S3_SECRET_ACCESS_KEY")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return &staticCredentialsProvider{}
+	}
+	return &staticCredentialsProvider{creds: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, "")}
+}
+
+func (p *staticCredentialsProvider) Credentials(_ string, _ map[string]string) (*credentials.Credentials, error) {
+	if p.creds == nil {
+		return nil, errors.New("no static S3 credentials are configured")
+	}
+	return p.creds, nil
+}
@@ -0,0 +1,205 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestGetS3CredentialsProvider(t *testing.T) {
+	source := &models.SourceIntegration{}
+	kind, provider, err := getS3CredentialsProvider(source)
+	require.NoError(t, err)
+	assert.Equal(t, models.S3CredentialsAssumeRole, kind)
+	assert.Equal(t, assumeRoleCredentialsProvider{}, provider)
+
+	source.S3CredentialsProvider = models.S3CredentialsInstance
+	kind, provider, err = getS3CredentialsProvider(source)
+	require.NoError(t, err)
+	assert.Equal(t, models.S3CredentialsInstance, kind)
+	assert.Equal(t, instanceCredentialsProvider{}, provider)
+
+	source.S3CredentialsProvider = "magic"
+	_, _, err = getS3CredentialsProvider(source)
+	assert.Error(t, err)
+}
+
+func TestStaticCredentialsProvider(t *testing.T) {
+	defer os.Unsetenv("SOURCE_S3_ACCESS_KEY_ID")
+	defer os.Unsetenv("SOURCE_S3_SECRET_ACCESS_KEY")
+
+	_, err := newStaticCredentialsProvider().Credentials("", nil)
+	assert.Error(t, err)
+
+	require.NoError(t, os.Setenv("SOURCE_S3_ACCESS_KEY_ID", "AKIAEXAMPLE"))
+	require.NoError(t, os.Setenv("SOURCE_S3_SECRET_ACCESS_KEY", "secret"))
+	creds, err := newStaticCredentialsProvider().Credentials("", nil)
+	require.NoError(t, err)
+	value, err := creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "AKIAEXAMPLE", value.AccessKeyID)
+	assert.Equal(t, "secret", value.SecretAccessKey)
+}
+
+func TestGetS3ClientStaticCredentials(t *testing.T) {
+	resetCaches()
+	defer resetCaches() // the cached clients were built with the static credentials
+	lambdaMock := &testutils.LambdaMock{}
+	common.LambdaClient = lambdaMock
+
+	staticCreds := credentials.NewStaticCredentials("AKIAEXAMPLE", "secret", "")
+	previousProvider := s3CredentialsProviders[models.S3CredentialsStatic]
+	defer func() { s3CredentialsProviders[models.S3CredentialsStatic] = previousProvider }()
+	s3CredentialsProviders[models.S3CredentialsStatic] = &staticCredentialsProvider{creds: staticCreds}
+
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			assert.Fail(t, "static credentials must not assume a role")
+			return nil
+		}
+
+	s3Mock := &testutils.S3Mock{}
+	var clientCreds []*credentials.Credentials
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		clientCreds = append(clientCreds, creds)
+		return s3Mock
+	}
+
+	staticIntegration := *integration
+	staticIntegration.IntegrationID = "5f0e5c8e-1c1a-4e0b-9d43-2b1f1d1b6a57" // the status of a new integration is updated
+	staticIntegration.S3CredentialsProvider = models.S3CredentialsStatic
+	marshaledResult, err := jsoniter.Marshal([]*models.SourceIntegration{&staticIntegration})
+	require.NoError(t, err)
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: marshaledResult}, nil).Once()
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil).Once()
+	s3Mock.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("test-bucket")}).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil).Once()
+
+	result, _, err := getS3Client(&S3ObjectInfo{S3Bucket: "test-bucket", S3ObjectKey: "prefix/key"})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	// Both the bucket location lookup and the cached client use the static credentials
+	require.Len(t, clientCreds, 2)
+	assert.Same(t, staticCreds, clientCreds[0])
+	assert.Same(t, staticCreds, clientCreds[1])
+	assert.True(t, s3ClientCache.Contains(s3ClientCacheKey{
+		credentialsProvider: models.S3CredentialsStatic,
+		roleArn:             integration.LogProcessingRole,
+		awsRegion:           "us-west-2",
+	}))
+
+	s3Mock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestGetS3ClientSessionTags(t *testing.T) {
+	resetCaches()
+	defer resetCaches()
+	lambdaMock := &testutils.LambdaMock{}
+	common.LambdaClient = lambdaMock
+
+	s3Mock := &testutils.S3Mock{}
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		return s3Mock
+	}
+
+	// Two integrations reading the same bucket with the same role, one of them with session tags
+	untagged := *integration
+	untagged.IntegrationID = "0d7c2f4e-8a4b-4f5e-9a55-3f2b6c1d2e3f"
+	untagged.S3Prefix = "prefix" // other tests replace the shared test integration
+	tagged := *integration
+	tagged.IntegrationID = "9a1f3c5e-7b2d-4e6f-8a0b-1c2d3e4f5a6b"
+	tagged.S3Prefix = "tagged-prefix"
+	tagged.LogProcessingRoleSessionTags = map[string]string{
+		"team":                "security",
+		"panther:integration": tagged.IntegrationID,
+	}
+	marshaledResult, err := jsoniter.Marshal([]*models.SourceIntegration{&untagged, &tagged})
+	require.NoError(t, err)
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: marshaledResult}, nil).Once()
+	lambdaMock.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, nil)
+	s3Mock.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("test-bucket")}).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil).Once()
+
+	var assumedTags [][]*sts.Tag
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			provider := &stscreds.AssumeRoleProvider{}
+			for _, option := range options {
+				option(provider)
+			}
+			assumedTags = append(assumedTags, provider.Tags)
+			return &credentials.Credentials{}
+		}
+
+	for _, key := range []string{"prefix/key", "tagged-prefix/key"} {
+		result, _, err := getS3Client(&S3ObjectInfo{S3Bucket: "test-bucket", S3ObjectKey: key})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+	}
+
+	// The untagged role is assumed as before, the tagged sessions get their own client
+	require.Len(t, assumedTags, 2)
+	assert.Nil(t, assumedTags[0])
+	assert.Equal(t, []*sts.Tag{
+		{Key: aws.String("panther:integration"), Value: aws.String(tagged.IntegrationID)},
+		{Key: aws.String("team"), Value: aws.String("security")},
+	}, assumedTags[1])
+	assert.Equal(t, 2, s3ClientCache.Len())
+	assert.True(t, s3ClientCache.Contains(s3ClientCacheKey{
+		credentialsProvider: models.S3CredentialsAssumeRole,
+		roleArn:             integration.LogProcessingRole,
+		sessionTags:         fmt.Sprintf("19:panther:integration=%d:%s,4:team=8:security,", len(tagged.IntegrationID), tagged.IntegrationID),
+		awsRegion:           "us-west-2",
+	}))
+	s3Mock.AssertExpectations(t)
+}
+
+func TestFormatSessionTags(t *testing.T) {
+	assert.Equal(t, "", formatSessionTags(nil))
+	assert.Equal(t, "4:team=8:security,", formatSessionTags(map[string]string{"team": "security"}))
+
+	// Tags containing the separators don't collide with other tag sets
+	assert.NotEqual(t,
+		formatSessionTags(map[string]string{"a": "b,c=d"}),
+		formatSessionTags(map[string]string{"a": "b", "c": "d"}))
+	assert.NotEqual(t,
+		formatSessionTags(map[string]string{"a=b": "c"}),
+		formatSessionTags(map[string]string{"a": "b=c"}))
+}
@@ -0,0 +1,146 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// S3ObjectSummary describes an object in the S3 bucket of a log source
+type S3ObjectSummary struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListSourceObjectsInput selects the objects of a source to list
+type ListSourceObjectsInput struct {
+	// Only objects last modified in the time window [Start, End) are listed.
+	// A zero End leaves the window open, up to the latest objects.
+	Start time.Time
+	End   time.Time
+
+	// At most MaxResults objects are returned. Zero or less means no limit.
+	MaxResults int
+
+	// The listing stops early after scanning MaxObjects objects (in the time window or not),
+	// or after MaxDuration, so that it can be used on huge buckets. Zero or less means no limit.
+	// The duration is checked between pages, so at least one page is always scanned.
+	MaxObjects  int
+	MaxDuration time.Duration
+
+	// ContinuationToken resumes a truncated listing, see S3ObjectListing
+	ContinuationToken string
+
+	// KeysInTimeOrder is set if the object keys under the prefix sort in the order the objects were written,
+	// e.g. when they start with a date like "2020/06/01/". Listing then stops at the first object
+	// modified at or after End, instead of going through the rest of the bucket.
+	KeysInTimeOrder bool
+}
+
+// S3ObjectListing is the result of ListSourceObjects
+type S3ObjectListing struct {
+	Objects []*S3ObjectSummary
+	// The number and total size in bytes of the listed objects
+	Count     int
+	TotalSize int64
+	// The number of objects scanned, in the time window or not
+	Scanned int
+	// True if the listing stopped because of MaxResults, MaxObjects or MaxDuration,
+	// so there may be more objects in the time window
+	Truncated bool
+	// Set the ContinuationToken of the input to this to list the rest of the objects, if Truncated
+	ContinuationToken string
+}
+
+// ListSourceObjects lists the objects under the configured S3 prefix of a source, which were last modified
+// in the given time window. It is meant for troubleshooting logs which were not processed.
+func ListSourceObjects(source *models.SourceIntegration, input *ListSourceObjectsInput) (*S3ObjectListing, error) {
+	s3Bucket, s3Prefix := getSourceS3Info(source)
+	if s3Bucket == "" {
+		return nil, errors.Errorf("source %s has no S3 bucket configured", source.IntegrationID)
+	}
+
+	client, err := getSourceS3Client(source, s3Bucket)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get S3 client for source %s", source.IntegrationID)
+	}
+
+	listing := &S3ObjectListing{}
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3Bucket),
+		Prefix: aws.String(s3Prefix),
+	}
+	// The continuation token is the key of the last scanned object. Unlike the page tokens of S3,
+	// it lets the listing resume in the middle of a page.
+	lastKey := input.ContinuationToken
+	if lastKey != "" {
+		listInput.StartAfter = aws.String(lastKey)
+	}
+	beforeEnd := func(lastModified time.Time) bool {
+		return input.End.IsZero() || lastModified.Before(input.End)
+	}
+	truncate := func() bool {
+		listing.Truncated = true
+		listing.ContinuationToken = lastKey
+		return false
+	}
+
+	started := time.Now()
+	err = client.ListObjectsV2Pages(listInput, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if input.MaxObjects > 0 && listing.Scanned >= input.MaxObjects {
+				return truncate()
+			}
+			lastModified := aws.TimeValue(object.LastModified)
+			if !beforeEnd(lastModified) && input.KeysInTimeOrder {
+				// All the following objects were written after the window
+				return false
+			}
+			if !lastModified.Before(input.Start) && beforeEnd(lastModified) {
+				if input.MaxResults > 0 && listing.Count >= input.MaxResults {
+					return truncate()
+				}
+				listing.Objects = append(listing.Objects, &S3ObjectSummary{
+					Key:          aws.StringValue(object.Key),
+					Size:         aws.Int64Value(object.Size),
+					LastModified: lastModified,
+				})
+				listing.Count++
+				listing.TotalSize += aws.Int64Value(object.Size)
+			}
+			listing.Scanned++
+			lastKey = aws.StringValue(object.Key)
+		}
+		if !lastPage && input.MaxDuration > 0 && time.Since(started) >= input.MaxDuration {
+			return truncate()
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list objects in s3://%s/%s", s3Bucket, s3Prefix)
+	}
+	return listing, nil
+}
@@ -0,0 +1,256 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var listWindowStart = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// Other tests replace the shared test integration, the listed source must keep its prefix
+var listedSource = &models.SourceIntegration{
+	SourceIntegrationMetadata: models.SourceIntegrationMetadata{
+		S3Bucket:          "test-bucket",
+		S3Prefix:          "prefix",
+		IntegrationType:   models.IntegrationTypeAWS3,
+		LogProcessingRole: "arn:aws:iam::123456789012:role/PantherLogProcessingRole-suffix",
+		IntegrationID:     "3e4b1734-e678-4581-b291-4b8a176219e9",
+	},
+}
+
+func setupListMocks() *testutils.S3Mock {
+	resetCaches()
+	s3Mock := &testutils.S3Mock{}
+	newS3ClientFunc = func(region *string, creds *credentials.Credentials) (result s3iface.S3API) {
+		return s3Mock
+	}
+	newCredentialsFunc =
+		func(c client.ConfigProvider, roleARN string, options ...func(*stscreds.AssumeRoleProvider)) *credentials.Credentials {
+			return &credentials.Credentials{}
+		}
+	s3Mock.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("test-bucket")}).Return(
+		&s3.GetBucketLocationOutput{LocationConstraint: aws.String("us-west-2")}, nil).Once()
+	return s3Mock
+}
+
+func listedObject(key string, lastModified time.Time) *s3.Object {
+	return &s3.Object{Key: aws.String(key), Size: aws.Int64(100), LastModified: aws.Time(lastModified)}
+}
+
+func TestListSourceObjects(t *testing.T) {
+	s3Mock := setupListMocks()
+	page := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/before", listWindowStart.Add(-time.Second)),
+		listedObject("prefix/first", listWindowStart),
+		listedObject("prefix/second", listWindowStart.Add(time.Minute)),
+		listedObject("prefix/after", listWindowStart.Add(time.Hour)),
+	}}
+	expectedInput := &s3.ListObjectsV2Input{Bucket: aws.String("test-bucket"), Prefix: aws.String("prefix")}
+	s3Mock.On("ListObjectsV2Pages", expectedInput, mock.Anything).Return(page, nil).Once()
+
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start:      listWindowStart,
+		End:        listWindowStart.Add(time.Hour),
+		MaxResults: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &S3ObjectListing{
+		Objects: []*S3ObjectSummary{
+			{Key: "prefix/first", Size: 100, LastModified: listWindowStart},
+			{Key: "prefix/second", Size: 100, LastModified: listWindowStart.Add(time.Minute)},
+		},
+		Count:     2,
+		TotalSize: 200,
+		Scanned:   4,
+	}, listing)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsKeysInTimeOrder(t *testing.T) {
+	s3Mock := setupListMocks()
+	page := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/2020/05/31/before", listWindowStart.Add(-time.Second)),
+		listedObject("prefix/2020/06/01/first", listWindowStart),
+		listedObject("prefix/2020/06/01/after", listWindowStart.Add(time.Hour)),
+		// Never reached, the listing stops at the first object after the window
+		listedObject("prefix/2020/06/01/late", listWindowStart.Add(time.Minute)),
+	}}
+	s3Mock.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(page, nil).Once()
+
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start:           listWindowStart,
+		End:             listWindowStart.Add(time.Hour),
+		KeysInTimeOrder: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, listing.Truncated)
+	require.Len(t, listing.Objects, 1)
+	assert.Equal(t, "prefix/2020/06/01/first", listing.Objects[0].Key)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsNoEnd(t *testing.T) {
+	s3Mock := setupListMocks()
+	page := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/2020/05/31/before", listWindowStart.Add(-time.Second)),
+		listedObject("prefix/2020/06/01/first", listWindowStart),
+		listedObject("prefix/2020/06/02/latest", time.Now()),
+	}}
+	s3Mock.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(page, nil).Once()
+
+	// Without an End, the time order of the keys does not stop the listing
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start:           listWindowStart,
+		KeysInTimeOrder: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, listing.Objects, 2)
+	assert.Equal(t, "prefix/2020/06/01/first", listing.Objects[0].Key)
+	assert.Equal(t, "prefix/2020/06/02/latest", listing.Objects[1].Key)
+	assert.Equal(t, 3, listing.Scanned)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsMaxResults(t *testing.T) {
+	s3Mock := setupListMocks()
+	page := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/first", listWindowStart),
+		listedObject("prefix/second", listWindowStart.Add(time.Minute)),
+	}}
+	s3Mock.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(page, nil).Once()
+
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start:      listWindowStart,
+		End:        listWindowStart.Add(time.Hour),
+		MaxResults: 1,
+	})
+	require.NoError(t, err)
+	assert.True(t, listing.Truncated)
+	require.Len(t, listing.Objects, 1)
+	assert.Equal(t, "prefix/first", listing.Objects[0].Key)
+	assert.Equal(t, 1, listing.Count)
+	assert.Equal(t, int64(100), listing.TotalSize)
+	assert.Equal(t, "prefix/first", listing.ContinuationToken)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsMaxObjectsResume(t *testing.T) {
+	s3Mock := setupListMocks()
+	firstPage := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/before", listWindowStart.Add(-time.Second)),
+		listedObject("prefix/first", listWindowStart),
+		listedObject("prefix/second", listWindowStart.Add(time.Minute)),
+	}}
+	s3Mock.On("ListObjectsV2Pages", &s3.ListObjectsV2Input{
+		Bucket: aws.String("test-bucket"),
+		Prefix: aws.String("prefix"),
+	}, mock.Anything).Return(firstPage, nil).Once()
+
+	input := &ListSourceObjectsInput{
+		Start:      listWindowStart,
+		End:        listWindowStart.Add(time.Hour),
+		MaxObjects: 2,
+	}
+	listing, err := ListSourceObjects(listedSource, input)
+	require.NoError(t, err)
+	assert.True(t, listing.Truncated)
+	assert.Equal(t, 2, listing.Scanned)
+	assert.Equal(t, "prefix/first", listing.ContinuationToken)
+	require.Len(t, listing.Objects, 1)
+	assert.Equal(t, "prefix/first", listing.Objects[0].Key)
+
+	// The follow-up call lists the objects after the last scanned one
+	secondPage := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/second", listWindowStart.Add(time.Minute)),
+	}}
+	s3Mock.On("ListObjectsV2Pages", &s3.ListObjectsV2Input{
+		Bucket:     aws.String("test-bucket"),
+		Prefix:     aws.String("prefix"),
+		StartAfter: aws.String("prefix/first"),
+	}, mock.Anything).Return(secondPage, nil).Once()
+
+	input.ContinuationToken = listing.ContinuationToken
+	listing, err = ListSourceObjects(listedSource, input)
+	require.NoError(t, err)
+	assert.False(t, listing.Truncated)
+	assert.Empty(t, listing.ContinuationToken)
+	require.Len(t, listing.Objects, 1)
+	assert.Equal(t, "prefix/second", listing.Objects[0].Key)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsMaxDuration(t *testing.T) {
+	s3Mock := setupListMocks()
+	page := &s3.ListObjectsV2Output{Contents: []*s3.Object{
+		listedObject("prefix/first", listWindowStart),
+		listedObject("prefix/after", listWindowStart.Add(time.Hour)),
+	}}
+	// The mock always reports more pages, the listing stops after the first one since the budget is spent
+	s3Mock.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(page, nil).Once()
+
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start:       listWindowStart,
+		End:         listWindowStart.Add(time.Hour),
+		MaxDuration: time.Nanosecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, listing.Truncated)
+	assert.Equal(t, 2, listing.Scanned)
+	assert.Equal(t, "prefix/after", listing.ContinuationToken)
+	require.Len(t, listing.Objects, 1)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsError(t *testing.T) {
+	s3Mock := setupListMocks()
+	s3Mock.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(
+		&s3.ListObjectsV2Output{}, errors.New("access denied")).Once()
+
+	listing, err := ListSourceObjects(listedSource, &ListSourceObjectsInput{
+		Start: listWindowStart,
+		End:   listWindowStart.Add(time.Hour),
+	})
+	require.Error(t, err)
+	assert.Nil(t, listing)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestListSourceObjectsNoBucket(t *testing.T) {
+	_, err := ListSourceObjects(&models.SourceIntegration{}, &ListSourceObjectsInput{
+		Start: listWindowStart,
+		End:   listWindowStart.Add(time.Hour),
+	})
+	require.Error(t, err)
+}
@@ -16,9 +16,9 @@
 
 import hashlib
 import os
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from datetime import datetime
-from typing import Optional
+from typing import List, Optional
 
 import boto3
 
@@ -38,6 +38,7 @@ _ALERT_COUNT_ATTR_NAME = 'alertCount'
 _ALERT_EVENT_COUNT = 'eventCount'
 _ALERT_LOG_TYPES = 'logTypes'
 _ALERT_TITLE = 'title'
+_ALERT_AWS_ACCOUNT_IDS = 'awsAccountIds'
 
 
 # pylint: disable=too-many-instance-attributes
@@ -52,6 +53,8 @@ class MatchingGroupInfo:
     num_matches: int
     title: Optional[str]
     processing_time: datetime
+    # The AWS accounts the matched events are associated with
+    aws_account_ids: List[str] = field(default_factory=list)
 
 
 def _generate_dedup_key(rule_id: str, dedup: str) -> str:
@@ -87,6 +90,8 @@ def _update_get_conditional(group_info: MatchingGroupInfo) -> AlertInfo:
 
     if group_info.title:
         update_expression += ', #11=:11'
+    if group_info.aws_account_ids:
+        update_expression += ', #12=:12'
     expresion_attribute_names = {
         '#1': _ALERT_CREATION_TIME_ATTR_NAME,
         '#2': _PARTITION_KEY_NAME,
@@ -102,6 +107,8 @@ def _update_get_conditional(group_info: MatchingGroupInfo) -> AlertInfo:
 
     if group_info.title:
         expresion_attribute_names['#11'] = _ALERT_TITLE
+    if group_info.aws_account_ids:
+        expresion_attribute_names['#12'] = _ALERT_AWS_ACCOUNT_IDS
 
     expression_attribute_values = {
         ':1':
@@ -137,6 +144,8 @@ def _update_get_conditional(group_info: MatchingGroupInfo) -> AlertInfo:
 
     if group_info.title:
         expression_attribute_values[':11'] = {'S': group_info.title}
+    if group_info.aws_account_ids:
+        expression_attribute_values[':12'] = {'SS': group_info.aws_account_ids}
 
     response = _DDB_CLIENT.update_item(
         TableName=_DDB_TABLE_NAME,
@@ -159,31 +168,36 @@ def _update_get(group_info: MatchingGroupInfo) -> AlertInfo:
     """Updates the following attributes in DDB:
     1. Alert event account - it adds the new events to existing
     2. Alert Update Time - it sets it to given time
+    3. Alert AWS accounts - it adds the accounts of the new events to existing
     """
+    # Setting proper value to alertUpdateTime. Increase event count
+    update_expression = 'SET #1=:1\nADD #2 :2, #3 :3'
+    expression_attribute_names = {'#1': _ALERT_UPDATE_TIME_ATTR_NAME, '#2': _ALERT_EVENT_COUNT, '#3': _ALERT_LOG_TYPES}
+    expression_attribute_values = {
+        ':1': {
+            'N': group_info.processing_time.strftime('%s')
+        },
+        ':2': {
+            'N': '{}'.format(group_info.num_matches)
+        },
+        ':3': {
+            'SS': [group_info.log_type]
+        },
+    }
+    # Empty sets are not allowed in DDB
+    if group_info.aws_account_ids:
+        update_expression += ', #4 :4'
+        expression_attribute_names['#4'] = _ALERT_AWS_ACCOUNT_IDS
+        expression_attribute_values[':4'] = {'SS': group_info.aws_account_ids}
 
     response = _DDB_CLIENT.update_item(
         TableName=_DDB_TABLE_NAME,
         Key={_PARTITION_KEY_NAME: {
             'S': _generate_dedup_key(group_info.rule_id, group_info.dedup)
         }},
-        # Setting proper value to alertUpdateTime. Increase event count
-        UpdateExpression='SET #1=:1\nADD #2 :2, #3 :3',
-        ExpressionAttributeNames={
-            '#1': _ALERT_UPDATE_TIME_ATTR_NAME,
-            '#2': _ALERT_EVENT_COUNT,
-            '#3': _ALERT_LOG_TYPES
-        },
-        ExpressionAttributeValues={
-            ':1': {
-                'N': group_info.processing_time.strftime('%s')
-            },
-            ':2': {
-                'N': '{}'.format(group_info.num_matches)
-            },
-            ':3': {
-                'SS': [group_info.log_type]
-            },
-        },
+        UpdateExpression=update_expression,
+        ExpressionAttributeNames=expression_attribute_names,
+        ExpressionAttributeValues=expression_attribute_values,
         ReturnValues='ALL_NEW'
     )
     alert_count = response['Attributes'][_ALERT_COUNT_ATTR_NAME]['N']
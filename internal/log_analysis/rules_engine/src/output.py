@@ -127,7 +127,8 @@ def _write_to_s3(time: datetime, key: OutputGroupingKey, events: List[EventMatch
         dedup_period_mins=events[0].dedup_period_mins,
         num_matches=len(events),
         title=events[0].title,
-        processing_time=time
+        processing_time=time,
+        aws_account_ids=_aws_account_ids(events)
     )
     alert_info = update_get_alert_info(group_info)
     data_stream = BytesIO()
@@ -167,6 +168,14 @@ def _write_to_s3(time: datetime, key: OutputGroupingKey, events: List[EventMatch
     )
 
 
+def _aws_account_ids(events: List[EventMatch]) -> List[str]:
+    """Returns the AWS accounts the events are associated with, as extracted by the log processor"""
+    account_ids = set()
+    for event in events:
+        account_ids.update(event.event.get('p_any_aws_account_ids') or [])
+    return sorted(account_ids)
+
+
 def _s3_put_object_notification(bucket: str, key: str, byte_size: int) -> Dict[str, list]:
     """The notification that will be sent to the SNS topic when we create a new object in S3.
 
@@ -335,3 +335,53 @@ class TestMatchedEventsBuffer(TestCase):
         # Assert that the buffer has been cleared
         self.assertEqual(len(buffer.data), 0)
         self.assertEqual(buffer.bytes_in_memory, 0)
+
+    def test_group_events_aws_account_ids(self) -> None:
+        buffer = MatchedEventsBuffer()
+        for event in [{'p_any_aws_account_ids': ['123456789012']}, {'p_any_aws_account_ids': ['210987654321', '123456789012']}, {}]:
+            buffer.add_event(EventMatch(rule_id='id', rule_version='version', log_type='log', dedup='dedup', dedup_period_mins=100, event=event))
+
+        DDB_MOCK.update_item.return_value = {'Attributes': {'alertCount': {'N': '1'}}}
+        buffer.flush()
+
+        DDB_MOCK.update_item.assert_called_once()
+        _, call_args = DDB_MOCK.update_item.call_args
+        self.assertTrue(call_args['UpdateExpression'].endswith(', #12=:12'))
+        self.assertEqual(call_args['ExpressionAttributeNames']['#12'], 'awsAccountIds')
+        self.assertEqual(call_args['ExpressionAttributeValues'][':12'], {'SS': ['123456789012', '210987654321']})
+
+    def test_merge_events_aws_account_ids(self) -> None:
+        buffer = MatchedEventsBuffer()
+        buffer.add_event(
+            EventMatch(
+                rule_id='id',
+                rule_version='version',
+                log_type='log',
+                dedup='dedup',
+                dedup_period_mins=100,
+                event={'p_any_aws_account_ids': ['123456789012']}
+            )
+        )
+
+        # The conditional update fails if the alert already exists, its events are merged
+        DDB_MOCK.exceptions.ConditionalCheckFailedException = ValueError
+        self.addCleanup(setattr, DDB_MOCK.update_item, 'side_effect', None)
+        DDB_MOCK.update_item.side_effect = [
+            ValueError(), {
+                'Attributes': {
+                    'alertCount': {
+                        'N': '1'
+                    },
+                    'alertCreationTime': {
+                        'N': '1000000'
+                    }
+                }
+            }
+        ]
+        buffer.flush()
+
+        self.assertEqual(DDB_MOCK.update_item.call_count, 2)
+        _, call_args = DDB_MOCK.update_item.call_args
+        self.assertEqual(call_args['UpdateExpression'], 'SET #1=:1\nADD #2 :2, #3 :3, #4 :4')
+        self.assertEqual(call_args['ExpressionAttributeNames']['#4'], 'awsAccountIds')
+        self.assertEqual(call_args['ExpressionAttributeValues'][':4'], {'SS': ['123456789012']})
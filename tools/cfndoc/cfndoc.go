@@ -21,6 +21,7 @@ package cfndoc
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -70,6 +71,7 @@ type ResourceDoc struct {
 	FieldName     string // "QueueName", "FunctionName", etc
 	Resource      string // referenced label
 	Documentation string
+	Source        string // CloudFormation file the resource is defined in (set by Read)
 }
 
 func ReadCfn(paths ...string) (docs []*ResourceDoc, err error) {
@@ -82,7 +84,10 @@ func ReadCfn(paths ...string) (docs []*ResourceDoc, err error) {
 	}
 	sort.Slice(docs, func(i, j int) bool {
 		if docs[i].Resource == docs[j].Resource {
-			// Same resource name: break ties by resource "type" based on field name
+			// Same resource name: break ties by resource "type" based on field name, then by file
+			if docs[i].FieldName == docs[j].FieldName {
+				return docs[i].Source < docs[j].Source
+			}
 			return docs[i].FieldName < docs[j].FieldName
 		}
 
@@ -98,7 +103,35 @@ func Read(fileName string) ([]*ResourceDoc, error) {
 		return nil, errors.Wrapf(err, "cannot read %s for doc extraction", fileName)
 	}
 
-	return Parse(string(cfn)), nil
+	docs := Parse(string(cfn))
+	for _, doc := range docs {
+		doc.Source = filepath.ToSlash(fileName)
+	}
+	return docs, nil
+}
+
+// AmbiguousResources returns the resource names which are documented in more than one file,
+// mapped to the (sorted) files which define them.
+func AmbiguousResources(docs []*ResourceDoc) map[string][]string {
+	sources := make(map[string]map[string]bool)
+	for _, doc := range docs {
+		if sources[doc.Resource] == nil {
+			sources[doc.Resource] = make(map[string]bool)
+		}
+		sources[doc.Resource][doc.Source] = true
+	}
+
+	result := make(map[string][]string)
+	for resource, files := range sources {
+		if len(files) < 2 {
+			continue
+		}
+		for file := range files {
+			result[resource] = append(result[resource], file)
+		}
+		sort.Strings(result[resource])
+	}
+	return result
 }
 
 func Parse(cfn string) (docs []*ResourceDoc) {
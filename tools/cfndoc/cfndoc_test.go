@@ -19,6 +19,9 @@ package cfndoc
  */
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -104,3 +107,35 @@ func TestNoMatch(t *testing.T) {
 	nomatch = `</cfndoc> <cfndoc>`
 	require.Equal(t, expected, Parse(nomatch))
 }
+
+func TestReadCfnSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfndoc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	core := filepath.Join(dir, "core.yml")
+	logs := filepath.Join(dir, "logs.yml")
+	require.NoError(t, ioutil.WriteFile(core, []byte(`QueueName: queue <cfndoc>core queue</cfndoc>
+FunctionName: shared <cfndoc>core function</cfndoc>`), 0600))
+	require.NoError(t, ioutil.WriteFile(logs, []byte(`FunctionName: shared <cfndoc>logs function</cfndoc>`), 0600))
+
+	docs, err := ReadCfn(logs, core)
+	require.NoError(t, err)
+	require.Equal(t, []*ResourceDoc{
+		{FieldName: "QueueName", Resource: "queue", Documentation: "core queue", Source: filepath.ToSlash(core)},
+		{FieldName: "FunctionName", Resource: "shared", Documentation: "core function", Source: filepath.ToSlash(core)},
+		{FieldName: "FunctionName", Resource: "shared", Documentation: "logs function", Source: filepath.ToSlash(logs)},
+	}, docs)
+
+	require.Equal(t, map[string][]string{
+		"shared": {filepath.ToSlash(core), filepath.ToSlash(logs)},
+	}, AmbiguousResources(docs))
+}
+
+func TestAmbiguousResourcesSameFile(t *testing.T) {
+	docs := []*ResourceDoc{
+		{FieldName: "QueueName", Resource: "queue", Source: "deployments/core.yml"},
+		{FieldName: "TableName", Resource: "queue", Source: "deployments/core.yml"},
+	}
+	require.Empty(t, AmbiguousResources(docs))
+}
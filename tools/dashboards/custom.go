@@ -0,0 +1,194 @@
+package dashboards
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
+)
+
+// CustomWidgetsDir holds additional widgets which are merged into the generated dashboards.
+//
+// Each file is named after the dashboard it extends (e.g. PantherOverview.json) and holds a JSON object
+// with a "widgets" list, in the same format as the "View/edit source" JSON of the CloudWatch console.
+// Every custom widget must set its "x" and "y" position, and may not overlap any other widget.
+var CustomWidgetsDir = filepath.Join("deployments", "dashboards")
+
+// CloudWatch dashboards are a grid 24 units wide
+const (
+	gridWidth     = 24
+	defaultWidth  = 6
+	defaultHeight = 6
+)
+
+// Merged dashboards are marshaled with sorted keys, so that regenerating them is stable
+var dashboardJSON = jsoniter.Config{SortMapKeys: true}.Froze()
+
+// The position of a widget on the dashboard grid
+type widgetLayout struct {
+	X      *int `json:"x"`
+	Y      *int `json:"y"`
+	Width  *int `json:"width"`
+	Height *int `json:"height"`
+}
+
+type rectangle struct {
+	x, y, width, height int
+}
+
+func (r rectangle) overlaps(other rectangle) bool {
+	return r.x < other.x+other.width && other.x < r.x+r.width &&
+		r.y < other.y+other.height && other.y < r.y+r.height
+}
+
+// LoadCustomWidgets reads the custom widgets in dir, keyed by dashboard name.
+//
+// A missing or empty directory is not an error, there are just no custom widgets.
+func LoadCustomWidgets(dir string) (map[string][]interface{}, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]interface{}, len(paths))
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		var custom struct {
+			Widgets []interface{} `json:"widgets"`
+		}
+		if err := jsoniter.Unmarshal(contents, &custom); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		result[name] = custom.Widgets
+	}
+	return result, nil
+}
+
+// DashboardsWithCustomWidgets returns all the declared dashboards, extended with the custom widgets in dir
+func DashboardsWithCustomWidgets(dir string) ([]*cloudwatchcf.Dashboard, error) {
+	custom, err := LoadCustomWidgets(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]*cloudwatchcf.Dashboard, 0, len(stockDashboards))
+	for _, stock := range stockDashboards {
+		body := stock.body
+		if widgets, ok := custom[stock.name]; ok {
+			if body, err = mergeWidgets(stock.name, body, widgets); err != nil {
+				return nil, err
+			}
+			delete(custom, stock.name)
+		}
+		dashboards = append(dashboards, cloudwatchcf.NewDashboard(stock.name, body))
+	}
+
+	if len(custom) > 0 {
+		unknown := make([]string, 0, len(custom))
+		for name := range custom {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("custom widgets for unknown dashboards in %s: %s", dir, strings.Join(unknown, ", "))
+	}
+	return dashboards, nil
+}
+
+// Add widgets to the JSON body of a dashboard, returning the new body.
+//
+// An error is returned if a custom widget has no position, falls off the grid or overlaps another widget.
+func mergeWidgets(name, body string, widgets []interface{}) (string, error) {
+	var dashboard map[string]interface{}
+	if err := jsoniter.UnmarshalFromString(body, &dashboard); err != nil {
+		return "", fmt.Errorf("failed to parse dashboard %s: %v", name, err)
+	}
+	existing, _ := dashboard["widgets"].([]interface{})
+
+	// Positions of the stock widgets which CloudWatch places automatically are unknown, so they are not checked
+	var occupied []rectangle
+	for _, widget := range existing {
+		if rect, ok, err := widgetRectangle(widget); err == nil && ok {
+			occupied = append(occupied, rect)
+		}
+	}
+
+	for i, widget := range widgets {
+		rect, ok, err := widgetRectangle(widget)
+		if err != nil {
+			return "", fmt.Errorf("dashboard %s: custom widget %d: %v", name, i, err)
+		}
+		if !ok {
+			return "", fmt.Errorf("dashboard %s: custom widget %d must set its x and y position", name, i)
+		}
+		if rect.x < 0 || rect.y < 0 || rect.width <= 0 || rect.height <= 0 || rect.x+rect.width > gridWidth {
+			return "", fmt.Errorf("dashboard %s: custom widget %d does not fit in the %d unit wide grid",
+				name, i, gridWidth)
+		}
+		for _, other := range occupied {
+			if rect.overlaps(other) {
+				return "", fmt.Errorf("dashboard %s: custom widget %d at (%d, %d) overlaps another widget at (%d, %d)",
+					name, i, rect.x, rect.y, other.x, other.y)
+			}
+		}
+		occupied = append(occupied, rect)
+	}
+
+	dashboard["widgets"] = append(existing, widgets...)
+	merged, err := dashboardJSON.MarshalIndent(dashboard, "", "    ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal dashboard %s: %v", name, err)
+	}
+	return string(merged), nil
+}
+
+// Returns the grid area covered by a widget, and false if the widget has no explicit position
+func widgetRectangle(widget interface{}) (rectangle, bool, error) {
+	raw, err := jsoniter.Marshal(widget)
+	if err != nil {
+		return rectangle{}, false, err
+	}
+	var layout widgetLayout
+	if err := jsoniter.Unmarshal(raw, &layout); err != nil {
+		return rectangle{}, false, fmt.Errorf("invalid widget position: %v", err)
+	}
+	if layout.X == nil || layout.Y == nil {
+		return rectangle{}, false, nil
+	}
+
+	rect := rectangle{x: *layout.X, y: *layout.Y, width: defaultWidth, height: defaultHeight}
+	if layout.Width != nil {
+		rect.width = *layout.Width
+	}
+	if layout.Height != nil {
+		rect.height = *layout.Height
+	}
+	return rect, true, nil
+}
@@ -0,0 +1,132 @@
+package dashboards
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDashboardJSON = `
+{
+    "widgets": [
+        {"type": "metric", "x": 0, "y": 0, "width": 12, "height": 6, "properties": {"region": "us-east-1"}},
+        {"type": "text", "properties": {"markdown": "auto placed"}}
+    ]
+}`
+
+func TestMergeWidgets(t *testing.T) {
+	custom := []interface{}{
+		map[string]interface{}{"type": "metric", "x": 12, "y": 0, "width": 12, "height": 6},
+		map[string]interface{}{"type": "metric", "x": 0, "y": 6}, // default size
+	}
+	body, err := mergeWidgets("Test", testDashboardJSON, custom)
+	require.NoError(t, err)
+
+	var dashboard struct {
+		Widgets []map[string]interface{} `json:"widgets"`
+	}
+	require.NoError(t, jsoniter.UnmarshalFromString(body, &dashboard))
+	require.Len(t, dashboard.Widgets, 4)
+	assert.Equal(t, "text", dashboard.Widgets[1]["type"])
+	assert.Equal(t, float64(12), dashboard.Widgets[2]["x"])
+	assert.Equal(t, float64(6), dashboard.Widgets[3]["y"])
+
+	// Merging is deterministic
+	again, err := mergeWidgets("Test", testDashboardJSON, custom)
+	require.NoError(t, err)
+	assert.Equal(t, body, again)
+}
+
+func TestMergeWidgetsOverlap(t *testing.T) {
+	_, err := mergeWidgets("Test", testDashboardJSON, []interface{}{
+		map[string]interface{}{"type": "metric", "x": 6, "y": 3, "width": 12, "height": 6},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "dashboard Test: custom widget 0 at (6, 3) overlaps another widget at (0, 0)", err.Error())
+}
+
+func TestMergeWidgetsOverlapEachOther(t *testing.T) {
+	_, err := mergeWidgets("Test", testDashboardJSON, []interface{}{
+		map[string]interface{}{"type": "metric", "x": 0, "y": 10},
+		map[string]interface{}{"type": "metric", "x": 3, "y": 12},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "custom widget 1 at (3, 12) overlaps")
+}
+
+func TestMergeWidgetsNoPosition(t *testing.T) {
+	_, err := mergeWidgets("Test", testDashboardJSON, []interface{}{
+		map[string]interface{}{"type": "metric"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "dashboard Test: custom widget 0 must set its x and y position", err.Error())
+}
+
+func TestMergeWidgetsOffGrid(t *testing.T) {
+	_, err := mergeWidgets("Test", testDashboardJSON, []interface{}{
+		map[string]interface{}{"type": "metric", "x": 20, "y": 6, "width": 6},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not fit")
+}
+
+func TestDashboardsWithCustomWidgets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "panther-dashboards")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	widgets := `{"widgets": [{"type": "text", "x": 0, "y": 100, "properties": {"markdown": "custom"}}]}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "PantherOverview.json"), []byte(widgets), 0600))
+
+	result, err := DashboardsWithCustomWidgets(dir)
+	require.NoError(t, err)
+	stock := Dashboards()
+	require.Len(t, result, len(stock))
+
+	assert.Contains(t, result[0].Properties.DashboardBody.Sub, `"markdown": "custom"`)
+	// The other dashboards are unchanged
+	for i := 1; i < len(stock); i++ {
+		assert.Equal(t, stock[i], result[i])
+	}
+}
+
+func TestDashboardsWithCustomWidgetsUnknownDashboard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "panther-dashboards")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Missing.json"), []byte(`{"widgets": []}`), 0600))
+
+	_, err = DashboardsWithCustomWidgets(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown dashboards")
+}
+
+func TestDashboardsWithoutCustomWidgets(t *testing.T) {
+	result, err := DashboardsWithCustomWidgets(filepath.Join("testdata", "does-not-exist"))
+	require.NoError(t, err)
+	assert.Equal(t, Dashboards(), result)
+}
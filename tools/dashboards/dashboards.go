@@ -29,19 +29,31 @@ insert the region in the JSON at various places. This is tedious
 and error prone. Generating the CF files is much simpler for the
 developer and easier to read.
 
+Operators can add their own widgets without changing this package, see CustomWidgetsDir.
+
 The methodology for adding dashboards is to:
 1. Design in the AWS CloutWatch Console
 2. Use the "View/edit source" option to copy the JSON for the dashboard
 3. Create a new file holding a global var bound to the JSON
-4. Add a line to Dashboards() below instantiating the CF dashboard resource
+4. Add an entry to stockDashboards below with the name of the dashboard and its JSON
 */
 
+// The declared dashboards, in the order they are generated
+var stockDashboards = []struct {
+	name string
+	body string
+}{
+	{"PantherOverview", overviewJSON},
+	{"PantherCloudSecurity", infraJSON},
+	{"PantherAlertProcessing", alertsJSON},
+	{"PantherRemediation", remediationJSON},
+	{"PantherLogAnalysis", logProcessingJSON},
+}
+
 // Dashboards returns all the declared dashboards
 func Dashboards() (dashboards []*cloudwatchcf.Dashboard) {
-	dashboards = append(dashboards, cloudwatchcf.NewDashboard("PantherOverview", overviewJSON))
-	dashboards = append(dashboards, cloudwatchcf.NewDashboard("PantherCloudSecurity", infraJSON))
-	dashboards = append(dashboards, cloudwatchcf.NewDashboard("PantherAlertProcessing", alertsJSON))
-	dashboards = append(dashboards, cloudwatchcf.NewDashboard("PantherRemediation", remediationJSON))
-	dashboards = append(dashboards, cloudwatchcf.NewDashboard("PantherLogAnalysis", logProcessingJSON))
+	for _, stock := range stockDashboards {
+		dashboards = append(dashboards, cloudwatchcf.NewDashboard(stock.name, stock.body))
+	}
 	return dashboards
 }
@@ -0,0 +1,171 @@
+package gluetype
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+)
+
+// This parses Glue (Hive) column types such as `array<struct<name:string,tags:map<string,string>>>`
+// into a tree which can be inspected and rendered.
+
+// Kind is the kind of a Glue type
+type Kind int
+
+const (
+	// Primitive types, e.g. string, bigint, timestamp
+	Primitive Kind = iota
+	// Array types, e.g. array<string>
+	Array
+	// Map types, e.g. map<string,bigint>
+	Map
+	// Struct types, e.g. struct<name:string,size:bigint>
+	Struct
+)
+
+// Type is a parsed Glue type
+type Type struct {
+	Kind Kind
+	// Name of a primitive type
+	Name string
+	// Element type of an array
+	Element *Type
+	// Key and value types of a map
+	Key   *Type
+	Value *Type
+	// Fields of a struct, in order
+	Fields []Field
+}
+
+// Field is a named field of a struct type
+type Field struct {
+	Name string
+	Type *Type
+}
+
+var complexKinds = []struct {
+	prefix string
+	kind   Kind
+}{
+	{"array", Array},
+	{"struct", Struct},
+	{"map", Map},
+}
+
+// Parse parses a Glue type
+func Parse(glueType string) (*Type, error) {
+	for _, complexKind := range complexKinds {
+		if !strings.HasPrefix(glueType, complexKind.prefix) {
+			continue
+		}
+		if !strings.HasPrefix(glueType, complexKind.prefix+"<") || !strings.HasSuffix(glueType, ">") {
+			return nil, fmt.Errorf("could not parse %s type `%s`", complexKind.prefix, glueType)
+		}
+		return parseComplex(complexKind.kind, complexKind.prefix, glueType)
+	}
+
+	// if NOT a complex type it is a primitive Glue type
+	return &Type{Kind: Primitive, Name: glueType}, nil
+}
+
+func parseComplex(kind Kind, prefix, glueType string) (*Type, error) {
+	fields := splitTypeFields(glueType[len(prefix)+1 : len(glueType)-1])
+	switch kind {
+	case Array:
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("could not parse array type `%s`", glueType)
+		}
+		element, err := Parse(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: Array, Element: element}, nil
+	case Map:
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("could not parse map type `%s`", glueType)
+		}
+		key, err := Parse(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := Parse(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Kind: Map, Key: key, Value: value}, nil
+	default:
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("could not parse struct type `%s`", glueType)
+		}
+		result := &Type{Kind: Struct, Fields: make([]Field, len(fields))}
+		for i, field := range fields {
+			splitIndex := strings.Index(field, ":") // name:type (can't use Split() cuz type can have ':'
+			if splitIndex == -1 {
+				return nil, fmt.Errorf("could not parse struct field `%s` of `%s`", field, glueType)
+			}
+			fieldType, err := Parse(field[splitIndex+1:])
+			if err != nil {
+				return nil, err
+			}
+			result.Fields[i] = Field{Name: field[:splitIndex], Type: fieldType}
+		}
+		return result, nil
+	}
+}
+
+// split fields into subFields around top level commas in type definition
+func splitTypeFields(fields string) (subFields []string) {
+	startSubfieldIndex := 0
+	insideBracketCount := 0 // when non-zero we are inside a complex type
+	for index := range fields {
+		if fields[index] == ',' && insideBracketCount == 0 {
+			subFields = append(subFields, fields[startSubfieldIndex:index])
+			startSubfieldIndex = index + 1 // next
+		}
+		// track context
+		if fields[index] == '<' {
+			insideBracketCount++
+		} else if fields[index] == '>' {
+			insideBracketCount--
+		}
+	}
+	if len(fields[startSubfieldIndex:]) > 0 { // the rest
+		subFields = append(subFields, fields[startSubfieldIndex:])
+	}
+	return subFields
+}
+
+// String returns the canonical Glue type
+func (t *Type) String() string {
+	switch t.Kind {
+	case Array:
+		return "array<" + t.Element.String() + ">"
+	case Map:
+		return "map<" + t.Key.String() + "," + t.Value.String() + ">"
+	case Struct:
+		fields := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			fields[i] = field.Name + ":" + field.Type.String()
+		}
+		return "struct<" + strings.Join(fields, ",") + ">"
+	default:
+		return t.Name
+	}
+}
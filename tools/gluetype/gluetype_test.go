@@ -0,0 +1,82 @@
+package gluetype
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse("struct<name:string,tags:array<string>,attributes:map<string,struct<value:bigint>>>")
+	require.NoError(t, err)
+	expected := &Type{
+		Kind: Struct,
+		Fields: []Field{
+			{Name: "name", Type: &Type{Kind: Primitive, Name: "string"}},
+			{Name: "tags", Type: &Type{Kind: Array, Element: &Type{Kind: Primitive, Name: "string"}}},
+			{Name: "attributes", Type: &Type{
+				Kind: Map,
+				Key:  &Type{Kind: Primitive, Name: "string"},
+				Value: &Type{Kind: Struct, Fields: []Field{
+					{Name: "value", Type: &Type{Kind: Primitive, Name: "bigint"}},
+				}},
+			}},
+		},
+	}
+	assert.Equal(t, expected, parsed)
+}
+
+func TestParsePrimitive(t *testing.T) {
+	parsed, err := Parse("timestamp")
+	require.NoError(t, err)
+	assert.Equal(t, &Type{Kind: Primitive, Name: "timestamp"}, parsed)
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, glueType := range []string{
+		"string",
+		"array<bigint>",
+		"map<string,array<string>>",
+		"struct<a:string,b:struct<c:map<string,string>,d:array<struct<e:double>>>>",
+		"struct<time:timestamp,url:string>", // field names may contain type keywords
+	} {
+		parsed, err := Parse(glueType)
+		require.NoError(t, err, glueType)
+		assert.Equal(t, glueType, parsed.String())
+	}
+}
+
+func TestParseFail(t *testing.T) {
+	for glueType, expected := range map[string]string{
+		"array<>":            "could not parse array type `array<>`",
+		"array<foo,bar,zot>": "could not parse array type `array<foo,bar,zot>`",
+		"array":              "could not parse array type `array`",
+		"map<>":              "could not parse map type `map<>`",
+		"map<foo,bar,zot>":   "could not parse map type `map<foo,bar,zot>`",
+		"struct<>":           "could not parse struct type `struct<>`",
+		"struct<foo,bar>":    "could not parse struct field `foo` of `struct<foo,bar>`",
+		"array<map<string>>": "could not parse map type `map<string>`",
+	} {
+		_, err := Parse(glueType)
+		assert.EqualError(t, err, expected, glueType)
+	}
+}
@@ -234,6 +234,9 @@ func (b Build) tools() error {
 }
 
 // Generate CloudFormation: deployments/dashboards.yml and out/deployments/
+//
+// With PLAN=true, only print the diff of deployments/dashboards.yml without writing anything.
+// With DASHBOARDS_SUMMARY=true, list the dashboard changes in a comment at the top of deployments/dashboards.yml.
 func (b Build) Cfn() {
 	if err := b.cfn(); err != nil {
 		logger.Fatal(err)
@@ -241,9 +244,13 @@ func (b Build) Cfn() {
 }
 
 func (b Build) cfn() error {
+	if os.Getenv("PLAN") == "true" {
+		return generateDashboards(true, false)
+	}
+
 	if err := embedAPISpec(); err != nil {
 		return err
 	}
 
-	return generateDashboards()
+	return generateDashboards(false, os.Getenv("DASHBOARDS_SUMMARY") == "true")
 }
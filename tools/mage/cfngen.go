@@ -20,18 +20,35 @@ package mage
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pmezard/go-difflib/difflib"
 	"gopkg.in/yaml.v2"
 
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
 	"github.com/panther-labs/panther/tools/dashboards"
 )
 
+var dashboardsTemplatePath = filepath.Join("deployments", "dashboards.yml")
+
 // Generate CloudWatch dashboards as CloudFormation
-func generateDashboards() error {
-	dashboardResources := dashboards.Dashboards()
+//
+// In plan mode, the unified diff against the existing template is printed instead of writing it.
+// Otherwise, the dashboards added, removed or modified since the existing template are logged,
+// and listed in a comment at the top of the template if summaryComment is set.
+func generateDashboards(plan, summaryComment bool) error {
+	dashboardResources, err := dashboards.DashboardsWithCustomWidgets(dashboards.CustomWidgetsDir)
+	if err != nil {
+		return fmt.Errorf("failed to add custom dashboard widgets: %v", err)
+	}
 	logger.Debugf("deploy: cfngen: loaded %d dashboards", len(dashboardResources))
+	if err := validateDashboards(dashboardResources); err != nil {
+		return err
+	}
 
 	template := map[string]interface{}{
 		"AWSTemplateFormatVersion": "2010-09-09",
@@ -39,10 +56,12 @@ func generateDashboards() error {
 	}
 
 	resources := make(map[string]interface{}, len(dashboardResources))
+	generated := make(map[string]*cloudwatchcf.Dashboard, len(dashboardResources))
 	for _, dashboard := range dashboardResources {
 		logicalID := strings.TrimPrefix(dashboard.Properties.DashboardName.Sub, "Panther")
 		logicalID = strings.TrimSuffix(logicalID, "-${AWS::Region}")
 		resources[logicalID] = dashboard
+		generated[logicalID] = dashboard
 	}
 
 	template["Resources"] = resources
@@ -51,9 +70,18 @@ func generateDashboards() error {
 		return fmt.Errorf("dashboard yaml marshal failed: %v", err)
 	}
 
-	body = append([]byte("# NOTE: template auto-generated by 'mage build:cfn', DO NOT EDIT\n"), body...)
+	header := "# NOTE: template auto-generated by 'mage build:cfn', DO NOT EDIT\n"
+	if plan {
+		return planDashboards(append([]byte(header), body...))
+	}
 
-	target := filepath.Join("deployments", "dashboards.yml")
+	summary := logDashboardChanges(generated)
+	if summaryComment && len(summary) > 0 {
+		header += formatDashboardSummaryComment(summary)
+	}
+	body = append([]byte(header), body...)
+
+	target := dashboardsTemplatePath
 	if err := writeFile(target, body); err != nil {
 		return err
 	}
@@ -61,3 +89,97 @@ func generateDashboards() error {
 	fmtLicense(target)
 	return prettier(target)
 }
+
+// Log the changes from the existing template, returning the summary lines
+//
+// The summary is best effort, the template is generated even if the existing one can't be read.
+func logDashboardChanges(generated map[string]*cloudwatchcf.Dashboard) []string {
+	existing, err := ioutil.ReadFile(dashboardsTemplatePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("build:cfn: failed to read %s, not summarizing changes: %v", dashboardsTemplatePath, err)
+		}
+		return nil
+	}
+
+	summary, err := summarizeDashboardChanges(existing, generated)
+	if err != nil {
+		logger.Warnf("build:cfn: not summarizing dashboard changes: %v", err)
+		return nil
+	}
+	for _, line := range summary {
+		logger.Infof("build:cfn: %s", line)
+	}
+	return summary
+}
+
+// Make sure the body of each dashboard is valid JSON, CloudFormation would only reject it at deploy time
+func validateDashboards(dashboardResources []*cloudwatchcf.Dashboard) error {
+	for _, dashboard := range dashboardResources {
+		var body interface{}
+		if err := jsoniter.UnmarshalFromString(dashboard.Properties.DashboardBody.Sub, &body); err != nil {
+			name := strings.TrimSuffix(dashboard.Properties.DashboardName.Sub, "-${AWS::Region}")
+			return fmt.Errorf("dashboard %s has an invalid JSON body: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Print the changes the generated template would make to the existing dashboards.yml, without writing it
+func planDashboards(body []byte) error {
+	// The generated template has to be formatted the same way as the committed one before comparing them.
+	// Prettier skips the (ignored) out/ directory, so the scratch file lives next to the real template.
+	scratch := filepath.Join(filepath.Dir(dashboardsTemplatePath), ".dashboards.plan.yml")
+	defer os.Remove(scratch)
+	if err := writeFile(scratch, body); err != nil {
+		return err
+	}
+	fmtLicense(scratch)
+	if err := prettier(scratch); err != nil {
+		return err
+	}
+
+	existing, err := ioutil.ReadFile(dashboardsTemplatePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", dashboardsTemplatePath, err)
+	}
+
+	diff, err := diffDashboards(dashboardsTemplatePath, existing, readFile(scratch))
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		logger.Infof("build:cfn: %s is up to date", dashboardsTemplatePath)
+		return nil
+	}
+
+	fmt.Print(diff)
+	logger.Infof("build:cfn: plan mode, %s was not modified", dashboardsTemplatePath)
+	return nil
+}
+
+// Returns the unified diff from the existing to the generated template, or "" if they are the same
+func diffDashboards(path string, existing, generated []byte) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(existing),
+		B:        splitLines(generated),
+		FromFile: "a/" + filepath.ToSlash(path),
+		ToFile:   "b/" + filepath.ToSlash(path),
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %v", path, err)
+	}
+	return diff, nil
+}
+
+// Split text into lines, keeping the line endings.
+//
+// Unlike difflib.SplitLines, this does not add an empty line after the final newline.
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,183 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
+)
+
+// The parts of the dashboards template which are compared to summarize the changes
+type dashboardsTemplate struct {
+	Resources map[string]*cloudwatchcf.Dashboard `yaml:"Resources"`
+}
+
+// Summarize the changes from the existing dashboards template to the generated dashboards, keyed by logical ID.
+//
+// Each line describes an added, removed or modified dashboard, e.g.
+// `modified dashboard Overview: added widgets "Errors"; changed widgets "Invocations"`.
+// Widgets are identified by their title. There are no lines if nothing changed.
+func summarizeDashboardChanges(existing []byte, generated map[string]*cloudwatchcf.Dashboard) ([]string, error) {
+	var previous dashboardsTemplate
+	if err := yaml.Unmarshal(existing, &previous); err != nil {
+		return nil, fmt.Errorf("failed to parse the existing dashboards: %v", err)
+	}
+
+	logicalIDs := make([]string, 0, len(generated)+len(previous.Resources))
+	for logicalID := range generated {
+		logicalIDs = append(logicalIDs, logicalID)
+	}
+	for logicalID := range previous.Resources {
+		if _, ok := generated[logicalID]; !ok {
+			logicalIDs = append(logicalIDs, logicalID)
+		}
+	}
+	sort.Strings(logicalIDs)
+
+	var summary []string
+	for _, logicalID := range logicalIDs {
+		before, after := previous.Resources[logicalID], generated[logicalID]
+		switch {
+		case before == nil:
+			widgets, err := dashboardWidgets(logicalID, after)
+			if err != nil {
+				return nil, err
+			}
+			summary = append(summary, fmt.Sprintf("added dashboard %s (%s)", logicalID, pluralize(len(widgets), "widget")))
+		case after == nil:
+			summary = append(summary, "removed dashboard "+logicalID)
+		default:
+			changes, err := diffDashboardWidgets(logicalID, before, after)
+			if err != nil {
+				return nil, err
+			}
+			if changes != "" {
+				summary = append(summary, fmt.Sprintf("modified dashboard %s: %s", logicalID, changes))
+			}
+		}
+	}
+	return summary, nil
+}
+
+// Describe the widgets added, removed and changed in a dashboard, or "" if the dashboard is unchanged
+func diffDashboardWidgets(logicalID string, before, after *cloudwatchcf.Dashboard) (string, error) {
+	beforeWidgets, err := dashboardWidgets(logicalID, before)
+	if err != nil {
+		return "", err
+	}
+	afterWidgets, err := dashboardWidgets(logicalID, after)
+	if err != nil {
+		return "", err
+	}
+
+	var added, removed, changed []string
+	for title, widget := range afterWidgets {
+		previous, ok := beforeWidgets[title]
+		switch {
+		case !ok:
+			added = append(added, title)
+		case previous != widget:
+			changed = append(changed, title)
+		}
+	}
+	for title := range beforeWidgets {
+		if _, ok := afterWidgets[title]; !ok {
+			removed = append(removed, title)
+		}
+	}
+
+	var changes []string
+	for _, group := range []struct {
+		verb   string
+		titles []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(group.titles) > 0 {
+			changes = append(changes, group.verb+" widgets "+quoteAll(group.titles))
+		}
+	}
+	if len(changes) == 0 && !jsonEqual(before.Properties.DashboardBody.Sub, after.Properties.DashboardBody.Sub) {
+		return "changed settings", nil
+	}
+	return strings.Join(changes, "; "), nil
+}
+
+// Returns the widgets of a dashboard by title, with their canonical JSON (sorted keys, no whitespace)
+func dashboardWidgets(logicalID string, dashboard *cloudwatchcf.Dashboard) (map[string]string, error) {
+	var body struct {
+		Widgets []map[string]interface{} `json:"widgets"`
+	}
+	if err := jsoniter.UnmarshalFromString(dashboard.Properties.DashboardBody.Sub, &body); err != nil {
+		return nil, fmt.Errorf("dashboard %s has an invalid JSON body: %v", logicalID, err)
+	}
+
+	widgets := make(map[string]string, len(body.Widgets))
+	for i, widget := range body.Widgets {
+		title := fmt.Sprintf("#%d", i+1) // e.g. text widgets have no title
+		if properties, ok := widget["properties"].(map[string]interface{}); ok {
+			if t, ok := properties["title"].(string); ok && t != "" {
+				title = t
+			}
+		}
+		if _, duplicate := widgets[title]; duplicate {
+			title = fmt.Sprintf("%s #%d", title, i+1)
+		}
+		canonical, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalToString(widget)
+		if err != nil {
+			return nil, fmt.Errorf("dashboard %s: failed to marshal widget %s: %v", logicalID, title, err)
+		}
+		widgets[title] = canonical
+	}
+	return widgets, nil
+}
+
+// Compare two JSON documents, ignoring formatting and key order
+func jsonEqual(a, b string) bool {
+	var aValue, bValue interface{}
+	if jsoniter.UnmarshalFromString(a, &aValue) != nil || jsoniter.UnmarshalFromString(b, &bValue) != nil {
+		return a == b
+	}
+	aCanonical, _ := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalToString(aValue)
+	bCanonical, _ := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalToString(bValue)
+	return aCanonical == bCanonical
+}
+
+func quoteAll(values []string) string {
+	sort.Strings(values)
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Format the summary as a YAML comment block for the top of the template
+func formatDashboardSummaryComment(summary []string) string {
+	var comment strings.Builder
+	comment.WriteString("# Changes since the previous version:\n")
+	for _, line := range summary {
+		comment.WriteString("#   - " + line + "\n")
+	}
+	return comment.String()
+}
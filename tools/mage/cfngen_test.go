@@ -0,0 +1,131 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
+	"github.com/panther-labs/panther/tools/dashboards"
+)
+
+func TestDiffDashboards(t *testing.T) {
+	existing := []byte("Resources:\n  Overview:\n    Type: AWS::CloudWatch::Dashboard\n")
+	generated := []byte("Resources:\n  Overview:\n    Type: AWS::CloudWatch::Dashboard\n  Alerts:\n    Type: AWS::CloudWatch::Dashboard\n")
+
+	diff, err := diffDashboards("deployments/dashboards.yml", existing, generated)
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/deployments/dashboards.yml
++++ b/deployments/dashboards.yml
+@@ -1,3 +1,5 @@
+ Resources:
+   Overview:
+     Type: AWS::CloudWatch::Dashboard
++  Alerts:
++    Type: AWS::CloudWatch::Dashboard
+`, diff)
+}
+
+func TestDiffDashboardsUnchanged(t *testing.T) {
+	body := []byte("Resources:\n  Overview:\n    Type: AWS::CloudWatch::Dashboard\n")
+
+	diff, err := diffDashboards("deployments/dashboards.yml", body, body)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestDiffDashboardsMissing(t *testing.T) {
+	diff, err := diffDashboards("deployments/dashboards.yml", nil, []byte("Resources: {}\n"))
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+Resources: {}\n")
+}
+
+func TestValidateDashboards(t *testing.T) {
+	require.NoError(t, validateDashboards(dashboards.Dashboards()))
+
+	malformed := []*cloudwatchcf.Dashboard{
+		cloudwatchcf.NewDashboard("PantherOverview", `{"widgets": []}`),
+		cloudwatchcf.NewDashboard("PantherBroken", `{"widgets": [{"type": "metric"}`),
+	}
+	err := validateDashboards(malformed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dashboard PantherBroken has an invalid JSON body")
+}
+
+func TestSummarizeDashboardChanges(t *testing.T) {
+	existing, err := yaml.Marshal(map[string]interface{}{
+		"Resources": map[string]*cloudwatchcf.Dashboard{
+			"Overview": cloudwatchcf.NewDashboard("PantherOverview", `{
+				"start": "-PT1H",
+				"widgets": [
+					{"type": "metric", "properties": {"title": "Errors", "period": 60}},
+					{"type": "metric", "properties": {"title": "Invocations"}},
+					{"type": "text", "properties": {"markdown": "# Overview"}}
+				]
+			}`),
+			"Remediation": cloudwatchcf.NewDashboard("PantherRemediation", `{"widgets": []}`),
+			"Alerts":      cloudwatchcf.NewDashboard("PantherAlerts", `{"widgets": [{"properties": {"title": "Alerts"}}]}`),
+			"Settings":    cloudwatchcf.NewDashboard("PantherSettings", `{"start": "-PT1H", "widgets": []}`),
+		},
+	})
+	require.NoError(t, err)
+
+	generated := map[string]*cloudwatchcf.Dashboard{
+		// reformatted, with keys in another order, the dashboard is unchanged
+		"Alerts": cloudwatchcf.NewDashboard("PantherAlerts", `{"widgets":[{"properties":{"title":"Alerts"}}]}`),
+		"Overview": cloudwatchcf.NewDashboard("PantherOverview", `{
+			"start": "-PT1H",
+			"widgets": [
+				{"type": "metric", "properties": {"title": "Errors", "period": 300}},
+				{"type": "metric", "properties": {"title": "Throttles"}},
+				{"type": "text", "properties": {"markdown": "# Overview"}}
+			]
+		}`),
+		"LogAnalysis": cloudwatchcf.NewDashboard("PantherLogAnalysis", `{"widgets": [{}, {}]}`),
+		"Settings":    cloudwatchcf.NewDashboard("PantherSettings", `{"start": "-PT3H", "widgets": []}`),
+	}
+
+	summary, err := summarizeDashboardChanges(existing, generated)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"added dashboard LogAnalysis (2 widgets)",
+		`modified dashboard Overview: added widgets "Throttles"; removed widgets "Invocations"; changed widgets "Errors"`,
+		"removed dashboard Remediation",
+		"modified dashboard Settings: changed settings",
+	}, summary)
+
+	assert.Equal(t, "# Changes since the previous version:\n#   - removed dashboard Remediation\n",
+		formatDashboardSummaryComment([]string{"removed dashboard Remediation"}))
+}
+
+func TestSummarizeDashboardChangesUnchanged(t *testing.T) {
+	generated := map[string]*cloudwatchcf.Dashboard{
+		"Overview": cloudwatchcf.NewDashboard("PantherOverview", `{"widgets": [{"properties": {"title": "Errors"}}]}`),
+	}
+	existing, err := yaml.Marshal(map[string]interface{}{"Resources": generated})
+	require.NoError(t, err)
+
+	summary, err := summarizeDashboardChanges(existing, generated)
+	require.NoError(t, err)
+	assert.Empty(t, summary)
+}
@@ -469,7 +469,7 @@ func deployCoreStack(settings *config.PantherConfig, outputs map[string]string)
 }
 
 func deployDashboardStack(bucket string) error {
-	if err := generateDashboards(); err != nil {
+	if err := generateDashboards(false, false); err != nil {
 		return err
 	}
 
@@ -20,32 +20,61 @@ package mage
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html"
+	"io"
+	"io/ioutil"
 	"math"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/magefile/mage/mg"
 
 	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
 	"github.com/panther-labs/panther/tools/cfndoc"
 	"github.com/panther-labs/panther/tools/config"
+	"github.com/panther-labs/panther/tools/gluetype"
 )
 
-// Preview auto-generated documentation in out/doc
-func Doc() {
+// Doc contains targets for generating documentation and schemas from the source code.
+type Doc mg.Namespace
+
+// Generate Preview auto-generated documentation in out/docs (set STRICT=true to fail on warnings and malformed HTML, DOCS_OUT to change the directory, PRUNE=true to delete orphaned log category files, REQUIRED_ONLY=true to list only required fields, FLATTEN=true to list nested struct fields as dotted columns, PER_TYPE=true to write a file for each log type, -v to log a timing summary)
+func (Doc) Generate() {
 	if err := doc(); err != nil {
 		logger.Fatal(err)
 	}
-	logger.Info("doc: generated runbooks and log types in out/docs")
+	logger.Infof("doc: generated runbooks and log types in %s", docsOutDir)
+}
+
+// Root of the generated documentation tree, which mirrors the layout of the docs/ directory
+var docsOutDir = getDocsOutDir()
+
+// The docs are generated in out/docs unless the DOCS_OUT environment variable is set
+func getDocsOutDir() string {
+	if dir := os.Getenv("DOCS_OUT"); dir != "" {
+		return dir
+	}
+	return filepath.Join("out", "docs")
 }
 
+// In verbose mode (mage -v), a summary of the time spent in each stage is logged at the end.
 func doc() error {
+	docTracer = &docTrace{}
+	defer func() {
+		logger.Debug(docTracer.summary())
+	}()
+
 	if err := opDocs(); err != nil {
 		return err
 	}
-	return logDocs()
+	return logDocs(os.Getenv("STRICT") == "true", os.Getenv("PRUNE") == "true",
+		os.Getenv("REQUIRED_ONLY") == "true", os.Getenv("FLATTEN") == "true", os.Getenv("PER_TYPE") == "true")
 }
 
 const (
@@ -84,18 +113,30 @@ func cfnFiles() []string {
 // generate operational documentation from deployment CloudFormation
 func opDocs() error {
 	logger.Debug("doc: generating operational documentation from cloudformation")
+	defer docTracer.stage("opDocs")()
 	docs, err := cfndoc.ReadCfn(cfnFiles()...)
 	if err != nil {
 		return fmt.Errorf("failed to generate operational documentation: %v", err)
 	}
 
+	ambiguous := cfndoc.AmbiguousResources(docs)
+	resources := make([]string, 0, len(ambiguous))
+	for resource := range ambiguous {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		logger.Warnf("doc: runbook resource %s is defined in more than one file: %s",
+			resource, strings.Join(ambiguous[resource], ", "))
+	}
+
 	var docsBuffer bytes.Buffer
 	docsBuffer.WriteString(inventoryDocHeader)
 	for _, doc := range docs {
-		docsBuffer.WriteString(fmt.Sprintf("## %s\n%s\n\n", doc.Resource, doc.Documentation))
+		docsBuffer.WriteString(fmt.Sprintf("## %s\n%s\n\n(defined in %s)\n\n", doc.Resource, doc.Documentation, doc.Source))
 	}
 
-	return writeFile(filepath.Join("out", "docs", "gitbook", "operations", "runbooks.md"), docsBuffer.Bytes())
+	return writeFile(filepath.Join(docsOutDir, "gitbook", "operations", "runbooks.md"), docsBuffer.Bytes())
 }
 
 const (
@@ -104,45 +145,275 @@ const (
 `
 )
 
+// docCategory overrides the default grouping of log types, which is by the first segment of the log type name
+type docCategory struct {
+	// Name of the category in the docs, e.g. "Google"
+	Name string
+	// First segments of the log types in this category, e.g. "GCP" and "GSuite"
+	Prefixes []string
+}
+
+// docCategories are listed first in the index, in this order.
+// Categories without an override are listed after them, sorted by name.
+var docCategories = []docCategory{
+	{Name: "AWS", Prefixes: []string{"AWS"}},
+	{Name: "Google", Prefixes: []string{"GCP", "GSuite"}},
+}
+
+// Returns the category name for the first segment of a log type name
+func docCategoryName(prefix string) string {
+	for _, override := range docCategories {
+		for _, p := range override.Prefixes {
+			if p == prefix {
+				return override.Name
+			}
+		}
+	}
+	return prefix
+}
+
 type supportedLogs struct {
 	Categories map[string]*logCategory
 	TotalTypes int
 }
 
+// Returns the categories in the order they are listed in the index
+func (logs *supportedLogs) orderedCategories() []*logCategory {
+	result := make([]*logCategory, 0, len(logs.Categories))
+	listed := make(map[string]bool)
+	for _, override := range docCategories {
+		if category, ok := logs.Categories[override.Name]; ok && !listed[override.Name] {
+			result = append(result, category)
+			listed[override.Name] = true
+		}
+	}
+
+	var others []*logCategory
+	for name, category := range logs.Categories {
+		if !listed[name] {
+			others = append(others, category)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	return append(result, others...)
+}
+
+// The "supported-logs" documentation directory
+func supportedLogsDir() string {
+	return filepath.Join(docsOutDir, "gitbook", "log-analysis", "log-processing", "supported-logs")
+}
+
 // Generate entire "supported-logs" documentation directory
-func (logs *supportedLogs) generateDocumentation() error {
-	outDir := filepath.Join("out", "docs", "gitbook", "log-analysis", "log-processing", "supported-logs")
+//
+// In strict mode, any warning about a log type fails the generation.
+// All categories are generated before failing, so every error is reported in a single run.
+// Category files left over from categories which no longer have any log types are reported,
+// and deleted if prune is set. If requiredOnly is set, only the required columns of each log type are listed.
+// If flatten is set, the fields of struct columns are listed as dotted columns.
+// If perType is set, each log type is documented in its own file and the category files link to them.
+func (logs *supportedLogs) generateDocumentation(strict, prune, requiredOnly, flatten, perType bool) error {
+	outDir := supportedLogsDir()
+
+	// Write one file for each category, or one for each log type.
+	var errs []string
+	for _, category := range logs.orderedCategories() {
+		var err error
+		if perType {
+			err = category.generateTypeDocFiles(outDir, strict, requiredOnly, flatten)
+		} else {
+			err = category.generateDocFile(outDir, strict, requiredOnly, flatten)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", category.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to generate documentation for %d log categories:\n%s",
+			len(errs), strings.Join(errs, "\n"))
+	}
+
+	if err := logs.generateIndexFile(outDir); err != nil {
+		return err
+	}
 
-	// Write one file for each category.
-	for _, category := range logs.Categories {
-		if err := category.generateDocFile(outDir); err != nil {
+	orphans, err := logs.findOrphanedDocFiles(outDir)
+	if err != nil {
+		return err
+	}
+	if perType {
+		typeOrphans, err := logs.findOrphanedTypeDocFiles(outDir)
+		if err != nil {
 			return err
 		}
+		orphans = append(orphans, typeOrphans...)
+	}
+	for _, path := range orphans {
+		if !prune {
+			logger.Warnf("%s does not match any log category, set PRUNE=true to delete it", path)
+			continue
+		}
+		logger.Infof("deleting orphaned log category documentation: %s", path)
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", path, err)
+		}
 	}
-
 	return nil
 }
 
+// Returns the category files in the output directory which don't belong to any category
+func (logs *supportedLogs) findOrphanedDocFiles(outDir string) ([]string, error) {
+	files, err := ioutil.ReadDir(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", outDir, err)
+	}
+
+	var orphans []string
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != ".md" || name == "README.md" || name == schemaChangelogFile {
+			continue
+		}
+		if _, ok := logs.Categories[strings.TrimSuffix(name, ".md")]; !ok {
+			orphans = append(orphans, filepath.Join(outDir, name))
+		}
+	}
+	return orphans, nil
+}
+
+// Returns the log type files in the category directories which don't belong to any log type of the category
+func (logs *supportedLogs) findOrphanedTypeDocFiles(outDir string) ([]string, error) {
+	var orphans []string
+	for _, category := range logs.orderedCategories() {
+		typeDir := filepath.Join(outDir, category.Name)
+		files, err := ioutil.ReadDir(typeDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %v", typeDir, err)
+		}
+
+		names := make(map[string]bool, len(category.LogTypes))
+		for _, logType := range category.LogTypes {
+			names[category.typeDocName(logType)+".md"] = true
+		}
+		for _, file := range files {
+			if !file.IsDir() && filepath.Ext(file.Name()) == ".md" && !names[file.Name()] {
+				orphans = append(orphans, filepath.Join(typeDir, file.Name()))
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// Generate the index of all categories, "README.md"
+func (logs *supportedLogs) generateIndexFile(outDir string) error {
+	var docsBuffer bytes.Buffer
+	docsBuffer.WriteString(parserReadmeHeader)
+	docsBuffer.WriteString(fmt.Sprintf("# Supported Logs\nPanther supports %d log types.\n\n", logs.TotalTypes))
+	for _, category := range logs.orderedCategories() {
+		docsBuffer.WriteString(fmt.Sprintf("* [%s](%s.md)\n", category.Name, category.Name))
+	}
+
+	path := filepath.Join(outDir, "README.md")
+	logger.Debugf("writing log category index: %s", path)
+	return writeFile(path, docsBuffer.Bytes())
+}
+
 type logCategory struct {
 	Name     string
 	LogTypes []string
 }
 
 // Generate a single documentation file for a log category, e.g. "AWS.md"
-func (category *logCategory) generateDocFile(outDir string) error {
+//
+// If requiredOnly is set, this is a quick reference which lists only the required columns of each log type.
+// If flatten is set, each leaf field of a struct column gets its own row, e.g. `user.id` and `user.name`.
+func (category *logCategory) generateDocFile(outDir string, strict, requiredOnly, flatten bool) error {
 	sort.Strings(category.LogTypes)
 
+	path := filepath.Join(outDir, category.Name+".md")
+	logger.Debugf("writing log category documentation: %s", path)
+	defer docTracer.stage("generateDocFile " + category.Name)()
+	return streamFile(path, func(w io.Writer) error {
+		return category.writeDoc(w, strict, requiredOnly, flatten)
+	})
+}
+
+// Generate a documentation file for each log type of a category, e.g. "AWS/CloudTrail.md",
+// and a category file linking to them, e.g. "AWS.md"
+func (category *logCategory) generateTypeDocFiles(outDir string, strict, requiredOnly, flatten bool) error {
+	sort.Strings(category.LogTypes)
+
+	defer docTracer.stage("generateTypeDocFiles " + category.Name)()
+	typeDir := filepath.Join(outDir, category.Name)
+	var errs []string
+	for _, logType := range category.LogTypes {
+		single := &logCategory{Name: category.typeDocName(logType), LogTypes: []string{logType}}
+		if err := single.generateDocFile(typeDir, strict, requiredOnly, flatten); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	path := filepath.Join(outDir, category.Name+".md")
+	logger.Debugf("writing log category index: %s", path)
+	return writeFile(path, category.formatTypeIndex())
+}
+
+// The name of the file documenting a log type, without the category name, e.g. "CloudTrail" for AWS.CloudTrail
+func (category *logCategory) typeDocName(logType string) string {
+	return strings.TrimPrefix(logType, category.Name+".")
+}
+
+// The category file linking to the files of its log types
+func (category *logCategory) formatTypeIndex() []byte {
 	var docsBuffer bytes.Buffer
 	docsBuffer.WriteString(parserReadmeHeader)
-	docsBuffer.WriteString(fmt.Sprintf("# %s\n%sRequired fields are in <b>bold</b>.%s\n",
+	docsBuffer.WriteString(fmt.Sprintf("# %s\n%s.\n\n", category.Name, pluralize(len(category.LogTypes), "log type")))
+	for _, logType := range category.LogTypes {
+		docsBuffer.WriteString(fmt.Sprintf("* [%s](%s/%s.md)\n", logType, category.Name, category.typeDocName(logType)))
+	}
+	return docsBuffer.Bytes()
+}
+
+// Write the documentation of a log category as it is generated.
+//
+// Only the documentation of one log type at a time is buffered, so memory use doesn't grow with the category size.
+func (category *logCategory) writeDoc(docs io.Writer, strict, requiredOnly, flatten bool) error {
+	hint := "Required fields are in <b>bold</b>."
+	if requiredOnly {
+		hint = "Only the required fields of each log type are listed."
+	}
+	if flatten {
+		hint += " Nested fields are listed with their full path, e.g. <code>user.id</code>."
+	}
+	var docsBuffer bytes.Buffer
+	docsBuffer.WriteString(parserReadmeHeader)
+	docsBuffer.WriteString(fmt.Sprintf("# %s\n%s%s%s\n",
 		category.Name,
 		`{% hint style="info" %}`,
+		hint,
 		`{% endhint %}`))
 
 	// use html table to get needed control
+	var errs []string
+	documentedTypes, totalColumns := 0, 0
 	for _, logType := range category.LogTypes {
 		entry := registry.Lookup(logType)
 		table := entry.GlueTableMeta()
+		inferStart := time.Now()
+		columns, err := registry.InferColumns(logType, table.EventStruct()) // get the Glue schema
+		docTracer.inferred(logType, len(columns), time.Since(inferStart))
+		if err != nil {
+			if err = docWarning(strict, err); err != nil {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+
 		entryDesc := entry.Describe()
 		desc := entryDesc.Description
 		if entryDesc.ReferenceURL != "-" {
@@ -151,34 +422,145 @@ func (category *logCategory) generateDocFile(outDir string) error {
 
 		description := html.EscapeString(desc)
 
+		// the documentation of a log type which fails is dropped, so it doesn't end up above the next log type
+		typeStart := docsBuffer.Len()
 		docsBuffer.WriteString(fmt.Sprintf("## %s\n%s\n", logType, description))
+		if entryDesc.Stability != awsglue.StabilityStable {
+			docsBuffer.WriteString(formatStabilityBadge(entryDesc.Stability) + "\n\n")
+		}
+		if len(entryDesc.Tags) > 0 {
+			docsBuffer.WriteString(formatTagBadges(entryDesc.Tags) + "\n\n")
+		}
+		if requiredOnly {
+			required := requiredColumns(columns)
+			docsBuffer.WriteString(formatSubsetLabel(len(required), len(columns)))
+			columns = required
+		}
+		documentedColumns := len(columns)
+		if flatten {
+			if columns, err = flattenColumns(logType, columns); err != nil {
+				errs = append(errs, err.Error())
+				docsBuffer.Truncate(typeStart)
+				continue
+			}
+		}
 
 		// add schema as html table since markdown won't let you embed tables
+		tableStart := docsBuffer.Len()
 		docsBuffer.WriteString(`<table>` + "\n")
 		docsBuffer.WriteString("<tr><th align=center>Column</th><th align=center>Type</th><th align=center>Description</th></tr>\n") // nolint
 
-		columns, _ := awsglue.InferJSONColumns(table.EventStruct(), awsglue.GlueMappings...) // get the Glue schema
+		rendered := true
 		for _, column := range columns {
-			colName := column.Name
-			if column.Required {
-				colName = "<b>" + colName + "</b>" // required elements are bold
+			colType, err := formatType(logType, column)
+			if err != nil {
+				errs = append(errs, err.Error())
+				rendered = false
+				continue
 			}
 			docsBuffer.WriteString(fmt.Sprintf("<tr><td valign=top>%s</td><td>%s</td><td valign=top>%s</td></tr>\n",
-				formatColumnName(colName),
-				formatType(logType, column),
+				formatColumnCell(column, table, entryDesc.Stability),
+				colType,
 				html.EscapeString(column.Comment)))
 		}
 
 		docsBuffer.WriteString("</table>\n\n")
+		// the table is assembled by hand, make sure the pretty printer didn't break the page
+		if strict {
+			if err := validateHTML(docsBuffer.Bytes()[tableStart:]); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: malformed HTML table: %v", logType, err))
+			}
+		}
+		if hasRawJSONColumns(columns) {
+			docsBuffer.WriteString(rawJSONNote)
+		}
+		if rendered {
+			documentedTypes++
+			totalColumns += documentedColumns
+		}
+
+		if _, err := docsBuffer.WriteTo(docs); err != nil {
+			return err
+		}
 	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	docsBuffer.WriteString(formatCategoryFooter(documentedTypes, totalColumns))
+	_, err := docsBuffer.WriteTo(docs)
+	return err
+}
 
-	path := filepath.Join(outDir, category.Name+".md")
-	logger.Debugf("writing log category documentation: %s", path)
-	return writeFile(path, docsBuffer.Bytes())
+// Returns only the required columns, in the same order
+func requiredColumns(columns []awsglue.Column) []awsglue.Column {
+	var result []awsglue.Column
+	for _, column := range columns {
+		if column.Required {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// Replaces struct columns with a column for each of their leaf fields, named by their dotted path.
+// Arrays and maps are not flattened, even when they hold structs, since their fields have no single path.
+// The description, sensitivity and stability of a struct column apply to all its fields.
+func flattenColumns(logType string, columns []awsglue.Column) ([]awsglue.Column, error) {
+	var result []awsglue.Column
+	for _, column := range columns {
+		parsed, err := gluetype.Parse(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%v for %s in %s", err, column.Name, logType)
+		}
+		result = appendFlattenedColumns(result, column, parsed)
+	}
+	return result, nil
 }
 
-func logDocs() error {
+func appendFlattenedColumns(columns []awsglue.Column, column awsglue.Column, t *gluetype.Type) []awsglue.Column {
+	if t.Kind != gluetype.Struct {
+		column.Type = t.String()
+		return append(columns, column)
+	}
+	for _, field := range t.Fields {
+		nested := column
+		nested.Name = column.Name + "." + field.Name
+		nested.Required = false // the fields of a required struct can still be omitted
+		columns = appendFlattenedColumns(columns, nested, field.Type)
+	}
+	return columns
+}
+
+// Labels the table of a log type as a subset of its columns
+func formatSubsetLabel(listed, total int) string {
+	return fmt.Sprintf("\n_Required fields only: %d of %s._\n\n", listed, pluralize(total, "column"))
+}
+
+// Summarize the size of a category at the end of its documentation file
+func formatCategoryFooter(logTypes, columns int) string {
+	return fmt.Sprintf("<!-- Generated summary, DO NOT EDIT! -->\n---\n_%s, %s in total._\n",
+		pluralize(logTypes, "log type"), pluralize(columns, "column"))
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// In strict mode, warnings are returned as errors. Otherwise they are logged and nil is returned.
+func docWarning(strict bool, err error) error {
+	if strict {
+		return err
+	}
+	logger.Warn(err)
+	return nil
+}
+
+func logDocs(strict, prune, requiredOnly, flatten, perType bool) error {
 	logger.Debug("doc: generating documentation on supported logs")
+	defer docTracer.stage("logDocs")()
 
 	// allow large comment descriptions in the docs (by default they are clipped)
 	awsglue.MaxCommentLength = math.MaxInt32
@@ -186,26 +568,43 @@ func logDocs() error {
 		awsglue.MaxCommentLength = awsglue.DefaultMaxCommentLength
 	}()
 
-	logs, err := findSupportedLogs()
+	logs, err := findSupportedLogs(strict)
 	if err != nil {
 		return err
 	}
 
-	return logs.generateDocumentation()
+	return logs.generateDocumentation(strict, prune, requiredOnly, flatten, perType)
 }
 
 // Group log registry by category
-func findSupportedLogs() (*supportedLogs, error) {
-	result := supportedLogs{Categories: make(map[string]*logCategory)}
-
+//
+// Log types with an unexpected name format are skipped, unless running in strict mode.
+func findSupportedLogs(strict bool) (*supportedLogs, error) {
+	defer docTracer.stage("findSupportedLogs")()
 	tables := registry.AvailableTables()
-	for _, table := range tables {
-		logType := table.LogType()
+	logTypes := make([]string, len(tables))
+	for i, table := range tables {
+		logTypes[i] = table.LogType()
+	}
+	return groupLogTypes(logTypes, strict)
+}
+
+// Group log types by category, failing if a log type is listed more than once
+func groupLogTypes(logTypes []string, strict bool) (*supportedLogs, error) {
+	if err := checkDuplicateLogTypes(logTypes); err != nil {
+		return nil, err
+	}
+
+	result := supportedLogs{Categories: make(map[string]*logCategory)}
+	for _, logType := range logTypes {
 		categoryType := strings.Split(logType, ".")
 		if len(categoryType) != 2 {
-			return nil, fmt.Errorf("unexpected logType format: %s", logType)
+			if err := docWarning(strict, fmt.Errorf("unexpected logType format: %s", logType)); err != nil {
+				return nil, err
+			}
+			continue
 		}
-		name := categoryType[0]
+		name := docCategoryName(categoryType[0])
 
 		category, exists := result.Categories[name]
 		if !exists {
@@ -219,106 +618,160 @@ func findSupportedLogs() (*supportedLogs, error) {
 	return &result, nil
 }
 
+// Reports log types registered more than once, or differing only by case.
+// The latter would overwrite each other's documentation on case-insensitive file systems.
+func checkDuplicateLogTypes(logTypes []string) error {
+	byName := make(map[string][]string)
+	for _, logType := range logTypes {
+		key := strings.ToLower(logType)
+		byName[key] = append(byName[key], logType)
+	}
+
+	var duplicates []string
+	for _, names := range byName {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		if names[0] == names[len(names)-1] {
+			duplicates = append(duplicates, fmt.Sprintf("%s is registered %d times", names[0], len(names)))
+		} else {
+			duplicates = append(duplicates, strings.Join(names, ", ")+" differ only by case")
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.Strings(duplicates)
+	return fmt.Errorf("duplicate log types:\n%s", strings.Join(duplicates, "\n"))
+}
+
 func formatColumnName(name string) string {
 	return "<code>" + name + "</code>"
 }
 
-func formatType(logType string, col awsglue.Column) string {
-	return "<code>" + prettyPrintType(logType, col.Name, col.Type, "") + "</code>"
+// Format the column cell of the table: the name followed by the event time, sensitive data and stability markers.
+// The stability of a column is only marked if it differs from the stability of its log type.
+func formatColumnCell(column awsglue.Column, table *awsglue.GlueTableMetadata, stability string) string {
+	colName := column.Name
+	if column.Required {
+		colName = "<b>" + colName + "</b>" // required elements are bold
+	}
+	colName = formatColumnName(colName)
+	if column.Name == table.EventTimeColumn() {
+		colName += "<br>" + formatEventTimeMarker(table)
+	}
+	if column.Sensitive {
+		colName += "<br>" + formatSensitiveMarker()
+	}
+	if column.RawJSON {
+		colName += "<br>" + formatRawJSONMarker()
+	}
+	if column.Stability != "" && column.Stability != stability {
+		colName += "<br>" + formatStabilityBadge(column.Stability)
+	}
+	return colName
 }
 
-const (
-	prettyPrintPrefix = "<br>"
-	prettyPrintIndent = "&nbsp;&nbsp;"
-)
-
-func prettyPrintType(logType, colName, colType, indent string) string {
-	complexTypes := []string{"array", "struct", "map"}
-	for _, ct := range complexTypes {
-		if strings.HasPrefix(colType, ct) {
-			return prettyPrintComplexType(logType, colName, ct, colType, indent)
-		}
+// Marks the event time column, which is the column to filter on to limit the partitions scanned by a query.
+// Athena computes the partitions of tables with partition projection instead of looking them up in Glue.
+func formatEventTimeMarker(table *awsglue.GlueTableMetadata) string {
+	partitions := table.PartitionKeys()
+	names := make([]string, len(partitions))
+	for i, partition := range partitions {
+		names[i] = partition.Name
+	}
+	if table.HasPartitionProjection() {
+		return fmt.Sprintf(`<i title="partitioned by %s, with partition projection">🕑 event time</i>`, strings.Join(names, ", "))
 	}
+	return fmt.Sprintf(`<i title="partitioned by %s">🕑 event time</i>`, strings.Join(names, ", "))
+}
 
-	// if NOT a complex type we just use the Glue type
-	return colType
+// Marks columns holding sensitive data (see awsglue.SensitiveTagName) so users know what to scrub
+func formatSensitiveMarker() string {
+	return `<i title="may contain sensitive data">🔒 sensitive</i>`
 }
 
-// complex hive types are ugly
-func prettyPrintComplexType(logType, colName, complexType, colType, indent string) (pretty string) {
-	switch complexType {
-	case "array":
-		return prettyPrintArrayType(logType, colName, colType, indent)
-	case "map":
-		return prettyPrintMapType(logType, colName, colType, indent)
-	case "struct":
-		return prettyPrintStructType(logType, colName, colType, indent)
-	default:
-		panic("unknown complex type: " + complexType + " for " + colName + " in " + logType)
+// Lists the use cases of a log type, e.g. "audit", so users can find the log types relevant to them
+func formatTagBadges(tags []string) string {
+	badges := make([]string, len(tags))
+	for i, tag := range tags {
+		badges[i] = `<i title="tag">🏷️ ` + html.EscapeString(tag) + `</i>`
 	}
+	return strings.Join(badges, " ")
 }
 
-func prettyPrintArrayType(logType, colName, colType, indent string) string {
-	fields := getTypeFields("array", colType)
-	if len(fields) != 1 {
-		panic("could not parse array type `" + colType + "` for " + colName + " in " + logType)
-	}
-	return "[" + prettyPrintType(logType, colName, fields[0], indent) + "]"
+// Marks columns holding unparsed JSON (see awsglue.IsRawJSON), explained by rawJSONNote below the table
+func formatRawJSONMarker() string {
+	return `<i title="unparsed JSON stored as a string">{} raw JSON</i>`
 }
 
-func prettyPrintMapType(logType, colName, colType, indent string) string {
-	fields := getTypeFields("map", colType)
-	if len(fields) != 2 {
-		panic("could not parse map type `" + colType + "` for " + colName + " in " + logType)
+// Explains the raw JSON columns of a log type, since their type doesn't tell how to query them
+const rawJSONNote = `{% hint style="info" %}` +
+	"Columns marked <i>raw JSON</i> hold the JSON of the event as is, without parsing it, because its fields vary from event to event. " +
+	"The JSON is stored as a string, use JSON functions such as <code>json_extract_scalar</code> to query its fields." +
+	`{% endhint %}` + "\n\n"
+
+func hasRawJSONColumns(columns []awsglue.Column) bool {
+	for _, column := range columns {
+		if column.RawJSON {
+			return true
+		}
 	}
-	keyType := fields[0]
-	valType := fields[1]
-	indent += prettyPrintIndent
-	return "{" + prettyPrintPrefix + indent + prettyPrintType(logType, colName, keyType, indent) + ":" +
-		prettyPrintType(logType, colName, valType, indent) + prettyPrintPrefix + "}"
+	return false
 }
 
-func prettyPrintStructType(logType, colName, colType, indent string) string {
-	fields := getTypeFields("struct", colType)
-	if len(fields) == 0 {
-		panic("could not parse struct type `" + colType + "` for " + colName + " in " + logType)
+// Marks log types and columns which are not stable, so users don't build on fields likely to change
+func formatStabilityBadge(stability string) string {
+	if stability == awsglue.StabilityExperimental {
+		return `<i title="may change without notice">🧪 experimental</i>`
 	}
-	indent += prettyPrintIndent
-	var fieldTypes []string
-	for _, field := range fields {
-		splitIndex := strings.Index(field, ":") // name:type (can't use Split() cuz type can have ':'
-		if splitIndex == -1 {
-			panic("could not parse struct field `" + field + "` of `" + colType + "` for " + colName + " in " + logType)
+	return fmt.Sprintf(`<i>%s</i>`, stability)
+}
+
+// Format the type of a column, converting type parsing failures into errors
+func formatType(logType string, col awsglue.Column) (formatted string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to format column type: %v", r)
 		}
-		name := `"` + field[0:splitIndex] + `"` // make it look like JSON by quoting
-		structFieldType := field[splitIndex+1:]
-		fieldTypes = append(fieldTypes, prettyPrintPrefix+indent+name+":"+
-			prettyPrintType(logType, colName, structFieldType, indent))
+	}()
+
+	return "<code>" + prettyPrintType(logType, col.Name, col.Type, "") + "</code>", nil
+}
+
+const (
+	prettyPrintPrefix = "<br>"
+	prettyPrintIndent = "&nbsp;&nbsp;"
+)
+
+func prettyPrintType(logType, colName, colType, indent string) string {
+	parsed, err := gluetype.Parse(colType)
+	if err != nil {
+		panic(err.Error() + " for " + colName + " in " + logType)
 	}
-	return "{" + strings.Join(fieldTypes, ",") + prettyPrintPrefix + "}"
+	return prettyPrintParsedType(parsed, indent)
 }
 
-func getTypeFields(complexType, colType string) (subFields []string) {
-	// strip off complexType + '<' in front and '>' on end
-	fields := colType[len(complexType)+1 : len(colType)-1]
-	// split fields into subFields around top level commas in type definition
-	startSubfieldIndex := 0
-	insideBracketCount := 0 // when non-zero we are inside a complex type
-	var index int
-	for index = range fields {
-		if fields[index] == ',' && insideBracketCount == 0 {
-			subFields = append(subFields, fields[startSubfieldIndex:index])
-			startSubfieldIndex = index + 1 // next
-		}
-		// track context
-		if fields[index] == '<' {
-			insideBracketCount++
-		} else if fields[index] == '>' {
-			insideBracketCount--
+// complex hive types are ugly
+func prettyPrintParsedType(t *gluetype.Type, indent string) string {
+	switch t.Kind {
+	case gluetype.Array:
+		return "[" + prettyPrintParsedType(t.Element, indent) + "]"
+	case gluetype.Map:
+		indent += prettyPrintIndent
+		return "{" + prettyPrintPrefix + indent + prettyPrintParsedType(t.Key, indent) + ":" +
+			prettyPrintParsedType(t.Value, indent) + prettyPrintPrefix + "}"
+	case gluetype.Struct:
+		indent += prettyPrintIndent
+		fieldTypes := make([]string, len(t.Fields))
+		for i, field := range t.Fields {
+			name := `"` + field.Name + `"` // make it look like JSON by quoting
+			fieldTypes[i] = prettyPrintPrefix + indent + name + ":" + prettyPrintParsedType(field.Type, indent)
 		}
+		return "{" + strings.Join(fieldTypes, ",") + prettyPrintPrefix + "}"
+	default:
+		// if NOT a complex type we just use the Glue type
+		return t.Name
 	}
-	if len(fields[startSubfieldIndex:]) > 0 { // the rest
-		subFields = append(subFields, fields[startSubfieldIndex:])
-	}
-	return subFields
 }
@@ -0,0 +1,96 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+// Descriptions which were left as placeholders when the log type was added (compared case-insensitively)
+var placeholderDescriptions = map[string]bool{
+	"":     true,
+	"-":    true,
+	"todo": true,
+	"tbd":  true,
+	"n/a":  true,
+}
+
+// Audit List the log types missing a description or a reference URL, by category (set STRICT=true to fail if there are any)
+func (Doc) Audit() {
+	strict := os.Getenv("STRICT") == "true"
+	logs, err := findSupportedLogs(strict)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	gaps := auditLogTypes(logs, func(logType string) logtypes.Desc { return registry.Lookup(logType).Describe() })
+	if len(gaps) == 0 {
+		logger.Infof("doc: all %d log types have a description and a reference URL", logs.TotalTypes)
+		return
+	}
+
+	report := fmt.Sprintf("doc: %d of %d log types are missing documentation:\n  %s",
+		len(gaps), logs.TotalTypes, strings.Join(gaps, "\n  "))
+	if strict {
+		logger.Fatal(report)
+	}
+	logger.Warn(report)
+}
+
+// List the log types with a placeholder description or without a reference URL,
+// sorted by category then log type, e.g. "AWS.S3ServerAccess: no reference URL"
+func auditLogTypes(logs *supportedLogs, describe func(logType string) logtypes.Desc) []string {
+	categories := make([]string, 0, len(logs.Categories))
+	for name := range logs.Categories {
+		categories = append(categories, name)
+	}
+	sort.Strings(categories)
+
+	var gaps []string
+	for _, name := range categories {
+		logTypes := append([]string(nil), logs.Categories[name].LogTypes...)
+		sort.Strings(logTypes)
+		for _, logType := range logTypes {
+			desc := describe(logType)
+			var missing []string
+			if isPlaceholderDescription(desc) {
+				missing = append(missing, "no description")
+			}
+			if desc.ReferenceURL == "" || desc.ReferenceURL == "-" {
+				missing = append(missing, "no reference URL")
+			}
+			if len(missing) > 0 {
+				gaps = append(gaps, logType+": "+strings.Join(missing, ", "))
+			}
+		}
+	}
+	return gaps
+}
+
+// A description is a placeholder if it is empty, a placeholder word or only repeats the log type name
+func isPlaceholderDescription(desc logtypes.Desc) bool {
+	description := strings.TrimSpace(desc.Description)
+	return placeholderDescriptions[strings.ToLower(description)] || strings.EqualFold(description, desc.Name)
+}
@@ -0,0 +1,113 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Check Verify the committed docs/ match the generated documentation, without modifying anything
+func (Doc) Check() {
+	if err := checkDocs(); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Info("doc: committed docs are up to date")
+}
+
+func checkDocs() error {
+	tmpDir, err := ioutil.TempDir("", "panther-docs")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Generate into the temp directory instead of out/docs
+	defer func(outDir string) { docsOutDir = outDir }(docsOutDir)
+	docsOutDir = tmpDir
+	if err := doc(); err != nil {
+		return err
+	}
+
+	stale, err := diffDocs(tmpDir, "docs")
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		return errors.New("docs are out of date, run 'mage doc:generate' and copy out/docs to docs:\n  " +
+			strings.Join(stale, "\n  "))
+	}
+	return nil
+}
+
+// Compare every generated file to its counterpart in the committed tree.
+//
+// Returns the committed paths which are missing or differ from the generated content.
+// Committed files which are not generated (e.g. hand-written pages) are ignored.
+func diffDocs(generatedDir, committedDir string) (stale []string, err error) {
+	err = filepath.Walk(generatedDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(generatedDir, path)
+		if err != nil {
+			return err
+		}
+		committedPath := filepath.Join(committedDir, rel)
+
+		committed, err := ioutil.ReadFile(committedPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				stale = append(stale, committedPath+" (missing)")
+				return nil
+			}
+			return fmt.Errorf("failed to read %s: %v", committedPath, err)
+		}
+
+		generated, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		if !bytes.Equal(normalizeDoc(generated), normalizeDoc(committed)) {
+			stale = append(stale, committedPath)
+		}
+		return nil
+	})
+	return stale, err
+}
+
+// Drop the "DO NOT EDIT" header lines, so that details like generation timestamps are not compared
+func normalizeDoc(body []byte) []byte {
+	lines := bytes.Split(body, []byte("\n"))
+	result := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		if bytes.Contains(line, []byte("DO NOT EDIT")) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return bytes.Join(result, []byte("\n"))
+}
@@ -0,0 +1,95 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+// Csv Export the supported log types as a spreadsheet in out/docs/supported-logs.csv (set STRICT=true to fail on warnings, DOCS_OUT to change the directory)
+func (Doc) Csv() {
+	path := filepath.Join(docsOutDir, "supported-logs.csv")
+	if err := writeLogCatalog(path, os.Getenv("STRICT") == "true"); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("doc: wrote log type catalog to %s", path)
+}
+
+var logCatalogHeader = []string{"Category", "Log Type", "Description", "Reference URL", "Columns", "Tags"}
+
+func writeLogCatalog(path string, strict bool) error {
+	logs, err := findSupportedLogs(strict)
+	if err != nil {
+		return err
+	}
+	body, err := formatLogCatalog(logs, func(logType string) (logtypes.Desc, int, error) {
+		columns, inferErr := registry.LogTypeColumns(logType)
+		return registry.Lookup(logType).Describe(), len(columns), inferErr
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(path, body)
+}
+
+// Format one CSV row for each log type, sorted by category and log type so the output is stable
+func formatLogCatalog(
+	logs *supportedLogs, describe func(logType string) (logtypes.Desc, int, error)) ([]byte, error) {
+
+	categories := make([]string, 0, len(logs.Categories))
+	for name := range logs.Categories {
+		categories = append(categories, name)
+	}
+	sort.Strings(categories)
+
+	var buffer bytes.Buffer
+	w := csv.NewWriter(&buffer) // quotes fields with commas, quotes and newlines
+	if err := w.Write(logCatalogHeader); err != nil {
+		return nil, err
+	}
+	for _, name := range categories {
+		logTypes := append([]string(nil), logs.Categories[name].LogTypes...)
+		sort.Strings(logTypes)
+		for _, logType := range logTypes {
+			desc, columns, err := describe(logType)
+			if err != nil {
+				return nil, err
+			}
+			referenceURL := desc.ReferenceURL
+			if referenceURL == "-" { // log types without a reference
+				referenceURL = ""
+			}
+			row := []string{name, logType, desc.Description, referenceURL, strconv.Itoa(columns), strings.Join(desc.Tags, ", ")}
+			if err := w.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buffer.Bytes(), w.Error()
+}
@@ -0,0 +1,105 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/tools/gluetype"
+)
+
+// Field List the log types with a column or nested field of the given name, e.g. "mage doc:field userIdentity"
+func (Doc) Field(name string) {
+	usages, err := findFieldUsages(registry.Default().Entries(), name)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if len(usages) == 0 {
+		logger.Infof("doc: no log type has a field named %s", name)
+		return
+	}
+	for _, usage := range usages {
+		fmt.Printf("%s: %s\n", usage.LogType, strings.Join(usage.Paths, ", "))
+	}
+}
+
+// fieldUsage lists where a field is used in the schema of a log type
+type fieldUsage struct {
+	LogType string
+	// Paths of the matching fields, e.g. "userIdentity" or "resources[].accountId".
+	// Array elements are marked with "[]" and map values with "{}".
+	Paths []string
+}
+
+// Find every log type with a top-level column or nested struct field of the given name, sorted by log type.
+//
+// Names are compared ignoring case, like Athena does.
+func findFieldUsages(entries []logtypes.Entry, name string) ([]fieldUsage, error) {
+	var usages []fieldUsage
+	for _, entry := range entries {
+		logType := entry.Describe().Name
+		columns, err := registry.InferColumns(logType, entry.GlueTableMeta().EventStruct())
+		if err != nil {
+			return nil, err
+		}
+
+		var paths []string
+		for _, column := range columns {
+			if strings.EqualFold(column.Name, name) {
+				paths = append(paths, column.Name)
+			}
+			parsed, err := gluetype.Parse(column.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v for %s", logType, err, column.Name)
+			}
+			paths = append(paths, findNestedFields(parsed, column.Name, name)...)
+		}
+		if len(paths) > 0 {
+			usages = append(usages, fieldUsage{LogType: logType, Paths: paths})
+		}
+	}
+
+	sort.Slice(usages, func(i, j int) bool { return usages[i].LogType < usages[j].LogType })
+	return usages, nil
+}
+
+// Returns the paths of the struct fields of the given name nested in a type
+func findNestedFields(t *gluetype.Type, path, name string) (paths []string) {
+	switch t.Kind {
+	case gluetype.Array:
+		return findNestedFields(t.Element, path+"[]", name)
+	case gluetype.Map:
+		return findNestedFields(t.Value, path+"{}", name)
+	case gluetype.Struct:
+		for _, field := range t.Fields {
+			fieldPath := path + "." + field.Name
+			if strings.EqualFold(field.Name, name) {
+				paths = append(paths, fieldPath)
+			}
+			paths = append(paths, findNestedFields(field.Type, fieldPath, name)...)
+		}
+		return paths
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// HTML elements which have no end tag
+var voidElements = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+	"wbr": true,
+}
+
+// Check that every tag of an HTML fragment is closed in the right order.
+//
+// The HTML parser recovers from any malformed input the way browsers do, so only the tokenizer is used.
+func validateHTML(fragment []byte) error {
+	tokenizer := html.NewTokenizer(bytes.NewReader(fragment))
+	var open []string
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return err
+			}
+			if len(open) > 0 {
+				return fmt.Errorf("<%s> is not closed", open[len(open)-1])
+			}
+			return nil
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			if !voidElements[string(name)] {
+				open = append(open, string(name))
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if len(open) == 0 {
+				return fmt.Errorf("unexpected </%s>", name)
+			}
+			if expected := open[len(open)-1]; expected != string(name) {
+				return fmt.Errorf("unexpected </%s>, expected </%s>", name, expected)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+const (
+	// The column types of each log type as of the last release
+	schemaSnapshotFile = "schemas.json"
+	// The human readable history of schema changes, newest release first
+	schemaChangelogFile   = "CHANGELOG.md"
+	schemaChangelogHeader = "# Log Schema Changelog\n"
+)
+
+// Release Record the log type schema changes since the last release in supported-logs/CHANGELOG.md (set VERSION, and DOCS_OUT=docs to update the docs in the repo)
+func (Doc) Release() {
+	version := os.Getenv("VERSION")
+	if version == "" {
+		logger.Fatal("VERSION is required, e.g. VERSION=v1.8.0 mage doc:release")
+	}
+	if err := releaseSchemaHistory(registry.Default().Entries(), supportedLogsDir(), version, time.Now().UTC()); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("doc: recorded schema changes for %s in %s", version, filepath.Join(supportedLogsDir(), schemaChangelogFile))
+}
+
+// Column name -> Glue type for each log type, e.g. {"AWS.CloudTrail": {"eventName": "string"}}
+type logTypeSchemas map[string]map[string]string
+
+// The schema changes of one log type between two releases
+type schemaChange struct {
+	LogType string
+	// The log type was added or removed, the column changes are not listed
+	AddedLogType   bool
+	RemovedLogType bool
+	Added          []string
+	Removed        []string
+	Retyped        []string
+}
+
+func collectSchemas(entries []logtypes.Entry) (logTypeSchemas, error) {
+	schemas := make(logTypeSchemas, len(entries))
+	for _, entry := range entries {
+		logType := entry.Describe().Name
+		columns, err := registry.InferColumns(logType, entry.GlueTableMeta().EventStruct())
+		if err != nil {
+			return nil, err
+		}
+		schemas[logType] = make(map[string]string, len(columns))
+		for _, column := range columns {
+			schemas[logType][column.Name] = column.Type
+		}
+	}
+	return schemas, nil
+}
+
+// Compare the schemas of two releases, returning the log types which changed sorted by name
+func diffSchemas(prior, current logTypeSchemas) []schemaChange {
+	var changes []schemaChange
+	for logType, columns := range current {
+		priorColumns, ok := prior[logType]
+		if !ok {
+			changes = append(changes, schemaChange{LogType: logType, AddedLogType: true})
+			continue
+		}
+
+		change := schemaChange{LogType: logType}
+		for name, glueType := range columns {
+			priorType, ok := priorColumns[name]
+			switch {
+			case !ok:
+				change.Added = append(change.Added, fmt.Sprintf("`%s` (`%s`)", name, glueType))
+			case priorType != glueType:
+				change.Retyped = append(change.Retyped, fmt.Sprintf("`%s` from `%s` to `%s`", name, priorType, glueType))
+			}
+		}
+		for name := range priorColumns {
+			if _, ok := columns[name]; !ok {
+				change.Removed = append(change.Removed, fmt.Sprintf("`%s`", name))
+			}
+		}
+		if len(change.Added)+len(change.Removed)+len(change.Retyped) > 0 {
+			sort.Strings(change.Added)
+			sort.Strings(change.Removed)
+			sort.Strings(change.Retyped)
+			changes = append(changes, change)
+		}
+	}
+	for logType := range prior {
+		if _, ok := current[logType]; !ok {
+			changes = append(changes, schemaChange{LogType: logType, RemovedLogType: true})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].LogType < changes[j].LogType })
+	return changes
+}
+
+// Format the changelog section of a single release
+func formatSchemaChanges(version string, date time.Time, changes []schemaChange) string {
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("## %s (%s)\n", version, date.Format("2006-01-02")))
+	if len(changes) == 0 {
+		section.WriteString("No schema changes.\n")
+	}
+	for _, change := range changes {
+		section.WriteString(fmt.Sprintf("### %s\n", change.LogType))
+		switch {
+		case change.AddedLogType:
+			section.WriteString("* New log type\n")
+		case change.RemovedLogType:
+			section.WriteString("* Removed log type\n")
+		}
+		for _, column := range change.Added {
+			section.WriteString("* Added " + column + "\n")
+		}
+		for _, column := range change.Removed {
+			section.WriteString("* Removed " + column + "\n")
+		}
+		for _, column := range change.Retyped {
+			section.WriteString("* Changed " + column + "\n")
+		}
+	}
+	return section.String() + "\n"
+}
+
+// Prepend the schema changes since the last release to the changelog in dir and update the schema snapshot.
+//
+// The first release has no snapshot to compare against, so all log types are listed as new.
+func releaseSchemaHistory(entries []logtypes.Entry, dir, version string, now time.Time) error {
+	current, err := collectSchemas(entries)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := filepath.Join(dir, schemaSnapshotFile)
+	prior := make(logTypeSchemas)
+	if body, err := ioutil.ReadFile(snapshotPath); err == nil {
+		if err := jsoniter.Unmarshal(body, &prior); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", snapshotPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", snapshotPath, err)
+	}
+
+	changelogPath := filepath.Join(dir, schemaChangelogFile)
+	history, err := ioutil.ReadFile(changelogPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", changelogPath, err)
+	}
+	// Older entries are kept as they are, the new one goes right below the header
+	changelog := schemaChangelogHeader + "\n" + formatSchemaChanges(version, now, diffSchemas(prior, current)) +
+		strings.TrimLeft(strings.TrimPrefix(string(history), schemaChangelogHeader), "\n")
+
+	snapshot, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(current, "", "  ") // sorted keys
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", snapshotPath, err)
+	}
+	if err := writeFile(changelogPath, []byte(changelog)); err != nil {
+		return err
+	}
+	return writeFile(snapshotPath, append(snapshot, '\n'))
+}
@@ -0,0 +1,124 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/jsonschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+// Jsonschema Generate a JSON Schema (draft-07) for each log type in out/schemas/json (set GZIP=true to also write .json.gz files)
+func (Doc) Jsonschema() {
+	if err := jsonSchemas(os.Getenv("GZIP") == "true"); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Info("doc: generated JSON schemas in out/schemas/json")
+}
+
+// Write one JSON Schema file for each log type, e.g. "AWS.CloudTrail.json", and a compressed copy if gzipped is set
+func jsonSchemas(gzipped bool) error {
+	logger.Debug("doc: generating JSON schemas for supported logs")
+	outDir := filepath.Join("out", "schemas", "json")
+
+	for _, entry := range registry.Default().Entries() {
+		desc := entry.Describe()
+		body, err := jsoniter.MarshalIndent(logTypeJSONSchema(entry), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON schema for %s: %v", desc.Name, err)
+		}
+
+		path := filepath.Join(outDir, desc.Name+".json")
+		logger.Debugf("writing JSON schema: %s", path)
+		if err := writeArtifact(path, append(body, '\n'), gzipped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The JSON Schema of a log type, with its description, stability and tags
+func logTypeJSONSchema(entry logtypes.Entry) *jsonschema.Schema {
+	desc := entry.Describe()
+	schema := jsonschema.Infer(entry.GlueTableMeta().EventStruct())
+	schema.Title = desc.Name
+	schema.Description = desc.Description
+	schema.Stability = desc.Stability
+	schema.Tags = desc.Tags
+	return schema
+}
+
+// Openapi Generate OpenAPI 3 component schemas for all log types in out/docs/openapi-logtypes.yml
+func (Doc) Openapi() {
+	if err := openAPISchemas(); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("doc: generated OpenAPI schemas in %s", filepath.Join(docsOutDir, "openapi-logtypes.yml"))
+}
+
+// The minimal OpenAPI 3 document which holds the log type schemas as reusable components
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Paths      map[string]interface{} `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*jsonschema.Schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// Build the OpenAPI document with one component schema for each log type, e.g. "AWS.CloudTrail"
+func openAPILogTypes(entries []logtypes.Entry) *openAPIDocument {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Paths:   make(map[string]interface{}),
+	}
+	doc.Info.Title = "Panther log types"
+	doc.Info.Version = "1.0.0"
+	doc.Components.Schemas = make(map[string]*jsonschema.Schema, len(entries))
+
+	for _, entry := range entries {
+		desc := entry.Describe()
+		schema := jsonschema.Reflect(entry.GlueTableMeta().EventStruct())
+		schema.Title = desc.Name
+		schema.Description = desc.Description
+		schema.Stability = desc.Stability
+		schema.Tags = desc.Tags
+		doc.Components.Schemas[desc.Name] = schema
+	}
+	return doc
+}
+
+func openAPISchemas() error {
+	logger.Debug("doc: generating OpenAPI schemas for supported logs")
+	body, err := yaml.Marshal(openAPILogTypes(registry.Default().Entries()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal OpenAPI schemas: %v", err)
+	}
+	return writeFile(filepath.Join(docsOutDir, "openapi-logtypes.yml"), body)
+}
@@ -0,0 +1,99 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+// Stats Summarize the supported log types in out/docs/stats.json for the release notes (set STRICT=true to fail on warnings, DOCS_OUT to change the directory, GZIP=true to also write stats.json.gz)
+func (Doc) Stats() {
+	path := filepath.Join(docsOutDir, "stats.json")
+	if err := writeDocStats(path, os.Getenv("STRICT") == "true", os.Getenv("GZIP") == "true"); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("doc: wrote log type statistics to %s", path)
+}
+
+// Aggregate counts of the supported log types, e.g. "Panther supports N log types across M categories"
+type docStats struct {
+	LogTypes           int            `json:"logTypes"`
+	Categories         int            `json:"categories"`
+	LogTypesByCategory map[string]int `json:"logTypesByCategory"`
+	// Columns with the same name in several log types are counted once
+	DistinctColumns int `json:"distinctColumns"`
+	// Rounded to two decimals
+	AverageColumns float64 `json:"averageColumnsPerLogType"`
+}
+
+func writeDocStats(path string, strict, gzipped bool) error {
+	logs, err := findSupportedLogs(strict)
+	if err != nil {
+		return err
+	}
+	stats, err := collectDocStats(logs, registry.LogTypeColumns)
+	if err != nil {
+		return err
+	}
+
+	body, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(stats, "", "  ") // sorted keys
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", path, err)
+	}
+	return writeArtifact(path, append(body, '\n'), gzipped)
+}
+
+// Aggregate the categories found by findSupportedLogs and the columns of each of their log types
+func collectDocStats(logs *supportedLogs, columnsOf func(logType string) ([]awsglue.Column, error)) (*docStats, error) {
+	stats := &docStats{
+		LogTypes:           logs.TotalTypes,
+		Categories:         len(logs.Categories),
+		LogTypesByCategory: make(map[string]int, len(logs.Categories)),
+	}
+
+	distinct := make(map[string]bool)
+	totalColumns := 0
+	for name, category := range logs.Categories {
+		stats.LogTypesByCategory[name] = len(category.LogTypes)
+		for _, logType := range category.LogTypes {
+			columns, err := columnsOf(logType)
+			if err != nil {
+				return nil, err
+			}
+			totalColumns += len(columns)
+			for _, column := range columns {
+				distinct[column.Name] = true
+			}
+		}
+	}
+
+	stats.DistinctColumns = len(distinct)
+	if stats.LogTypes > 0 {
+		stats.AverageColumns = math.Round(float64(totalColumns)/float64(stats.LogTypes)*100) / 100
+	}
+	return stats, nil
+}
@@ -19,9 +19,24 @@ package mage
  */
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
 )
 
 const logType, colName = "SomeParserType.SomeParser", "someColumn"
@@ -118,3 +133,702 @@ func TestLogDocPrettyPrintFail(t *testing.T) {
 	assert.PanicsWithValue(t, "could not parse struct field `foo` of `struct<foo,bar>` for someColumn in SomeParserType.SomeParser",
 		func() { prettyPrintType(logType, colName, colType, "") })
 }
+
+func TestLogDocInferColumns(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo" validate:"required" description:"foo field"`
+	}
+	columns, err := registry.InferColumns(logType, &event{})
+	require.NoError(t, err)
+	require.Len(t, columns, 1)
+	assert.Equal(t, "foo", columns[0].Name)
+	assert.True(t, columns[0].Required)
+}
+
+func TestLogDocInferColumnsFail(t *testing.T) {
+	// missing description panics in schema inference
+	type event struct {
+		Foo string `json:"foo"`
+	}
+	_, err := registry.InferColumns(logType, &event{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), logType)
+
+	// no columns
+	_, err = registry.InferColumns(logType, &struct{}{})
+	require.Error(t, err)
+}
+
+func TestLogDocWarning(t *testing.T) {
+	assert.NoError(t, docWarning(false, errors.New("lenient")))
+	assert.Error(t, docWarning(true, errors.New("strict")))
+}
+
+func TestLogDocCategories(t *testing.T) {
+	assert.Equal(t, "Google", docCategoryName("GCP"))
+	assert.Equal(t, "Google", docCategoryName("GSuite"))
+	assert.Equal(t, "Zeek", docCategoryName("Zeek"))
+
+	logs := &supportedLogs{Categories: map[string]*logCategory{
+		"Zeek":   {Name: "Zeek"},
+		"Google": {Name: "Google"},
+		"Apache": {Name: "Apache"},
+		"AWS":    {Name: "AWS"},
+	}}
+	var names []string
+	for _, category := range logs.orderedCategories() {
+		names = append(names, category.Name)
+	}
+	assert.Equal(t, []string{"AWS", "Google", "Apache", "Zeek"}, names)
+}
+
+func TestLogDocValidate(t *testing.T) {
+	type event struct {
+		Foo *string `json:"foo" validate:"required" description:"foo field"`
+	}
+	r := logtypes.Registry{}
+	_, err := r.RegisterJSON(logtypes.Desc{
+		Name:         "Foo.Bar",
+		Description:  "Foo.Bar logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+
+	// schema only
+	require.NoError(t, validateLogTypes(r.Entries(), ""))
+
+	examplesDir, err := ioutil.TempDir("", "doc-validate")
+	require.NoError(t, err)
+	defer os.RemoveAll(examplesDir)
+
+	// missing example file is not an error
+	require.NoError(t, validateLogTypes(r.Entries(), examplesDir))
+
+	examplePath := filepath.Join(examplesDir, "Foo.Bar.log")
+	require.NoError(t, ioutil.WriteFile(examplePath, []byte("{\"foo\":\"bar\"}\n\n"), 0644))
+	require.NoError(t, validateLogTypes(r.Entries(), examplesDir))
+
+	require.NoError(t, ioutil.WriteFile(examplePath, []byte("{\"foo\":\"bar\"}\n{\"baz\":1}\n"), 0644))
+	err = validateLogTypes(r.Entries(), examplesDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Foo.Bar: failed to parse example 2")
+}
+
+func TestLogDocOpenAPI(t *testing.T) {
+	type event struct {
+		Foo *string `json:"foo" validate:"required" sensitive:"true" description:"foo field"`
+		Bar *string `json:"bar" description:"bar field"`
+	}
+	r := logtypes.Registry{}
+	_, err := r.RegisterJSON(logtypes.Desc{
+		Name:         "Foo.Bar",
+		Description:  "Foo.Bar logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+
+	doc := openAPILogTypes(r.Entries())
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	require.Len(t, doc.Components.Schemas, 1)
+	schema := doc.Components.Schemas["Foo.Bar"]
+	require.NotNil(t, schema)
+	assert.Empty(t, schema.Schema)
+	assert.Equal(t, "Foo.Bar", schema.Title)
+	assert.Equal(t, "Foo.Bar logs", schema.Description)
+	assert.Contains(t, schema.Required, "foo")
+	require.Contains(t, schema.Properties, "foo")
+	assert.True(t, schema.Properties["foo"].Sensitive)
+	assert.False(t, schema.Properties["bar"].Sensitive)
+}
+
+func TestLogDocDiffDocs(t *testing.T) {
+	generatedDir, err := ioutil.TempDir("", "doc-generated")
+	require.NoError(t, err)
+	defer os.RemoveAll(generatedDir)
+	committedDir, err := ioutil.TempDir("", "doc-committed")
+	require.NoError(t, err)
+	defer os.RemoveAll(committedDir)
+
+	write := func(dir, name, body string) {
+		require.NoError(t, writeFile(filepath.Join(dir, name), []byte(body)))
+	}
+	write(generatedDir, "same.md", "<!-- generated at 1. DO NOT EDIT! -->\n# Same\n")
+	write(committedDir, "same.md", "<!-- generated at 2. DO NOT EDIT! -->\n# Same\n")
+	write(generatedDir, "sub/changed.md", "# New\n")
+	write(committedDir, "sub/changed.md", "# Old\n")
+	write(generatedDir, "missing.md", "# Missing\n")
+	write(committedDir, "handwritten.md", "# Not generated\n")
+
+	stale, err := diffDocs(generatedDir, committedDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(committedDir, "missing.md") + " (missing)",
+		filepath.Join(committedDir, "sub", "changed.md"),
+	}, stale)
+}
+
+func TestLogDocEventTimeMarker(t *testing.T) {
+	table := awsglue.NewGlueTableMetadata(models.LogData, "Foo.Bar", "Foo.Bar logs", awsglue.GlueTableDaily, nil)
+	assert.Equal(t, `<i title="partitioned by year, month, day">🕑 event time</i>`, formatEventTimeMarker(table))
+	assert.Equal(t, `<i title="partitioned by year, month, day, with partition projection">🕑 event time</i>`,
+		formatEventTimeMarker(table.WithPartitionProjection(2020)))
+}
+
+func TestLogDocSensitiveMarker(t *testing.T) {
+	type event struct {
+		Email string `json:"email" validate:"required" sensitive:"true" description:"email field"`
+		Name  string `json:"name" description:"name field"`
+	}
+	columns, err := registry.InferColumns(logType, &event{})
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.True(t, columns[0].Sensitive)
+	assert.False(t, columns[1].Sensitive)
+
+	table := awsglue.NewGlueTableMetadata(models.LogData, "Foo.Bar", "Foo.Bar logs", awsglue.GlueTableDaily, &event{})
+	assert.Equal(t, `<code><b>email</b></code><br><i title="may contain sensitive data">🔒 sensitive</i>`,
+		formatColumnCell(columns[0], table, awsglue.StabilityStable))
+	assert.Equal(t, `<code>name</code>`, formatColumnCell(columns[1], table, awsglue.StabilityStable))
+}
+
+func TestLogDocStability(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo" description:"foo field"`
+		Bar string `json:"bar" stability:"experimental" description:"bar field"`
+	}
+	r := logtypes.Registry{}
+	_, err := r.RegisterJSON(logtypes.Desc{
+		Name:         "Foo.Stable",
+		Description:  "Foo.Stable logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+	_, err = r.RegisterJSON(logtypes.Desc{
+		Name:         "Foo.Experimental",
+		Description:  "Foo.Experimental logs",
+		ReferenceURL: "-",
+		Stability:    awsglue.StabilityExperimental,
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+
+	// Markdown
+	columns, err := registry.InferColumns(logType, &event{})
+	require.NoError(t, err)
+	table := awsglue.NewGlueTableMetadata(models.LogData, "Foo.Stable", "Foo.Stable logs", awsglue.GlueTableDaily, &event{})
+	badge := `<i title="may change without notice">🧪 experimental</i>`
+	assert.Equal(t, badge, formatStabilityBadge(awsglue.StabilityExperimental))
+	assert.Equal(t, `<code>foo</code>`, formatColumnCell(columns[0], table, awsglue.StabilityStable))
+	assert.Equal(t, `<code>bar</code><br>`+badge, formatColumnCell(columns[1], table, awsglue.StabilityStable))
+	// the log type badge already covers the column
+	assert.Equal(t, `<code>bar</code>`, formatColumnCell(columns[1], table, awsglue.StabilityExperimental))
+
+	// JSON
+	doc := openAPILogTypes(r.Entries())
+	require.Len(t, doc.Components.Schemas, 2)
+	assert.Equal(t, awsglue.StabilityStable, doc.Components.Schemas["Foo.Stable"].Stability)
+	assert.Equal(t, awsglue.StabilityExperimental, doc.Components.Schemas["Foo.Experimental"].Stability)
+	assert.Empty(t, doc.Components.Schemas["Foo.Stable"].Properties["foo"].Stability)
+	assert.Equal(t, awsglue.StabilityExperimental, doc.Components.Schemas["Foo.Stable"].Properties["bar"].Stability)
+	body, err := jsoniter.Marshal(doc.Components.Schemas["Foo.Experimental"])
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"x-stability":"experimental"`)
+}
+
+func TestLogDocOutDir(t *testing.T) {
+	require.NoError(t, os.Unsetenv("DOCS_OUT"))
+	assert.Equal(t, filepath.Join("out", "docs"), getDocsOutDir())
+
+	require.NoError(t, os.Setenv("DOCS_OUT", "/tmp/site/docs"))
+	defer os.Unsetenv("DOCS_OUT")
+	assert.Equal(t, "/tmp/site/docs", getDocsOutDir())
+}
+
+func TestLogDocOrphanedFiles(t *testing.T) {
+	outDir, err := ioutil.TempDir("", "supported-logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(outDir)
+
+	for _, name := range []string{"README.md", "AWS.md", "Legacy.md", "notes.txt"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(outDir, name), []byte("# doc\n"), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(outDir, "images.md"), 0755))
+
+	logs := &supportedLogs{Categories: map[string]*logCategory{"AWS": {Name: "AWS"}}}
+	orphans, err := logs.findOrphanedDocFiles(outDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(outDir, "Legacy.md")}, orphans)
+}
+
+func TestLogDocFormatType(t *testing.T) {
+	formatted, err := formatType(logType, awsglue.Column{Name: colName, Type: "array<string>"})
+	require.NoError(t, err)
+	assert.Equal(t, "<code>[string]</code>", formatted)
+
+	_, err = formatType(logType, awsglue.Column{Name: colName, Type: "map<>"})
+	assert.EqualError(t, err, "failed to format column type: could not parse map type `map<>` for someColumn in SomeParserType.SomeParser")
+}
+
+func TestLogDocDiffSchemas(t *testing.T) {
+	prior := logTypeSchemas{
+		"Foo.Bar": {"a": "string", "b": "bigint", "c": "string"},
+		"Foo.Old": {"a": "string"},
+	}
+	current := logTypeSchemas{
+		"Foo.Bar": {"a": "string", "b": "string", "d": "array<string>"},
+		"Foo.New": {"a": "string"},
+	}
+	changes := diffSchemas(prior, current)
+	assert.Equal(t, []schemaChange{
+		{
+			LogType: "Foo.Bar",
+			Added:   []string{"`d` (`array<string>`)"},
+			Removed: []string{"`c`"},
+			Retyped: []string{"`b` from `bigint` to `string`"},
+		},
+		{LogType: "Foo.New", AddedLogType: true},
+		{LogType: "Foo.Old", RemovedLogType: true},
+	}, changes)
+
+	date := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "## v1.8.0 (2020-07-01)\n"+
+		"### Foo.Bar\n* Added `d` (`array<string>`)\n* Removed `c`\n* Changed `b` from `bigint` to `string`\n"+
+		"### Foo.New\n* New log type\n"+
+		"### Foo.Old\n* Removed log type\n\n",
+		formatSchemaChanges("v1.8.0", date, changes))
+	assert.Equal(t, "## v1.8.1 (2020-07-01)\nNo schema changes.\n\n", formatSchemaChanges("v1.8.1", date, nil))
+}
+
+func TestLogDocRelease(t *testing.T) {
+	type event struct {
+		Foo *string `json:"foo" validate:"required" sensitive:"true" description:"foo field"`
+		Bar *string `json:"bar" description:"bar field"`
+	}
+	r := logtypes.Registry{}
+	_, err := r.RegisterJSON(logtypes.Desc{
+		Name:         "Foo.Bar",
+		Description:  "Foo.Bar logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "doc-release")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The first release lists every log type as new
+	require.NoError(t, releaseSchemaHistory(r.Entries(), dir, "v1.0.0", time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)))
+
+	// Pretend the "foo" column didn't exist in the last release
+	var snapshot logTypeSchemas
+	body, err := ioutil.ReadFile(filepath.Join(dir, schemaSnapshotFile))
+	require.NoError(t, err)
+	require.NoError(t, jsoniter.Unmarshal(body, &snapshot))
+	require.Equal(t, "string", snapshot["Foo.Bar"]["foo"])
+	delete(snapshot["Foo.Bar"], "foo")
+	body, err = jsoniter.Marshal(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, schemaSnapshotFile), body, 0644))
+
+	require.NoError(t, releaseSchemaHistory(r.Entries(), dir, "v1.1.0", time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)))
+	changelog, err := ioutil.ReadFile(filepath.Join(dir, schemaChangelogFile))
+	require.NoError(t, err)
+	assert.Equal(t, schemaChangelogHeader+"\n"+
+		"## v1.1.0 (2020-07-01)\n### Foo.Bar\n* Added `foo` (`string`)\n\n"+
+		"## v1.0.0 (2020-06-01)\n### Foo.Bar\n* New log type\n\n",
+		string(changelog))
+}
+
+func TestLogDocCategoryFooter(t *testing.T) {
+	assert.Equal(t, "<!-- Generated summary, DO NOT EDIT! -->\n---\n_3 log types, 42 columns in total._\n",
+		formatCategoryFooter(3, 42))
+	assert.Equal(t, "<!-- Generated summary, DO NOT EDIT! -->\n---\n_1 log type, 1 column in total._\n",
+		formatCategoryFooter(1, 1))
+	assert.Contains(t, formatCategoryFooter(0, 0), "_0 log types, 0 columns in total._")
+}
+
+func TestLogDocRequiredOnly(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo" validate:"required" description:"foo field"`
+		Bar string `json:"bar" description:"bar field"`
+		Baz string `json:"baz" validate:"required" description:"baz field"`
+	}
+	columns, err := registry.InferColumns(logType, &event{})
+	require.NoError(t, err)
+
+	required := requiredColumns(columns)
+	require.Len(t, required, 2)
+	assert.Equal(t, "foo", required[0].Name)
+	assert.Equal(t, "baz", required[1].Name)
+	assert.Empty(t, requiredColumns(columns[1:2]))
+
+	assert.Equal(t, "\n_Required fields only: 2 of 3 columns._\n\n", formatSubsetLabel(len(required), len(columns)))
+	assert.Equal(t, "\n_Required fields only: 0 of 1 column._\n\n", formatSubsetLabel(0, 1))
+}
+
+func TestLogDocFindFieldUsages(t *testing.T) {
+	type identity struct {
+		UserName *string `json:"userName" description:"user name"`
+	}
+	type resource struct {
+		AccountID *string   `json:"accountId" description:"account id"`
+		Owner     *identity `json:"owner" description:"owner"`
+	}
+	type fooEvent struct {
+		UserName  *string             `json:"username" description:"top-level user name"`
+		Resources []resource          `json:"resources" description:"resources"`
+		Sessions  map[string]identity `json:"sessions" description:"sessions"`
+	}
+	type barEvent struct {
+		Identity *identity `json:"identity" description:"identity"`
+	}
+	type bazEvent struct {
+		Name *string `json:"name" description:"name"`
+	}
+
+	r := logtypes.Registry{}
+	for name, event := range map[string]interface{}{"Foo.Event": &fooEvent{}, "Bar.Event": &barEvent{}, "Baz.Event": &bazEvent{}} {
+		event := event
+		_, err := r.RegisterJSON(logtypes.Desc{
+			Name:         name,
+			Description:  name + " logs",
+			ReferenceURL: "-",
+		}, func() interface{} { return event })
+		require.NoError(t, err)
+	}
+
+	usages, err := findFieldUsages(r.Entries(), "userName")
+	require.NoError(t, err)
+	assert.Equal(t, []fieldUsage{
+		{LogType: "Bar.Event", Paths: []string{"identity.userName"}},
+		{LogType: "Foo.Event", Paths: []string{"username", "resources[].owner.userName", "sessions{}.userName"}},
+	}, usages)
+
+	usages, err = findFieldUsages(r.Entries(), "missing")
+	require.NoError(t, err)
+	assert.Empty(t, usages)
+}
+
+func TestLogDocStats(t *testing.T) {
+	logs := &supportedLogs{
+		Categories: map[string]*logCategory{
+			"AWS":  {Name: "AWS", LogTypes: []string{"AWS.A", "AWS.B"}},
+			"Okta": {Name: "Okta", LogTypes: []string{"Okta.SystemLog"}},
+		},
+		TotalTypes: 3,
+	}
+	columns := map[string][]awsglue.Column{
+		"AWS.A":          {{Name: "id"}, {Name: "eventName"}},
+		"AWS.B":          {{Name: "id"}, {Name: "region"}, {Name: "accountId"}},
+		"Okta.SystemLog": {{Name: "id"}, {Name: "actor"}},
+	}
+	columnsOf := func(logType string) ([]awsglue.Column, error) {
+		return columns[logType], nil
+	}
+
+	stats, err := collectDocStats(logs, columnsOf)
+	require.NoError(t, err)
+	assert.Equal(t, &docStats{
+		LogTypes:           3,
+		Categories:         2,
+		LogTypesByCategory: map[string]int{"AWS": 2, "Okta": 1},
+		DistinctColumns:    5,
+		AverageColumns:     2.33,
+	}, stats)
+
+	body, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(stats)
+	require.NoError(t, err)
+	assert.Equal(t, `{"logTypes":3,"categories":2,"logTypesByCategory":{"AWS":2,"Okta":1},`+
+		`"distinctColumns":5,"averageColumnsPerLogType":2.33}`, string(body))
+
+	_, err = collectDocStats(logs, func(string) ([]awsglue.Column, error) {
+		return nil, errors.New("no columns")
+	})
+	assert.Error(t, err)
+
+	empty, err := collectDocStats(&supportedLogs{Categories: map[string]*logCategory{}}, columnsOf)
+	require.NoError(t, err)
+	assert.Zero(t, empty.AverageColumns)
+}
+
+func TestLogDocValidateHTML(t *testing.T) {
+	assert.NoError(t, validateHTML([]byte("<table>\n<tr><td><code><b>foo</b></code><br><i>sensitive</i></td></tr>\n</table>\n")))
+	assert.NoError(t, validateHTML(nil))
+
+	assert.EqualError(t, validateHTML([]byte("<table><tr><td>foo</td></tr>")), "<table> is not closed")
+	assert.EqualError(t, validateHTML([]byte("<code><b>foo</code></b>")), "unexpected </code>, expected </b>")
+	assert.EqualError(t, validateHTML([]byte("foo</td>")), "unexpected </td>")
+
+	// The pretty printer output of nested types is well formed
+	colType := `struct<a:array<struct<b:map<string,string>,c:bigint>>>`
+	cell := "<table><tr><td><code>" + prettyPrintType(logType, colName, colType, "") + "</code></td></tr></table>"
+	assert.NoError(t, validateHTML([]byte(cell)))
+}
+
+func TestLogDocFlattenColumns(t *testing.T) {
+	columns := []awsglue.Column{
+		{Name: "name", Type: "string", Required: true},
+		{Name: "user", Type: "struct<id:bigint,address:struct<city:string,zip:string>>", Comment: "the user", Required: true},
+		{Name: "tags", Type: "array<struct<key:string,value:string>>"},
+		{Name: "labels", Type: "map<string,struct<value:string>>", Sensitive: true},
+	}
+	flattened, err := flattenColumns(logType, columns)
+	require.NoError(t, err)
+	assert.Equal(t, []awsglue.Column{
+		{Name: "name", Type: "string", Required: true},
+		{Name: "user.id", Type: "bigint", Comment: "the user"},
+		{Name: "user.address.city", Type: "string", Comment: "the user"},
+		{Name: "user.address.zip", Type: "string", Comment: "the user"},
+		{Name: "tags", Type: "array<struct<key:string,value:string>>"},
+		{Name: "labels", Type: "map<string,struct<value:string>>", Sensitive: true},
+	}, flattened)
+
+	_, err = flattenColumns(logType, []awsglue.Column{{Name: colName, Type: "struct<>"}})
+	assert.EqualError(t, err, "could not parse struct type `struct<>` for someColumn in SomeParserType.SomeParser")
+}
+
+func TestLogDocTypeFiles(t *testing.T) {
+	category := &logCategory{Name: "Google", LogTypes: []string{"GSuite.Reports", "GCP.AuditLog"}}
+	assert.Equal(t, "GCP.AuditLog", category.typeDocName("GCP.AuditLog"))
+	awsCategory := &logCategory{Name: "AWS", LogTypes: []string{"AWS.CloudTrail"}}
+	assert.Equal(t, "CloudTrail", awsCategory.typeDocName("AWS.CloudTrail"))
+
+	sort.Strings(category.LogTypes)
+	index := string(category.formatTypeIndex())
+	assert.Contains(t, index, "# Google\n2 log types.\n\n"+
+		"* [GCP.AuditLog](Google/GCP.AuditLog.md)\n* [GSuite.Reports](Google/GSuite.Reports.md)\n")
+
+	outDir, err := ioutil.TempDir("", "supported-logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(outDir)
+
+	require.NoError(t, os.Mkdir(filepath.Join(outDir, "AWS"), 0755))
+	for _, name := range []string{"CloudTrail.md", "Legacy.md", "notes.txt"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(outDir, "AWS", name), []byte("# doc\n"), 0644))
+	}
+	logs := &supportedLogs{Categories: map[string]*logCategory{"AWS": awsCategory, "Google": category}}
+	orphans, err := logs.findOrphanedTypeDocFiles(outDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(outDir, "AWS", "Legacy.md")}, orphans)
+}
+
+func TestLogDocCatalog(t *testing.T) {
+	logs := &supportedLogs{
+		Categories: map[string]*logCategory{
+			"Okta": {Name: "Okta", LogTypes: []string{"Okta.SystemLog"}},
+			"AWS":  {Name: "AWS", LogTypes: []string{"AWS.VPCFlow", "AWS.CloudTrail"}},
+		},
+		TotalTypes: 3,
+	}
+	descriptions := map[string]logtypes.Desc{
+		"AWS.CloudTrail": {
+			Description:  `API calls, e.g. "ListBuckets"`,
+			ReferenceURL: "https://aws.amazon.com/cloudtrail",
+			Tags:         []string{"audit", "auth"},
+		},
+		"AWS.VPCFlow":    {Description: "Network flows, per interface", ReferenceURL: "-"},
+		"Okta.SystemLog": {Description: "Okta events", ReferenceURL: "https://okta.com"},
+	}
+	describe := func(logType string) (logtypes.Desc, int, error) {
+		return descriptions[logType], len(logType), nil
+	}
+
+	body, err := formatLogCatalog(logs, describe)
+	require.NoError(t, err)
+	assert.Equal(t, "Category,Log Type,Description,Reference URL,Columns,Tags\n"+
+		`AWS,AWS.CloudTrail,"API calls, e.g. ""ListBuckets""",https://aws.amazon.com/cloudtrail,14,"audit, auth"`+"\n"+
+		`AWS,AWS.VPCFlow,"Network flows, per interface",,11,`+"\n"+
+		"Okta,Okta.SystemLog,Okta events,https://okta.com,14,\n", string(body))
+
+	_, err = formatLogCatalog(logs, func(string) (logtypes.Desc, int, error) {
+		return logtypes.Desc{}, 0, errors.New("no columns")
+	})
+	assert.Error(t, err)
+}
+
+func TestLogDocTraceSummary(t *testing.T) {
+	trace := &docTrace{}
+	endLogDocs := trace.stage("logDocs")
+	trace.stage("findSupportedLogs")()
+	endLogDocs()
+	trace.stage("opDocs")()
+	require.Len(t, trace.stages, 3)
+	trace.stages[0].duration = 1500 * time.Millisecond
+	trace.stages[1].duration = 20 * time.Millisecond
+	trace.stages[2].duration = 300 * time.Millisecond
+	trace.inferred("AWS.S3ServerAccess", 20, 5*time.Millisecond)
+	trace.inferred("AWS.CloudTrail", 300, 120*time.Millisecond)
+	trace.inferred("Zeek.DNS", 1, 10*time.Millisecond)
+
+	expected := `doc: timing summary
+  logDocs              1.5s
+    findSupportedLogs  20ms
+  opDocs               300ms
+  3 log types (321 columns), slowest inference:
+    AWS.CloudTrail      120ms  300 columns
+    Zeek.DNS            10ms   1 column
+    AWS.S3ServerAccess  5ms    20 columns`
+	assert.Equal(t, expected, trace.summary())
+	assert.Equal(t, 0, trace.depth)
+}
+
+func TestLogDocDuplicateLogTypes(t *testing.T) {
+	type event struct {
+		Foo *string `json:"foo" description:"foo field"`
+	}
+	register := func(r *logtypes.Registry, name string) {
+		_, err := r.RegisterJSON(logtypes.Desc{
+			Name:         name,
+			Description:  name + " logs",
+			ReferenceURL: "-",
+		}, func() interface{} { return &event{} })
+		require.NoError(t, err)
+	}
+	// a registry rejects duplicate names, the same entry is registered by copy-paste in another one
+	r := &logtypes.Registry{}
+	register(r, "Foo.Bar")
+	register(r, "Foo.Baz")
+	register(r, "Qux.Quux")
+	copied := &logtypes.Registry{}
+	register(copied, "Foo.Bar")
+	register(copied, "Foo.baz")
+
+	var logTypes []string
+	for _, entry := range append(r.Entries(), copied.Entries()...) {
+		logTypes = append(logTypes, entry.Describe().Name)
+	}
+	_, err := groupLogTypes(logTypes, false)
+	require.Error(t, err)
+	assert.Equal(t, "duplicate log types:\nFoo.Bar is registered 2 times\nFoo.Baz, Foo.baz differ only by case", err.Error())
+
+	logs, err := groupLogTypes(r.LogTypes(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, logs.TotalTypes)
+	assert.ElementsMatch(t, []string{"Foo.Bar", "Foo.Baz"}, logs.Categories["Foo"].LogTypes)
+}
+
+func TestLogDocRawJSONColumns(t *testing.T) {
+	type event struct {
+		Name   string               `json:"name" validate:"required" description:"name field"`
+		Detail *jsoniter.RawMessage `json:"detail" description:"detail field"`
+	}
+	_, err := logtypes.DefaultRegistry().RegisterJSON(logtypes.Desc{
+		Name:         "Foo.RawDetail",
+		Description:  "Foo.RawDetail logs",
+		ReferenceURL: "-",
+	}, func() interface{} { return &event{} })
+	require.NoError(t, err)
+	defer logtypes.DefaultRegistry().Del("Foo.RawDetail")
+
+	columns, err := registry.InferColumns("Foo.RawDetail", &event{})
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.False(t, columns[0].RawJSON)
+	assert.True(t, columns[1].RawJSON)
+	table := awsglue.NewGlueTableMetadata(models.LogData, "Foo.RawDetail", "Foo.RawDetail logs", awsglue.GlueTableDaily, &event{})
+	assert.Equal(t, `<code>detail</code><br><i title="unparsed JSON stored as a string">{} raw JSON</i>`,
+		formatColumnCell(columns[1], table, awsglue.StabilityStable))
+
+	var docs bytes.Buffer
+	category := &logCategory{Name: "Foo", LogTypes: []string{"Foo.RawDetail"}}
+	require.NoError(t, category.writeDoc(&docs, true, false, false))
+	assert.Contains(t, docs.String(), "</table>\n\n"+rawJSONNote)
+
+	// the note is only added when a raw JSON column is listed
+	docs.Reset()
+	require.NoError(t, category.writeDoc(&docs, true, true, false))
+	assert.NotContains(t, docs.String(), rawJSONNote)
+}
+
+// unparseableType is mapped to a Glue type the docs can't parse
+type unparseableType string
+
+func TestLogDocFlattenFailureDropsLogType(t *testing.T) {
+	previous := awsglue.GlueMappings
+	awsglue.GlueMappings = append(awsglue.GlueMappings[:len(awsglue.GlueMappings):len(awsglue.GlueMappings)],
+		awsglue.CustomMapping{From: reflect.TypeOf(unparseableType("")), To: "struct<"})
+	defer func() { awsglue.GlueMappings = previous }()
+
+	type badEvent struct {
+		Bad unparseableType `json:"bad" description:"bad field"`
+	}
+	type goodEvent struct {
+		Name string `json:"name" validate:"required" description:"name field"`
+	}
+	for name, event := range map[string]interface{}{"Foo.Bad": &badEvent{}, "Foo.Good": &goodEvent{}} {
+		event := event
+		_, err := logtypes.DefaultRegistry().RegisterJSON(logtypes.Desc{
+			Name:         name,
+			Description:  name + " logs",
+			ReferenceURL: "-",
+		}, func() interface{} { return event })
+		require.NoError(t, err)
+		defer logtypes.DefaultRegistry().Del(name)
+	}
+
+	var docs bytes.Buffer
+	category := &logCategory{Name: "Foo", LogTypes: []string{"Foo.Bad", "Foo.Good"}}
+	require.Error(t, category.writeDoc(&docs, false, false, true))
+	assert.NotContains(t, docs.String(), "## Foo.Bad")
+	assert.Contains(t, docs.String(), "# Foo\n")
+	assert.Contains(t, docs.String(), "## Foo.Good\nFoo.Good logs\n<table>")
+}
+
+func TestLogDocTags(t *testing.T) {
+	type event struct {
+		Name string `json:"name" validate:"required" description:"name field"`
+	}
+	for _, desc := range []logtypes.Desc{
+		{Name: "Foo.Tagged", Description: "Foo.Tagged logs", ReferenceURL: "-", Tags: []string{"audit", "network"}},
+		{Name: "Foo.Untagged", Description: "Foo.Untagged logs", ReferenceURL: "-"},
+	} {
+		_, err := logtypes.DefaultRegistry().RegisterJSON(desc, func() interface{} { return &event{} })
+		require.NoError(t, err)
+		defer logtypes.DefaultRegistry().Del(desc.Name)
+	}
+
+	var docs bytes.Buffer
+	tagged := &logCategory{Name: "Foo", LogTypes: []string{"Foo.Tagged"}}
+	require.NoError(t, tagged.writeDoc(&docs, true, false, false))
+	assert.Contains(t, docs.String(), "## Foo.Tagged\nFoo.Tagged logs\n"+
+		`<i title="tag">🏷️ audit</i> <i title="tag">🏷️ network</i>`+"\n\n<table>")
+
+	docs.Reset()
+	untagged := &logCategory{Name: "Foo", LogTypes: []string{"Foo.Untagged"}}
+	require.NoError(t, untagged.writeDoc(&docs, true, false, false))
+	assert.Contains(t, docs.String(), "## Foo.Untagged\nFoo.Untagged logs\n<table>")
+	assert.NotContains(t, docs.String(), `title="tag"`)
+
+	schema, err := jsoniter.Marshal(logTypeJSONSchema(registry.Lookup("Foo.Tagged")))
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), `"x-tags":["audit","network"]`)
+	schema, err = jsoniter.Marshal(logTypeJSONSchema(registry.Lookup("Foo.Untagged")))
+	require.NoError(t, err)
+	assert.NotContains(t, string(schema), "x-tags")
+}
+
+func TestLogDocAudit(t *testing.T) {
+	descs := map[string]logtypes.Desc{
+		"Zeta.Events":   {Name: "Zeta.Events", Description: "TODO", ReferenceURL: "-"},
+		"Alpha.Flow":    {Name: "Alpha.Flow", Description: "Alpha flow logs", ReferenceURL: "-"},
+		"Alpha.Audit":   {Name: "Alpha.Audit", Description: " alpha.audit ", ReferenceURL: "https://example.com/audit"},
+		"Alpha.Access":  {Name: "Alpha.Access", Description: "Alpha access logs", ReferenceURL: "https://example.com/access"},
+		"Beta.Requests": {Name: "Beta.Requests", Description: "Beta request logs", ReferenceURL: "https://example.com"},
+	}
+	logTypes := make([]string, 0, len(descs))
+	for logType := range descs {
+		logTypes = append(logTypes, logType)
+	}
+	logs, err := groupLogTypes(logTypes, true)
+	require.NoError(t, err)
+
+	gaps := auditLogTypes(logs, func(logType string) logtypes.Desc { return descs[logType] })
+	assert.Equal(t, []string{
+		"Alpha.Audit: no description",
+		"Alpha.Flow: no reference URL",
+		"Zeta.Events: no description, no reference URL",
+	}, gaps)
+
+	logs, err = groupLogTypes([]string{"Alpha.Access", "Beta.Requests"}, true)
+	require.NoError(t, err)
+	assert.Empty(t, auditLogTypes(logs, func(logType string) logtypes.Desc { return descs[logType] }))
+}
@@ -0,0 +1,106 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// The number of log types listed in the trace summary, slowest first
+const traceSlowestTypes = 10
+
+// Timings of "mage doc", summarized at the end of the run in verbose mode (mage -v).
+//
+// Stages can be nested: the stages started while another one is running are indented below it.
+type docTrace struct {
+	stages []*docTraceStage
+	types  []docTraceType
+	depth  int
+}
+
+type docTraceStage struct {
+	name     string
+	depth    int
+	duration time.Duration
+}
+
+// The schema inference of a single log type
+type docTraceType struct {
+	logType  string
+	columns  int
+	duration time.Duration
+}
+
+var docTracer = &docTrace{}
+
+// Start timing a stage, the returned function stops it: defer docTracer.stage("opDocs")()
+func (trace *docTrace) stage(name string) func() {
+	stage := &docTraceStage{name: name, depth: trace.depth}
+	trace.stages = append(trace.stages, stage)
+	trace.depth++
+	start := time.Now()
+	return func() {
+		stage.duration = time.Since(start)
+		trace.depth--
+	}
+}
+
+// Record the number of columns inferred for a log type and how long the inference took
+func (trace *docTrace) inferred(logType string, columns int, duration time.Duration) {
+	trace.types = append(trace.types, docTraceType{logType: logType, columns: columns, duration: duration})
+}
+
+// A table of the stage durations, in the order they started, followed by the slowest log types
+func (trace *docTrace) summary() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "doc: timing summary")
+	for _, stage := range trace.stages {
+		fmt.Fprintf(w, "%s%s\t%s\n", strings.Repeat("  ", stage.depth+1), stage.name, formatTraceDuration(stage.duration))
+	}
+
+	if len(trace.types) > 0 {
+		types := make([]docTraceType, len(trace.types))
+		copy(types, trace.types)
+		sort.SliceStable(types, func(i, j int) bool { return types[i].duration > types[j].duration })
+		totalColumns := 0
+		for _, t := range types {
+			totalColumns += t.columns
+		}
+		if len(types) > traceSlowestTypes {
+			types = types[:traceSlowestTypes]
+		}
+		fmt.Fprintf(w, "  %s (%s), slowest inference:\n",
+			pluralize(len(trace.types), "log type"), pluralize(totalColumns, "column"))
+		for _, t := range types {
+			fmt.Fprintf(w, "    %s\t%s\t%s\n", t.logType, formatTraceDuration(t.duration), pluralize(t.columns, "column"))
+		}
+	}
+	_ = w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func formatTraceDuration(duration time.Duration) string {
+	return duration.Round(time.Millisecond).String()
+}
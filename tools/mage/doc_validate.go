@@ -0,0 +1,111 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+)
+
+// Validate Verify every log type infers a Glue schema and parses its examples (set EXAMPLES=dir with <LogType>.log files)
+func (Doc) Validate() {
+	if err := validateLogTypes(registry.Default().Entries(), os.Getenv("EXAMPLES")); err != nil {
+		logger.Fatal(err)
+	}
+	logger.Info("doc: all log types are valid")
+}
+
+// Check each log type entry, collecting all failures into a single error.
+//
+// If examplesDir is not empty, example events are read from "<examplesDir>/<LogType>.log" (one event per line)
+// and parsed through the registered parser. Log types without an example file are only checked for their schema.
+func validateLogTypes(entries []logtypes.Entry, examplesDir string) error {
+	var errs []string
+	for _, entry := range entries {
+		if err := validateLogType(entry, examplesDir); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", entry.Describe().Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func validateLogType(entry logtypes.Entry, examplesDir string) error {
+	logType := entry.Describe().Name
+	if _, err := registry.InferColumns(logType, entry.GlueTableMeta().EventStruct()); err != nil {
+		return err
+	}
+
+	if examplesDir == "" {
+		return nil
+	}
+	examples, err := readLogExamples(filepath.Join(examplesDir, logType+".log"))
+	if err != nil {
+		return err
+	}
+	if len(examples) == 0 {
+		return nil
+	}
+
+	parser, err := entry.NewParser(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create parser: %v", err)
+	}
+	for i, example := range examples {
+		if _, err := parser.ParseLog(example); err != nil {
+			return fmt.Errorf("failed to parse example %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Read the non-empty lines of an example file. A missing file returns no examples.
+func readLogExamples(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var examples []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			examples = append(examples, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return examples, nil
+}
@@ -64,7 +64,7 @@ func (Test) CI() {
 			return goLintErr
 		}},
 
-		// mage doc
+		// mage doc:generate
 		{"doc", doc}, // verify the command works, even if docs aren't committed in this repo
 	}
 
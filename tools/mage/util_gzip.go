@@ -0,0 +1,59 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// Write a generated artifact and, if gzipped is set, a compressed copy of it next to it, e.g. "stats.json.gz".
+//
+// Both files have the same content, the compressed copy is meant to be served as is by a CDN.
+func writeArtifact(path string, data []byte, gzipped bool) error {
+	if err := writeFile(path, data); err != nil {
+		return err
+	}
+	if !gzipped {
+		return nil
+	}
+	compressed, err := gzipBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to compress %s: %v", path, err)
+	}
+	return writeFile(path+".gz", compressed)
+}
+
+// Compress data reproducibly: the compression level is fixed and the gzip header has no name or timestamp,
+// so the same data always gives the same bytes.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package mage
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArtifact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifact")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data := []byte(`{"logTypes": 42}` + "\n")
+	plain := filepath.Join(dir, "plain.json")
+	require.NoError(t, writeArtifact(plain, data, false))
+	_, err = os.Stat(plain + ".gz")
+	assert.True(t, os.IsNotExist(err))
+
+	path := filepath.Join(dir, "stats.json")
+	require.NoError(t, writeArtifact(path, data, true))
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, written)
+
+	compressed, err := ioutil.ReadFile(path + ".gz")
+	require.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	assert.True(t, r.ModTime.IsZero())
+	assert.Empty(t, r.Name)
+	decompressed, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	// the same content always compresses to the same bytes
+	require.NoError(t, writeArtifact(path, data, true))
+	again, err := ioutil.ReadFile(path + ".gz")
+	require.NoError(t, err)
+	assert.Equal(t, compressed, again)
+}
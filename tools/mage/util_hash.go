@@ -19,8 +19,11 @@ package mage
  */
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/md5" // nolint:gosec
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -107,3 +110,58 @@ func fileDiffs(before, after map[string][16]byte) []string {
 
 	return diffs
 }
+
+// Stream generated content to a file, without holding all of it in memory.
+//
+// The content is written to a temporary file next to the path and hashed as it is written.
+// If the existing file has the same hash, it is left untouched (keeping its modification time).
+// If write returns an error, the existing file is not modified either.
+func streamFile(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", dir, err)
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file in %s: %v", dir, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	defer tmp.Close()
+
+	hash := md5.New() // nolint:gosec
+	w := bufio.NewWriter(io.MultiWriter(tmp, hash))
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+
+	if existing, err := streamMD5(path); err == nil && bytes.Equal(existing, hash.Sum(nil)) {
+		logger.Debugf("%s is unchanged", path)
+		return nil
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write file %s: %v", path, err)
+	}
+	return nil
+}
+
+// Compute the MD5 checksum of a file without reading all of it in memory.
+func streamMD5(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash := md5.New() // nolint:gosec
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, fmt.Errorf("read %s: %v", path, err)
+	}
+	return hash.Sum(nil), nil
+}
@@ -20,10 +20,17 @@ package mage
 
 import (
 	"crypto/md5" // nolint: gosec
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFileDiffs(t *testing.T) {
@@ -62,3 +69,46 @@ func TestFileDiffs(t *testing.T) {
 	sort.Strings(result)
 	assert.Equal(t, []string{"+ E", "- B", "~ A", "~ D"}, result)
 }
+
+func TestStreamFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stream-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "doc.md")
+
+	write := func(body string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, body)
+			return err
+		}
+	}
+
+	// new file, including the parent directory
+	require.NoError(t, streamFile(path, write("# Doc\n")))
+	assert.Equal(t, "# Doc\n", string(readFile(path)))
+
+	// unchanged file is not rewritten
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	require.NoError(t, streamFile(path, write("# Doc\n")))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, modTime, info.ModTime())
+
+	// changed file
+	require.NoError(t, streamFile(path, write("# New doc\n")))
+	assert.Equal(t, "# New doc\n", string(readFile(path)))
+
+	// failed generation leaves the file as it was
+	assert.EqualError(t, streamFile(path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "# Partial")
+		return errors.New("generation failed")
+	}), "generation failed")
+	assert.Equal(t, "# New doc\n", string(readFile(path)))
+
+	// no temp files left behind
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "doc.md", files[0].Name())
+}
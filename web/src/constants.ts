@@ -70,6 +70,7 @@ export const RESOURCE_TYPES = [
   'AWS.CloudTrail',
   'AWS.CloudTrail.Meta',
   'AWS.CloudWatch.LogGroup',
+  'AWS.CloudWatch.LogsDestination',
   'AWS.Config.Recorder',
   'AWS.Config.Recorder.Meta',
   'AWS.DynamoDB.Table',